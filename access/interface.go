@@ -0,0 +1,20 @@
+package access
+
+import (
+	"github.com/mysterium/node/identity"
+)
+
+// Policy decides which consumers are allowed to use provider's services
+type Policy interface {
+	// Allows tells if consumer may use services, rejected consumers are logged and counted
+	Allows(peerID identity.Identity) bool
+	// Rejections returns how many times consumers were rejected
+	Rejections() uint64
+}
+
+// ReloadingPolicy is policy which follows changes of its source in background, once started
+type ReloadingPolicy interface {
+	Policy
+	Start() error
+	Stop()
+}
@@ -0,0 +1,29 @@
+package access
+
+import (
+	"github.com/mysterium/node/identity"
+)
+
+// NewPolicyFake returns policy which rejects only given consumers
+func NewPolicyFake(denied ...identity.Identity) *policyFake {
+	return &policyFake{denied: denied}
+}
+
+type policyFake struct {
+	denied     []identity.Identity
+	rejections uint64
+}
+
+func (policy *policyFake) Allows(peerID identity.Identity) bool {
+	for _, denied := range policy.denied {
+		if denied == peerID {
+			policy.rejections++
+			return false
+		}
+	}
+	return true
+}
+
+func (policy *policyFake) Rejections() uint64 {
+	return policy.rejections
+}
@@ -0,0 +1,153 @@
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/identity"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const policyLogPrefix = "[access-policy] "
+
+// lists is the content of policy file. When allow list is not empty, only listed consumers are allowed.
+// Consumers in deny list are rejected in any case.
+type lists struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// NewPolicyFile returns policy which reads allow and deny lists from given JSON file.
+// Missing file allows everybody. File is reloaded when it changes, once policy is started.
+func NewPolicyFile(path string, reloadInterval time.Duration) *policyFile {
+	return &policyFile{
+		path:           path,
+		reloadInterval: reloadInterval,
+	}
+}
+
+type policyFile struct {
+	path           string
+	reloadInterval time.Duration
+
+	mutex    sync.RWMutex
+	loaded   bool
+	modified time.Time
+	allow    map[identity.Identity]bool
+	deny     map[identity.Identity]bool
+
+	rejections uint64
+	reloadStop chan struct{}
+}
+
+// Allows rejects consumers which are denied or are not in non empty allow list
+func (policy *policyFile) Allows(peerID identity.Identity) bool {
+	policy.mutex.RLock()
+	allowed := !policy.deny[peerID] && (len(policy.allow) == 0 || policy.allow[peerID])
+	policy.mutex.RUnlock()
+
+	if !allowed {
+		rejections := atomic.AddUint64(&policy.rejections, 1)
+		log.Warn(policyLogPrefix, "Consumer rejected: ", peerID.Address, ", total rejections: ", rejections)
+	}
+	return allowed
+}
+
+func (policy *policyFile) Rejections() uint64 {
+	return atomic.LoadUint64(&policy.rejections)
+}
+
+// Start loads the file and watches it for changes in background
+func (policy *policyFile) Start() error {
+	if err := policy.reload(); err != nil {
+		return err
+	}
+
+	policy.reloadStop = make(chan struct{})
+	go policy.reloadPeriodically(policy.reloadStop)
+	return nil
+}
+
+// Stop finishes watching the file
+func (policy *policyFile) Stop() {
+	if policy.reloadStop != nil {
+		close(policy.reloadStop)
+		policy.reloadStop = nil
+	}
+}
+
+func (policy *policyFile) reloadPeriodically(stop chan struct{}) {
+	ticker := time.NewTicker(policy.reloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := policy.reload(); err != nil {
+				log.Error(policyLogPrefix, "Failed to reload, keeping previous lists: ", err)
+			}
+		}
+	}
+}
+
+// reload reads the file again, if it was changed, created or removed since last load
+func (policy *policyFile) reload() error {
+	var modified time.Time
+	info, err := os.Stat(policy.path)
+	if err == nil {
+		modified = info.ModTime()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	policy.mutex.RLock()
+	unchanged := policy.loaded && modified.Equal(policy.modified)
+	policy.mutex.RUnlock()
+	if unchanged {
+		return nil
+	}
+
+	var content lists
+	if !modified.IsZero() {
+		if content, err = readLists(policy.path); err != nil {
+			return err
+		}
+	}
+
+	policy.mutex.Lock()
+	defer policy.mutex.Unlock()
+	policy.loaded = true
+	policy.modified = modified
+	policy.allow = toSet(content.Allow)
+	policy.deny = toSet(content.Deny)
+
+	log.Info(policyLogPrefix, fmt.Sprintf("Loaded %d allowed and %d denied consumers", len(policy.allow), len(policy.deny)))
+	return nil
+}
+
+func readLists(path string) (content lists, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &content)
+	if err != nil {
+		err = fmt.Errorf("malformed access policy file %s: %s", path, err)
+	}
+	return
+}
+
+func toSet(addresses []string) map[identity.Identity]bool {
+	set := make(map[identity.Identity]bool, len(addresses))
+	for _, address := range addresses {
+		set[identity.FromAddress(address)] = true
+	}
+	return set
+}
@@ -0,0 +1,107 @@
+package access
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	consumer1 = identity.FromAddress("0x1")
+	consumer2 = identity.FromAddress("0x2")
+)
+
+func newPolicyInTempDir(t *testing.T) (*policyFile, string, func()) {
+	dir, err := ioutil.TempDir("", "access-policy")
+	assert.NoError(t, err)
+
+	path := filepath.Join(dir, "access-policy.json")
+	return NewPolicyFile(path, time.Millisecond), path, func() { os.RemoveAll(dir) }
+}
+
+func writePolicy(t *testing.T, path, content string, modified time.Time) {
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	assert.NoError(t, os.Chtimes(path, modified, modified))
+}
+
+func TestPolicyFileAllowsEverybodyWithoutFile(t *testing.T) {
+	policy, _, cleanup := newPolicyInTempDir(t)
+	defer cleanup()
+
+	assert.NoError(t, policy.Start())
+	defer policy.Stop()
+
+	assert.True(t, policy.Allows(consumer1))
+	assert.Equal(t, uint64(0), policy.Rejections())
+}
+
+func TestPolicyFileRejectsDeniedConsumers(t *testing.T) {
+	policy, path, cleanup := newPolicyInTempDir(t)
+	defer cleanup()
+	writePolicy(t, path, `{"deny": ["0X1"]}`, time.Now())
+
+	assert.NoError(t, policy.reload())
+
+	assert.False(t, policy.Allows(consumer1))
+	assert.True(t, policy.Allows(consumer2))
+	assert.Equal(t, uint64(1), policy.Rejections())
+}
+
+func TestPolicyFileAllowsOnlyListedConsumers(t *testing.T) {
+	policy, path, cleanup := newPolicyInTempDir(t)
+	defer cleanup()
+	writePolicy(t, path, `{"allow": ["0x1", "0x2"], "deny": ["0x2"]}`, time.Now())
+
+	assert.NoError(t, policy.reload())
+
+	assert.True(t, policy.Allows(consumer1))
+	assert.False(t, policy.Allows(consumer2))
+	assert.False(t, policy.Allows(identity.FromAddress("0x3")))
+	assert.Equal(t, uint64(2), policy.Rejections())
+}
+
+func TestPolicyFileFailsToStartWithMalformedFile(t *testing.T) {
+	policy, path, cleanup := newPolicyInTempDir(t)
+	defer cleanup()
+	writePolicy(t, path, `{"deny": `, time.Now())
+
+	assert.Error(t, policy.Start())
+}
+
+func TestPolicyFileKeepsPreviousListsWhenReloadFails(t *testing.T) {
+	policy, path, cleanup := newPolicyInTempDir(t)
+	defer cleanup()
+	modified := time.Now()
+	writePolicy(t, path, `{"deny": ["0x1"]}`, modified)
+	assert.NoError(t, policy.reload())
+
+	writePolicy(t, path, `{"deny": `, modified.Add(time.Second))
+	assert.Error(t, policy.reload())
+
+	assert.False(t, policy.Allows(consumer1))
+}
+
+func TestPolicyFileReloadsChangedFileInBackground(t *testing.T) {
+	policy, path, cleanup := newPolicyInTempDir(t)
+	defer cleanup()
+	modified := time.Now()
+	writePolicy(t, path, `{"deny": ["0x1"]}`, modified)
+
+	assert.NoError(t, policy.Start())
+	defer policy.Stop()
+	assert.False(t, policy.Allows(consumer1))
+
+	writePolicy(t, path, `{"deny": ["0x2"]}`, modified.Add(time.Second))
+	for i := 0; i < 100; i++ {
+		if policy.Allows(consumer1) {
+			assert.False(t, policy.Allows(consumer2))
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("changed policy file was not reloaded")
+}
@@ -0,0 +1,57 @@
+package client_connection
+
+import (
+	"sync"
+)
+
+// subscriberBufferSize limits how many events may be queued for slow subscriber before they are dropped
+const subscriberBufferSize = 50
+
+// EventBus delivers connection events to all subscribers
+type EventBus struct {
+	mutex       sync.Mutex
+	subscribers map[<-chan Event]chan Event
+}
+
+// NewEventBus creates event bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[<-chan Event]chan Event),
+	}
+}
+
+// Publish passes event to every subscriber, without blocking on the ones which do not keep up
+func (bus *EventBus) Publish(event Event) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	for _, subscriber := range bus.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe returns channel which receives all events published after subscription
+func (bus *EventBus) Subscribe() <-chan Event {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	subscriber := make(chan Event, subscriberBufferSize)
+	bus.subscribers[subscriber] = subscriber
+	return subscriber
+}
+
+// Unsubscribe stops delivering events to given channel and closes it
+func (bus *EventBus) Unsubscribe(events <-chan Event) {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+
+	subscriber, exists := bus.subscribers[events]
+	if !exists {
+		return
+	}
+	delete(bus.subscribers, events)
+	close(subscriber)
+}
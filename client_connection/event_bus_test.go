@@ -0,0 +1,41 @@
+package client_connection
+
+import (
+	"github.com/mysterium/node/service"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEventBusDeliversEventsToAllSubscribers(t *testing.T) {
+	bus := NewEventBus()
+	first := bus.Subscribe()
+	second := bus.Subscribe()
+
+	event := Event{Type: StateEvent, State: service.ConnectionConnecting}
+	bus.Publish(event)
+
+	assert.Equal(t, event, <-first)
+	assert.Equal(t, event, <-second)
+}
+
+func TestEventBusStopsDeliveryAfterUnsubscribe(t *testing.T) {
+	bus := NewEventBus()
+	events := bus.Subscribe()
+
+	bus.Unsubscribe(events)
+	bus.Publish(Event{Type: StateEvent, State: service.ConnectionConnecting})
+
+	_, more := <-events
+	assert.False(t, more)
+}
+
+func TestEventBusDropsEventsForSlowSubscriber(t *testing.T) {
+	bus := NewEventBus()
+	events := bus.Subscribe()
+
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		bus.Publish(Event{Type: StateEvent, State: service.ConnectionConnecting})
+	}
+
+	assert.Len(t, events, subscriberBufferSize)
+}
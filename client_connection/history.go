@@ -0,0 +1,81 @@
+package client_connection
+
+import (
+	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	"github.com/mysterium/node/session"
+	"time"
+)
+
+// DisconnectReason tells why connection attempt has finished
+type DisconnectReason string
+
+const (
+	// ReasonDisconnected is set when connection was closed on request
+	ReasonDisconnected = DisconnectReason("disconnected")
+	// ReasonCancelled is set when connection was closed before it got established
+	ReasonCancelled = DisconnectReason("cancelled")
+	// ReasonConnectFailed is set when connection failed to get established
+	ReasonConnectFailed = DisconnectReason("connect_failed")
+	// ReasonConnectionLost is set when established connection dropped and could not be restored
+	ReasonConnectionLost = DisconnectReason("connection_lost")
+)
+
+// HistoryRecord describes single connection attempt, end fields are empty while connection is in progress
+type HistoryRecord struct {
+	ID               int                     `json:"id"`
+	SessionID        session.SessionID       `json:"sessionId"`
+	ProviderID       string                  `json:"providerId"`
+	ProposalID       int                     `json:"proposalId"`
+	Started          time.Time               `json:"started"`
+	Ended            time.Time               `json:"ended"`
+	Stats            bytescount.SessionStats `json:"stats"`
+	DisconnectReason DisconnectReason        `json:"disconnectReason"`
+	Error            string                  `json:"error"`
+}
+
+// HistoryQuery filters history records, zero valued fields match any record
+type HistoryQuery struct {
+	ProviderID string
+	// Records started earlier are skipped
+	From time.Time
+	// Records started later are skipped
+	To time.Time
+	// Count of matching records to skip
+	Offset int
+	// Maximum count of records to return, 0 returns all of them
+	Limit int
+}
+
+// Matches checks if record satisfies all criteria of the query
+func (query HistoryQuery) Matches(record HistoryRecord) bool {
+	if query.ProviderID != "" && query.ProviderID != record.ProviderID {
+		return false
+	}
+	if !query.From.IsZero() && record.Started.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && record.Started.After(query.To) {
+		return false
+	}
+	return true
+}
+
+// filterHistory returns page of records matching the query, newest first
+func filterHistory(records []HistoryRecord, query HistoryQuery) ([]HistoryRecord, int) {
+	matching := make([]HistoryRecord, 0)
+	for i := len(records) - 1; i >= 0; i-- {
+		if query.Matches(records[i]) {
+			matching = append(matching, records[i])
+		}
+	}
+
+	total := len(matching)
+	if query.Offset >= total {
+		return []HistoryRecord{}, total
+	}
+	matching = matching[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(matching) {
+		matching = matching[:query.Limit]
+	}
+	return matching, total
+}
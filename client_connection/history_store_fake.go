@@ -0,0 +1,26 @@
+package client_connection
+
+// NewHistoryStoreFake creates history store which keeps records in memory only
+func NewHistoryStoreFake() *historyStoreFake {
+	return &historyStoreFake{}
+}
+
+type historyStoreFake struct {
+	Records []HistoryRecord
+}
+
+func (store *historyStoreFake) Add(record HistoryRecord) (int, error) {
+	record.ID = len(store.Records) + 1
+	store.Records = append(store.Records, record)
+	return record.ID, nil
+}
+
+func (store *historyStoreFake) Update(record HistoryRecord) error {
+	store.Records[record.ID-1] = record
+	return nil
+}
+
+func (store *historyStoreFake) List(query HistoryQuery) ([]HistoryRecord, int, error) {
+	records, total := filterHistory(store.Records, query)
+	return records, total, nil
+}
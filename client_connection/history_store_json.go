@@ -0,0 +1,94 @@
+package client_connection
+
+import (
+	"fmt"
+	"github.com/mysterium/node/utils/file"
+	"sync"
+)
+
+// maxHistoryRecords is how many of the latest connections history keeps, older ones are forgotten
+const maxHistoryRecords = 1000
+
+// NewHistoryStoreJSON creates history store which keeps the latest records in given JSON file
+func NewHistoryStoreJSON(path string) HistoryStore {
+	return &historyStoreJSON{
+		path:       path,
+		maxRecords: maxHistoryRecords,
+	}
+}
+
+type historyStoreJSON struct {
+	path       string
+	maxRecords int
+
+	mutex   sync.Mutex
+	loaded  bool
+	records []HistoryRecord
+}
+
+func (store *historyStoreJSON) Add(record HistoryRecord) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return 0, err
+	}
+
+	record.ID = 1
+	if len(store.records) > 0 {
+		record.ID = store.records[len(store.records)-1].ID + 1
+	}
+	store.records = append(store.records, record)
+	if len(store.records) > store.maxRecords {
+		store.records = append([]HistoryRecord(nil), store.records[len(store.records)-store.maxRecords:]...)
+	}
+
+	return record.ID, store.save()
+}
+
+func (store *historyStoreJSON) Update(record HistoryRecord) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return err
+	}
+
+	for i := range store.records {
+		if store.records[i].ID == record.ID {
+			store.records[i] = record
+			return store.save()
+		}
+	}
+	return fmt.Errorf("history record %d does not exist", record.ID)
+}
+
+func (store *historyStoreJSON) List(query HistoryQuery) ([]HistoryRecord, int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if err := store.load(); err != nil {
+		return nil, 0, err
+	}
+
+	records, total := filterHistory(store.records, query)
+	return records, total, nil
+}
+
+// load reads records from the file once, missing file means empty history
+func (store *historyStoreJSON) load() error {
+	if store.loaded {
+		return nil
+	}
+
+	if err := file.ReadJSON(store.path, &store.records); err != nil {
+		return err
+	}
+	store.loaded = true
+	return nil
+}
+
+// save replaces the file with all records
+func (store *historyStoreJSON) save() error {
+	return file.WriteJSON(store.path, store.records)
+}
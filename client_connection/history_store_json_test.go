@@ -0,0 +1,126 @@
+package client_connection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createHistoryFile(t *testing.T) (string, func()) {
+	directory, err := ioutil.TempDir("", "history-test-")
+	assert.NoError(t, err)
+
+	return filepath.Join(directory, "history", "connections.json"), func() { os.RemoveAll(directory) }
+}
+
+func TestHistoryStoreJSON_RecordsArePersisted(t *testing.T) {
+	path, cleanup := createHistoryFile(t)
+	defer cleanup()
+
+	started := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := NewHistoryStoreJSON(path)
+	id, err := store.Add(HistoryRecord{ProviderID: "provider-1", Started: started})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, id)
+
+	finished := HistoryRecord{
+		ID:               id,
+		ProviderID:       "provider-1",
+		Started:          started,
+		Ended:            started.Add(time.Minute),
+		DisconnectReason: ReasonDisconnected,
+	}
+	assert.NoError(t, store.Update(finished))
+
+	records, total, err := NewHistoryStoreJSON(path).List(HistoryQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, []HistoryRecord{finished}, records)
+}
+
+func TestHistoryStoreJSON_KeepsLatestRecords(t *testing.T) {
+	path, cleanup := createHistoryFile(t)
+	defer cleanup()
+
+	store := &historyStoreJSON{path: path, maxRecords: 2}
+	for _, providerID := range []string{"provider-1", "provider-2", "provider-3"} {
+		_, err := store.Add(HistoryRecord{ProviderID: providerID})
+		assert.NoError(t, err)
+	}
+
+	records, total, err := NewHistoryStoreJSON(path).List(HistoryQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, []HistoryRecord{{ID: 3, ProviderID: "provider-3"}, {ID: 2, ProviderID: "provider-2"}}, records)
+
+	id, err := NewHistoryStoreJSON(path).Add(HistoryRecord{ProviderID: "provider-4"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+}
+
+func TestHistoryStoreJSON_ListIsEmptyWithoutFile(t *testing.T) {
+	path, cleanup := createHistoryFile(t)
+	defer cleanup()
+
+	records, total, err := NewHistoryStoreJSON(path).List(HistoryQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, records)
+}
+
+func TestHistoryStoreJSON_UpdateOfUnknownRecordFails(t *testing.T) {
+	path, cleanup := createHistoryFile(t)
+	defer cleanup()
+
+	err := NewHistoryStoreJSON(path).Update(HistoryRecord{ID: 5})
+	assert.EqualError(t, err, "history record 5 does not exist")
+}
+
+func TestHistoryStoreJSON_CorruptedFileIsReported(t *testing.T) {
+	path, cleanup := createHistoryFile(t)
+	defer cleanup()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+
+	_, _, err := NewHistoryStoreJSON(path).List(HistoryQuery{})
+	assert.Error(t, err)
+}
+
+func TestFilterHistory(t *testing.T) {
+	day := func(day int) time.Time {
+		return time.Date(2018, 1, day, 0, 0, 0, 0, time.UTC)
+	}
+	records := []HistoryRecord{
+		{ID: 1, ProviderID: "provider-1", Started: day(1)},
+		{ID: 2, ProviderID: "provider-2", Started: day(2)},
+		{ID: 3, ProviderID: "provider-1", Started: day(3)},
+		{ID: 4, ProviderID: "provider-1", Started: day(4)},
+	}
+
+	var tests = []struct {
+		query         HistoryQuery
+		expectedIDs   []int
+		expectedTotal int
+	}{
+		{HistoryQuery{}, []int{4, 3, 2, 1}, 4},
+		{HistoryQuery{ProviderID: "provider-1"}, []int{4, 3, 1}, 3},
+		{HistoryQuery{From: day(2), To: day(3)}, []int{3, 2}, 2},
+		{HistoryQuery{Offset: 1, Limit: 2}, []int{3, 2}, 4},
+		{HistoryQuery{ProviderID: "provider-1", Offset: 2, Limit: 2}, []int{1}, 3},
+		{HistoryQuery{Offset: 10}, []int{}, 4},
+	}
+
+	for _, test := range tests {
+		filtered, total := filterHistory(records, test.query)
+
+		ids := make([]int, 0)
+		for _, record := range filtered {
+			ids = append(ids, record.ID)
+		}
+		assert.Equal(t, test.expectedIDs, ids, "%+v", test.query)
+		assert.Equal(t, test.expectedTotal, total, "%+v", test.query)
+	}
+}
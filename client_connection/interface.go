@@ -1,7 +1,11 @@
 package client_connection
 
 import (
+	"context"
 	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	"github.com/mysterium/node/service"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 	"github.com/mysterium/node/session"
 )
 
@@ -11,6 +15,7 @@ const (
 	NotConnected  = State("NotConnected")
 	Connecting    = State("Connecting")
 	Connected     = State("Connected")
+	Reconnecting  = State("Reconnecting")
 	Disconnecting = State("Disconnecting")
 )
 
@@ -21,8 +26,50 @@ type ConnectionStatus struct {
 }
 
 type Manager interface {
-	Connect(identity identity.Identity, NodeKey string) error
+	Connect(ctx context.Context, identity identity.Identity, NodeKey string, filter ProposalFilter) error
 	Status() ConnectionStatus
 	Disconnect() error
 	Wait() error
+	// ActiveProposal returns proposal which current connection is made with
+	ActiveProposal() (dto_discovery.ServiceProposal, bool)
+}
+
+// EventType tells what kind of change an Event describes
+type EventType string
+
+const (
+	// StatusEvent is published on every change of ConnectionStatus
+	StatusEvent = EventType("status")
+	// StateEvent is published when connection reports its state
+	StateEvent = EventType("state")
+	// StatisticsEvent is published when connection reports transferred bytes
+	StatisticsEvent = EventType("statistics")
+)
+
+// Event describes a change of connection, only the field matching its Type is filled
+type Event struct {
+	Type       EventType
+	Status     ConnectionStatus
+	State      service.ConnectionState
+	Statistics bytescount.SessionStats
+}
+
+// EventPublisher accepts connection events
+type EventPublisher interface {
+	Publish(event Event)
+}
+
+// EventSubscriber allows listening for connection events
+type EventSubscriber interface {
+	Subscribe() <-chan Event
+	Unsubscribe(events <-chan Event)
+}
+
+// HistoryStore keeps records of connection attempts
+type HistoryStore interface {
+	// Add stores new record and returns identifier assigned to it
+	Add(record HistoryRecord) (int, error)
+	Update(record HistoryRecord) error
+	// List returns page of records matching the query, newest first, together with count of all matching records
+	List(query HistoryQuery) ([]HistoryRecord, int, error)
 }
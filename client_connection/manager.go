@@ -1,114 +1,771 @@
 package client_connection
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/client_promise"
 	"github.com/mysterium/node/communication"
+	nats_discovery "github.com/mysterium/node/communication/nats/discovery"
+	"github.com/mysterium/node/dns"
+	"github.com/mysterium/node/firewall"
 	"github.com/mysterium/node/identity"
-	"github.com/mysterium/node/openvpn"
-	"github.com/mysterium/node/openvpn/middlewares/client/auth"
 	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
-	openvpnSession "github.com/mysterium/node/openvpn/session"
 	"github.com/mysterium/node/server"
+	"github.com/mysterium/node/service"
+	"github.com/mysterium/node/service_discovery"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 	"github.com/mysterium/node/session"
-	"path/filepath"
+	"net/url"
+	"sync"
 	"time"
 )
 
+const managerLogPrefix = "[connection-manager] "
+
+// statsSendInterval limits how often statistics are sent to mysterium api
+const statsSendInterval = 1 * time.Minute
+
+// sessionDestroyTimeout limits how long disconnect waits for provider to acknowledge session destroy
+const sessionDestroyTimeout = 5 * time.Second
+
+var errDisconnectStarted = errors.New("disconnect started")
+
+// providerEndpoint is the proposal and its contact which session was created with
+type providerEndpoint struct {
+	proposal dto_discovery.ServiceProposal
+	contact  dto_discovery.Contact
+}
+
 type DialogEstablisherFactory func(identity identity.Identity) communication.DialogEstablisher
 
-type VpnClientFactory func(vpnSession session.SessionDto, identity identity.Identity) (openvpn.Client, error)
+// VpnClientFactory creates client which connects to service of given type with session created by provider
+type VpnClientFactory func(serviceType string, vpnSession session.SessionDto, identity identity.Identity, stateCallback service.ConnectionStateCallback) (service.Connection, error)
+
+// PromiseIssuerFactory creates issuer which pays provider for the session over given dialog
+type PromiseIssuerFactory func(
+	myID identity.Identity,
+	providerID identity.Identity,
+	sessionID session.SessionID,
+	paymentMethod dto_discovery.PaymentMethod,
+	sender communication.Sender,
+) client_promise.Issuer
 
 type connectionManager struct {
 	//these are passed on creation
 	mysteriumClient          server.Client
 	dialogEstablisherFactory DialogEstablisherFactory
 	vpnClientFactory         VpnClientFactory
+	promiseIssuerFactory     PromiseIssuerFactory
 	statsKeeper              bytescount.SessionStatsKeeper
+	reconnectPolicy          ReconnectPolicy
+	eventPublisher           EventPublisher
+	proposalSelector         ProposalSelector
+	extractor                identity.Extractor
+	killSwitch               firewall.FirewallService
+	historyStore             HistoryStore
 	//these are populated by Connect at runtime
-	dialog    communication.Dialog
-	vpnClient openvpn.Client
-	status    ConnectionStatus
+	mutex             sync.RWMutex
+	dialog            communication.Dialog
+	vpnClient         service.Connection
+	promiseIssuer     client_promise.Issuer
+	proposal          dto_discovery.ServiceProposal
+	status            ConnectionStatus
+	cancelConnect     context.CancelFunc
+	cancelConnection  context.CancelFunc
+	connectionDone    chan bool
+	connectionError   error
+	killSwitchEnabled bool
+	history           HistoryRecord
 }
 
 func NewManager(mysteriumClient server.Client, dialogEstablisherFactory DialogEstablisherFactory,
-	vpnClientFactory VpnClientFactory, statsKeeper bytescount.SessionStatsKeeper) *connectionManager {
+	vpnClientFactory VpnClientFactory, promiseIssuerFactory PromiseIssuerFactory, statsKeeper bytescount.SessionStatsKeeper, reconnectPolicy ReconnectPolicy,
+	eventPublisher EventPublisher, proposalSelector ProposalSelector, extractor identity.Extractor, killSwitch firewall.FirewallService,
+	historyStore HistoryStore) *connectionManager {
 	return &connectionManager{
 		mysteriumClient:          mysteriumClient,
 		dialogEstablisherFactory: dialogEstablisherFactory,
 		vpnClientFactory:         vpnClientFactory,
+		promiseIssuerFactory:     promiseIssuerFactory,
 		statsKeeper:              statsKeeper,
+		reconnectPolicy:          reconnectPolicy,
+		eventPublisher:           eventPublisher,
+		proposalSelector:         proposalSelector,
+		extractor:                extractor,
+		killSwitch:               killSwitch,
+		historyStore:             historyStore,
 		dialog:                   nil,
 		vpnClient:                nil,
 		status:                   statusNotConnected(),
 	}
 }
 
-func (manager *connectionManager) Connect(myID identity.Identity, nodeKey string) error {
-	manager.status = statusConnecting()
+func (manager *connectionManager) Connect(ctx context.Context, myID identity.Identity, nodeKey string, filter ProposalFilter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
 
-	providerID := identity.FromAddress(nodeKey)
+	ctx, cancelConnect := context.WithCancel(ctx)
+	defer cancelConnect()
 
-	proposals, err := manager.mysteriumClient.FindProposals(nodeKey)
+	manager.mutex.Lock()
+	err := manager.setStatus(statusConnecting())
+	if err == nil {
+		manager.cancelConnect = cancelConnect
+		manager.startHistory(nodeKey)
+	}
+	manager.mutex.Unlock()
 	if err != nil {
-		manager.status = statusError(err)
 		return err
 	}
+
+	// traffic may still be blocked after previous connection was lost
+	if err := manager.disableKillSwitch(); err != nil {
+		return manager.connectFailed(err)
+	}
+
+	providerID := identity.FromAddress(nodeKey)
+
+	proposals, err := manager.mysteriumClient.FindProposals(ctx, nodeKey)
+	if err != nil {
+		return manager.connectFailed(err)
+	}
 	if len(proposals) == 0 {
-		err = errors.New("node has no service proposals")
-		manager.status = statusError(err)
-		return err
+		return manager.connectFailed(errors.New("node has no service proposals"))
+	}
+	proposals = manager.verifiedProposals(proposals)
+	if len(proposals) == 0 {
+		return manager.connectFailed(errors.New("node has no service proposals signed by provider"))
+	}
+	proposals = manager.proposalSelector(proposals, filter)
+	if len(proposals) == 0 {
+		return manager.connectFailed(errors.New("node has no service proposals matching the request"))
 	}
-	proposal := proposals[0]
 
-	dialogEstablisher := manager.dialogEstablisherFactory(myID)
-	manager.dialog, err = dialogEstablisher.CreateDialog(providerID, proposal.ProviderContacts[0])
+	dialog, vpnSession, endpoint, err := manager.createSession(ctx, myID, providerID, proposals)
 	if err != nil {
-		manager.status = statusError(err)
-		return err
+		return manager.connectFailed(err)
 	}
 
-	vpnSession, err := session.RequestSessionCreate(manager.dialog, proposal.ID)
+	vpnClient, vpnExiting, err := manager.newVpnClient(endpoint.proposal.ServiceType, *vpnSession, myID)
 	if err != nil {
-		manager.status = statusError(err)
-		return err
+		dialog.Close()
+		return manager.connectFailed(err)
+	}
+
+	if err := manager.enableKillSwitch(vpnClient.KillSwitchRules(), endpoint.contact); err != nil {
+		dialog.Close()
+		return manager.connectFailed(err)
+	}
+
+	if err := vpnClient.Start(); err != nil {
+		dialog.Close()
+		manager.disableKillSwitch()
+		return manager.connectFailed(err)
 	}
 
-	manager.vpnClient, err = manager.vpnClientFactory(*vpnSession, myID)
+	connectionCtx, cancelConnection := context.WithCancel(context.Background())
+	connectionDone := make(chan bool)
 
-	if err := manager.vpnClient.Start(); err != nil {
-		manager.status = statusError(err)
-		return err
+	manager.mutex.Lock()
+	if manager.status.State != Connecting {
+		manager.mutex.Unlock()
+		cancelConnection()
+		vpnClient.Stop()
+		dialog.Close()
+		manager.disableKillSwitch()
+		return manager.connectFailed(ErrConnectionCancelled)
 	}
+	manager.cancelConnect = nil
+	manager.dialog = dialog
+	manager.vpnClient = vpnClient
+	manager.promiseIssuer = manager.startPromiseIssuer(myID, providerID, endpoint.proposal, vpnSession.ID, dialog)
+	manager.proposal = endpoint.proposal
+	manager.cancelConnection = cancelConnection
+	manager.connectionDone = connectionDone
+	manager.connectionError = nil
+	manager.recordSession(endpoint.proposal.ID, vpnSession.ID)
+	manager.statsKeeper.MarkSessionStart(vpnSession.ID)
+	manager.setStatus(statusConnected(vpnSession.ID))
+	manager.mutex.Unlock()
 
-	manager.statsKeeper.MarkSessionStart()
-	manager.status = statusConnected(vpnSession.ID)
+	go manager.superviseConnection(connectionCtx, myID, providerID, endpoint, vpnClient, vpnExiting, connectionDone)
 	return nil
 }
 
+// verifiedProposals rejects proposals which are not signed by their provider
+func (manager *connectionManager) verifiedProposals(proposals []dto_discovery.ServiceProposal) []dto_discovery.ServiceProposal {
+	verified := make([]dto_discovery.ServiceProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		if err := service_discovery.VerifyProposal(proposal, manager.extractor); err != nil {
+			log.Warn(managerLogPrefix, "Rejecting proposal ", proposal.ID, " of ", proposal.ProviderID, ": ", err)
+			continue
+		}
+		verified = append(verified, proposal)
+	}
+	return verified
+}
+
+// createSession tries given proposals and their contacts in order, until session is created with one of them
+func (manager *connectionManager) createSession(
+	ctx context.Context,
+	myID identity.Identity,
+	providerID identity.Identity,
+	proposals []dto_discovery.ServiceProposal,
+) (communication.Dialog, *session.SessionDto, providerEndpoint, error) {
+	dialogEstablisher := manager.dialogEstablisherFactory(myID)
+
+	var lastErr error
+	for _, proposal := range proposals {
+		for _, contact := range proposal.ProviderContacts {
+			if ctx.Err() != nil {
+				return nil, nil, providerEndpoint{}, ctx.Err()
+			}
+
+			dialog, err := dialogEstablisher.CreateDialog(ctx, providerID, contact)
+			if err != nil {
+				log.Warn(managerLogPrefix, "Failed to create dialog with contact ", contact.Type, ": ", err)
+				lastErr = err
+				continue
+			}
+
+			vpnSession, err := session.RequestSessionCreate(ctx, dialog, proposal.ID)
+			if err != nil {
+				log.Warn(managerLogPrefix, "Failed to create session for proposal ", proposal.ID, ": ", err)
+				dialog.Close()
+				if createErr, ok := err.(*session.CreateError); ok && createErr.AppliesToProvider() {
+					return nil, nil, providerEndpoint{}, err
+				}
+				lastErr = err
+				continue
+			}
+
+			return dialog, vpnSession, providerEndpoint{proposal, contact}, nil
+		}
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("node has no contacts to connect to")
+	}
+	return nil, nil, providerEndpoint{}, lastErr
+}
+
+// connectFailed finishes connection attempt which failed or was cancelled by Disconnect
+func (manager *connectionManager) connectFailed(err error) error {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.cancelConnect = nil
+	if manager.status.State == Disconnecting {
+		manager.finishHistory(ReasonCancelled, ErrConnectionCancelled)
+		manager.setStatus(statusNotConnected())
+		return ErrConnectionCancelled
+	}
+
+	manager.finishHistory(ReasonConnectFailed, err)
+	manager.setStatus(statusError(err))
+	return err
+}
+
 func (manager *connectionManager) Status() ConnectionStatus {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
 	return manager.status
 }
 
 func (manager *connectionManager) Disconnect() error {
-	manager.status = statusDisconnecting()
+	manager.mutex.Lock()
+	if manager.status.State == NotConnected && manager.killSwitchEnabled {
+		manager.mutex.Unlock()
+		return manager.disableKillSwitch()
+	}
+	previousState := manager.status.State
+	sessionID := manager.status.SessionID
+	if err := manager.setStatus(statusDisconnecting()); err != nil {
+		manager.mutex.Unlock()
+		return err
+	}
+	if previousState == Connecting {
+		// Connect gets cancelled and tears down whatever it managed to establish
+		manager.cancelConnect()
+		manager.mutex.Unlock()
+		return nil
+	}
+	if manager.cancelConnection != nil {
+		manager.cancelConnection()
+		manager.cancelConnection = nil
+	}
+	vpnClient := manager.vpnClient
+	promiseIssuer := manager.promiseIssuer
+	dialog := manager.dialog
+	connectionDone := manager.connectionDone
+	manager.mutex.Unlock()
+
+	var err error
+	if vpnClient != nil {
+		err = vpnClient.Stop()
+	}
+	if promiseIssuer != nil {
+		promiseIssuer.Stop()
+	}
+	if dialog != nil {
+		destroySession(context.Background(), dialog, sessionID)
+		if closeErr := dialog.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if connectionDone != nil {
+		<-connectionDone
+	}
+	if killSwitchErr := manager.disableKillSwitch(); err == nil {
+		err = killSwitchErr
+	}
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.vpnClient = nil
+	manager.promiseIssuer = nil
+	manager.dialog = nil
+	manager.finishHistory(ReasonDisconnected, err)
+	manager.statsKeeper.MarkSessionEnd()
+	manager.setStatus(statusNotConnected())
+	return err
+}
+
+// ActiveProposal returns proposal which current connection is made with, while it is connected or reconnecting
+func (manager *connectionManager) ActiveProposal() (dto_discovery.ServiceProposal, bool) {
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+
+	if manager.status.State != Connected && manager.status.State != Reconnecting {
+		return dto_discovery.ServiceProposal{}, false
+	}
+	return manager.proposal, true
+}
+
+// Wait blocks until current connection is finished, either by Disconnect or by failing to reconnect
+func (manager *connectionManager) Wait() error {
+	manager.mutex.RLock()
+	connectionDone := manager.connectionDone
+	manager.mutex.RUnlock()
+
+	if connectionDone == nil {
+		return nil
+	}
+	<-connectionDone
+
+	manager.mutex.RLock()
+	defer manager.mutex.RUnlock()
+	return manager.connectionError
+}
+
+// setStatus moves connection to given status if transition is legal, mutex must be held by caller
+func (manager *connectionManager) setStatus(status ConnectionStatus) error {
+	if err := validateTransition(manager.status.State, status.State); err != nil {
+		return err
+	}
+
+	manager.status = status
+	manager.eventPublisher.Publish(Event{Type: StatusEvent, Status: status})
+	return nil
+}
+
+func (manager *connectionManager) startVpnClient(serviceType string, vpnSession session.SessionDto, myID identity.Identity) (service.Connection, chan bool, error) {
+	vpnClient, vpnExiting, err := manager.newVpnClient(serviceType, vpnSession, myID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := vpnClient.Start(); err != nil {
+		return nil, nil, err
+	}
+
+	return vpnClient, vpnExiting, nil
+}
+
+// newVpnClient creates connection of given session, which reports its states to connection manager
+func (manager *connectionManager) newVpnClient(serviceType string, vpnSession session.SessionDto, myID identity.Identity) (service.Connection, chan bool, error) {
+	vpnExiting := make(chan bool)
+	exitingOnce := sync.Once{}
+
+	stateCallback := func(vpnState service.ConnectionState) error {
+		manager.eventPublisher.Publish(Event{Type: StateEvent, State: vpnState})
+
+		switch vpnState {
+		case service.ConnectionReconnecting:
+			manager.updateSessionState(vpnSession.ID, Connected, Reconnecting)
+		case service.ConnectionConnected:
+			manager.updateSessionState(vpnSession.ID, Reconnecting, Connected)
+		case service.ConnectionExiting:
+			manager.updateSessionState(vpnSession.ID, Connected, Reconnecting)
+			exitingOnce.Do(func() { close(vpnExiting) })
+		}
+		return nil
+	}
+
+	vpnClient, err := manager.vpnClientFactory(serviceType, vpnSession, myID, stateCallback)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return vpnClient, vpnExiting, nil
+}
+
+// startPromiseIssuer starts paying for the session, service is used even if its proposal can not be paid for
+func (manager *connectionManager) startPromiseIssuer(
+	myID identity.Identity,
+	providerID identity.Identity,
+	proposal dto_discovery.ServiceProposal,
+	sessionID session.SessionID,
+	dialog communication.Dialog,
+) client_promise.Issuer {
+	promiseIssuer := manager.promiseIssuerFactory(myID, providerID, sessionID, proposal.PaymentMethod, dialog)
+	if err := promiseIssuer.Start(); err != nil {
+		log.Warn(managerLogPrefix, "Failed to start paying for session ", sessionID, ": ", err)
+	}
+	return promiseIssuer
+}
+
+// updateSessionState changes state of given session, but only when it is still current and in expected state
+func (manager *connectionManager) updateSessionState(sessionID session.SessionID, expectedState, newState State) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	if manager.status.SessionID != sessionID || manager.status.State != expectedState {
+		return
+	}
+	manager.setStatus(ConnectionStatus{newState, sessionID, nil})
+}
 
-	if manager.vpnClient != nil {
-		if err := manager.vpnClient.Stop(); err != nil {
-			return err
+func (manager *connectionManager) superviseConnection(
+	ctx context.Context,
+	myID identity.Identity,
+	providerID identity.Identity,
+	endpoint providerEndpoint,
+	vpnClient service.Connection,
+	vpnExiting chan bool,
+	connectionDone chan bool,
+) {
+	defer close(connectionDone)
+
+	for {
+		vpnErr := waitForVpnClientExit(vpnClient, vpnExiting)
+
+		manager.mutex.Lock()
+		select {
+		case <-ctx.Done():
+			manager.mutex.Unlock()
+			return
+		default:
+		}
+		manager.vpnClient = nil
+		promiseIssuer := manager.promiseIssuer
+		manager.promiseIssuer = nil
+		manager.mutex.Unlock()
+
+		log.Warn(managerLogPrefix, "Openvpn client exited unexpectedly: ", vpnErr)
+		vpnClient.Stop()
+		if promiseIssuer != nil {
+			promiseIssuer.Stop()
+		}
+
+		var err error
+		vpnClient, vpnExiting, err = manager.reconnect(ctx, myID, providerID, endpoint)
+		if err == errDisconnectStarted {
+			return
+		}
+		if err != nil {
+			log.Error(managerLogPrefix, "Connection lost: ", err)
+			manager.loseConnection(ctx, err)
+			return
+		}
+	}
+}
+
+func waitForVpnClientExit(vpnClient service.Connection, vpnExiting chan bool) error {
+	vpnExited := make(chan error, 1)
+	go func() {
+		vpnExited <- vpnClient.Wait()
+	}()
+
+	select {
+	case err := <-vpnExited:
+		return err
+	case <-vpnExiting:
+		return errors.New("openvpn client is exiting")
+	}
+}
+
+func (manager *connectionManager) reconnect(
+	ctx context.Context,
+	myID identity.Identity,
+	providerID identity.Identity,
+	endpoint providerEndpoint,
+) (service.Connection, chan bool, error) {
+	manager.mutex.Lock()
+	staleSessions := make([]session.SessionID, 0)
+	if manager.status.SessionID != "" {
+		staleSessions = append(staleSessions, manager.status.SessionID)
+	}
+	if manager.status.State == Connected {
+		manager.setStatus(statusReconnecting(manager.status.SessionID))
+	}
+	manager.mutex.Unlock()
+
+	for attempt := 1; attempt <= manager.reconnectPolicy.MaxAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, nil, errDisconnectStarted
+		case <-time.After(manager.reconnectPolicy.Delay(attempt)):
+		}
+		log.Info(managerLogPrefix, "Reconnecting, attempt ", attempt)
+
+		staleSessions = manager.destroyStaleSessions(ctx, staleSessions)
+		vpnSession, err := manager.renewSession(ctx, myID, providerID, endpoint)
+		if err == errDisconnectStarted {
+			return nil, nil, err
+		}
+		if err != nil {
+			log.Warn(managerLogPrefix, "Failed to renew session: ", err)
+			continue
+		}
+		// dialog may be re-established by renewal, stale sessions can be destroyed through it
+		staleSessions = manager.destroyStaleSessions(ctx, staleSessions)
+
+		vpnClient, vpnExiting, err := manager.startVpnClient(endpoint.proposal.ServiceType, *vpnSession, myID)
+		if err != nil {
+			log.Warn(managerLogPrefix, "Failed to start openvpn client: ", err)
+			staleSessions = append(staleSessions, vpnSession.ID)
+			continue
 		}
+
+		manager.mutex.Lock()
+		select {
+		case <-ctx.Done():
+			manager.mutex.Unlock()
+			vpnClient.Stop()
+			return nil, nil, errDisconnectStarted
+		default:
+		}
+		manager.vpnClient = vpnClient
+		manager.promiseIssuer = manager.startPromiseIssuer(myID, providerID, endpoint.proposal, vpnSession.ID, manager.dialog)
+		manager.recordSession(endpoint.proposal.ID, vpnSession.ID)
+		manager.statsKeeper.MarkSessionStart(vpnSession.ID)
+		manager.setStatus(statusConnected(vpnSession.ID))
+		manager.mutex.Unlock()
+
+		log.Info(managerLogPrefix, "Reconnected with session: ", vpnSession.ID)
+		return vpnClient, vpnExiting, nil
+	}
+
+	return nil, nil, fmt.Errorf("failed to reconnect after %d attempts", manager.reconnectPolicy.MaxAttempts)
+}
+
+// renewSession requests new session over current dialog, re-establishing the dialog if it is not usable anymore
+func (manager *connectionManager) renewSession(
+	ctx context.Context,
+	myID identity.Identity,
+	providerID identity.Identity,
+	endpoint providerEndpoint,
+) (*session.SessionDto, error) {
+	manager.mutex.RLock()
+	dialog := manager.dialog
+	manager.mutex.RUnlock()
+
+	vpnSession, err := session.RequestSessionCreate(ctx, dialog, endpoint.proposal.ID)
+	if err == nil {
+		return vpnSession, nil
+	}
+	if ctx.Err() != nil {
+		return nil, errDisconnectStarted
 	}
+	log.Warn(managerLogPrefix, "Failed to request session over existing dialog, re-establishing it: ", err)
+	dialog.Close()
+
+	dialogEstablisher := manager.dialogEstablisherFactory(myID)
+	dialog, err = dialogEstablisher.CreateDialog(ctx, providerID, endpoint.contact)
+	if err != nil {
+		return nil, err
+	}
+
+	manager.mutex.Lock()
+	select {
+	case <-ctx.Done():
+		manager.mutex.Unlock()
+		dialog.Close()
+		return nil, errDisconnectStarted
+	default:
+	}
+	manager.dialog = dialog
+	manager.mutex.Unlock()
+
+	return session.RequestSessionCreate(ctx, dialog, endpoint.proposal.ID)
+}
+
+func (manager *connectionManager) loseConnection(ctx context.Context, err error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return
+	default:
+	}
+
 	if manager.dialog != nil {
-		if err := manager.dialog.Close(); err != nil {
-			return err
+		manager.dialog.Close()
+	}
+	manager.dialog = nil
+	manager.vpnClient = nil
+	manager.cancelConnection()
+	manager.cancelConnection = nil
+	manager.connectionError = err
+	manager.finishHistory(ReasonConnectionLost, err)
+	manager.statsKeeper.MarkSessionEnd()
+	manager.setStatus(statusError(err))
+}
+
+// destroySession lets provider free resources of the session, failure is not fatal as provider expires sessions anyway
+func destroySession(ctx context.Context, dialog communication.Dialog, sessionID session.SessionID) error {
+	ctx, cancel := context.WithTimeout(ctx, sessionDestroyTimeout)
+	defer cancel()
+
+	err := session.RequestSessionDestroy(ctx, dialog, sessionID)
+	if err != nil {
+		log.Warn(managerLogPrefix, "Failed to destroy session ", sessionID, ": ", err)
+	}
+	return err
+}
+
+// destroyStaleSessions destroys sessions replaced by reconnect, so that they do not count against consumer's quota
+// on provider. Sessions which failed to be destroyed are returned.
+func (manager *connectionManager) destroyStaleSessions(ctx context.Context, staleSessions []session.SessionID) []session.SessionID {
+	manager.mutex.RLock()
+	dialog := manager.dialog
+	manager.mutex.RUnlock()
+
+	remaining := make([]session.SessionID, 0)
+	for _, sessionID := range staleSessions {
+		if err := destroySession(ctx, dialog, sessionID); err != nil {
+			remaining = append(remaining, sessionID)
 		}
 	}
+	return remaining
+}
+
+// startHistory records new connection attempt, mutex must be held by caller
+func (manager *connectionManager) startHistory(providerID string) {
+	manager.history = HistoryRecord{
+		ProviderID: providerID,
+		Started:    time.Now(),
+	}
+
+	id, err := manager.historyStore.Add(manager.history)
+	if err != nil {
+		log.Warn(managerLogPrefix, "Failed to record connection history: ", err)
+		return
+	}
+	manager.history.ID = id
+}
 
-	manager.status = statusNotConnected()
+// recordSession saves session which was established for current connection attempt, mutex must be held by caller
+func (manager *connectionManager) recordSession(proposalID int, sessionID session.SessionID) {
+	// statistics of the session being replaced after reconnect are still kept by statsKeeper
+	manager.addSessionStats()
+	manager.history.ProposalID = proposalID
+	manager.history.SessionID = sessionID
+	manager.updateHistory()
+}
+
+// finishHistory saves outcome of current connection attempt, mutex must be held by caller
+func (manager *connectionManager) finishHistory(reason DisconnectReason, err error) {
+	manager.history.Ended = time.Now()
+	manager.history.DisconnectReason = reason
+	if err != nil {
+		manager.history.Error = err.Error()
+	}
+	manager.addSessionStats()
+	manager.updateHistory()
+	manager.history = HistoryRecord{}
+}
+
+// addSessionStats adds statistics of current session to current connection attempt, mutex must be held by caller
+func (manager *connectionManager) addSessionStats() {
+	if manager.history.SessionID == "" {
+		return
+	}
+	stats := manager.statsKeeper.Retrieve()
+	manager.history.Stats.BytesSent += stats.BytesSent
+	manager.history.Stats.BytesReceived += stats.BytesReceived
+}
+
+func (manager *connectionManager) updateHistory() {
+	// record was not stored when the attempt started, so there is nothing to update
+	if manager.history.ID == 0 {
+		return
+	}
+	if err := manager.historyStore.Update(manager.history); err != nil {
+		log.Warn(managerLogPrefix, "Failed to record connection history: ", err)
+	}
+}
+
+// enableKillSwitch blocks all traffic except the one needed by connection and to provider contact
+func (manager *connectionManager) enableKillSwitch(rules []firewall.RuleAllowed, contact dto_discovery.Contact) error {
+	if manager.killSwitch == nil {
+		return nil
+	}
+
+	for _, rule := range rules {
+		manager.killSwitch.Allow(rule)
+	}
+	// session is renewed through the contact while tunnel is down
+	for _, address := range contactAddresses(contact) {
+		manager.killSwitch.Allow(firewall.RuleAllowed{RemoteAddress: address})
+	}
+	if err := manager.killSwitch.Start(); err != nil {
+		return err
+	}
+
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+
+	manager.killSwitchEnabled = true
 	return nil
 }
 
-func (manager *connectionManager) Wait() error {
-	return manager.vpnClient.Wait()
+// disableKillSwitch unblocks traffic, kill switch stays enabled after connection is lost until this is called
+func (manager *connectionManager) disableKillSwitch() error {
+	manager.mutex.Lock()
+	enabled := manager.killSwitchEnabled
+	manager.killSwitchEnabled = false
+	manager.mutex.Unlock()
+
+	if !enabled {
+		return nil
+	}
+	return manager.killSwitch.Stop()
+}
+
+// contactAddresses returns hosts which communication with provider goes through
+func contactAddresses(contact dto_discovery.Contact) []string {
+	contactNats, ok := contact.Definition.(nats_discovery.ContactNATSV1)
+	if !ok {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(contactNats.BrokerAddresses))
+	for _, brokerAddress := range contactNats.BrokerAddresses {
+		brokerURL, err := url.Parse(brokerAddress)
+		if err != nil || brokerURL.Hostname() == "" {
+			continue
+		}
+		addresses = append(addresses, brokerURL.Hostname())
+	}
+	return addresses
 }
 
 func statusError(err error) ConnectionStatus {
@@ -123,6 +780,10 @@ func statusConnected(sessionID session.SessionID) ConnectionStatus {
 	return ConnectionStatus{Connected, sessionID, nil}
 }
 
+func statusReconnecting(sessionID session.SessionID) ConnectionStatus {
+	return ConnectionStatus{Reconnecting, sessionID, nil}
+}
+
 func statusNotConnected() ConnectionStatus {
 	return ConnectionStatus{NotConnected, "", nil}
 }
@@ -131,32 +792,57 @@ func statusDisconnecting() ConnectionStatus {
 	return ConnectionStatus{Disconnecting, "", nil}
 }
 
+// ConfigureVpnClientFactory creates factory, which connects with plugin registered for service type of proposal
 func ConfigureVpnClientFactory(mysteriumAPIClient server.Client, vpnClientRuntimeDirectory string,
-	signerFactory identity.SignerFactory, statsKeeper bytescount.SessionStatsKeeper) VpnClientFactory {
-	return func(vpnSession session.SessionDto, id identity.Identity) (openvpn.Client, error) {
-		vpnConfig, err := openvpn.NewClientConfigFromString(
-			vpnSession.Config,
-			filepath.Join(vpnClientRuntimeDirectory, "client.ovpn"),
-		)
-		if err != nil {
-			return nil, err
+	signerFactory identity.SignerFactory, statsKeeper bytescount.SessionStatsKeeper, eventPublisher EventPublisher,
+	dnsProtector dns.Protector) VpnClientFactory {
+	return func(serviceType string, vpnSession session.SessionDto, id identity.Identity, stateCallback service.ConnectionStateCallback) (service.Connection, error) {
+		plugin, found := service.FindPlugin(serviceType)
+		if !found {
+			return nil, fmt.Errorf("unsupported service type: %s", serviceType)
 		}
 
 		signer := signerFactory(id)
 
-		statsSaver := bytescount.NewSessionStatsSaver(statsKeeper)
-		statsSender := bytescount.NewSessionStatsSender(mysteriumAPIClient, vpnSession.ID, signer)
-		statsHandler := bytescount.NewCompositeStatsHandler(statsSaver, statsSender)
+		statsSaver := bytescount.NewSessionStatsSaver(statsKeeper, vpnSession.ID)
+		statsSender := bytescount.NewThrottledStatsHandler(
+			bytescount.NewSessionStatsSender(mysteriumAPIClient, vpnSession.ID, signer),
+			statsSendInterval,
+			time.Now,
+		)
+		statsPublisher := func(stats bytescount.SessionStats) error {
+			eventPublisher.Publish(Event{Type: StatisticsEvent, Statistics: stats})
+			return nil
+		}
+		statsHandler := bytescount.NewCompositeStatsHandler(statsSaver, statsSender, statsPublisher)
+
+		connection, err := plugin.NewConnection(service.ConnectionParams{
+			Session:          vpnSession,
+			Signer:           signer,
+			DirectoryRuntime: vpnClientRuntimeDirectory,
+			DNSProtector:     dnsProtector,
+			StateCallback:    stateCallback,
+			StatsHandler: func(stats service.ConnectionStats) error {
+				return statsHandler(bytescount.SessionStats{
+					BytesSent:     stats.BytesSent,
+					BytesReceived: stats.BytesReceived,
+				})
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return connection, nil
+	}
+}
 
-		credentialsProvider := openvpnSession.SignatureCredentialsProvider(vpnSession.ID, signer)
-		vpnMiddlewares := []openvpn.ManagementMiddleware{
-			bytescount.NewMiddleware(statsHandler, 1*time.Minute),
-			auth.NewMiddleware(credentialsProvider),
+// ConfigurePromiseIssuerFactory creates factory, which pays for traffic of current session counted by stats keeper
+func ConfigurePromiseIssuerFactory(signerFactory identity.SignerFactory, statsKeeper bytescount.SessionStatsKeeper) PromiseIssuerFactory {
+	return func(myID identity.Identity, providerID identity.Identity, sessionID session.SessionID, paymentMethod dto_discovery.PaymentMethod, sender communication.Sender) client_promise.Issuer {
+		getTraffic := func() uint64 {
+			stats := statsKeeper.Retrieve()
+			return uint64(stats.BytesSent + stats.BytesReceived)
 		}
-		return openvpn.NewClient(
-			vpnConfig,
-			vpnClientRuntimeDirectory,
-			vpnMiddlewares...,
-		), nil
+		return client_promise.NewIssuer(myID, providerID, sessionID, paymentMethod, signerFactory(myID), sender, getTraffic)
 	}
 }
@@ -1,12 +1,20 @@
 package client_connection
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"github.com/mysterium/node/client_promise"
 	"github.com/mysterium/node/communication"
+	nats_discovery "github.com/mysterium/node/communication/nats/discovery"
+	"github.com/mysterium/node/firewall"
 	"github.com/mysterium/node/identity"
-	"github.com/mysterium/node/openvpn"
+	"github.com/mysterium/node/money"
 	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
 	"github.com/mysterium/node/server"
+	"github.com/mysterium/node/service"
+	"github.com/mysterium/node/service_discovery"
 	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 	"github.com/mysterium/node/session"
 	"github.com/stretchr/testify/assert"
@@ -21,13 +29,20 @@ type testContext struct {
 	connManager         *connectionManager
 	fakeDiscoveryClient *server.ClientFake
 	fakeOpenVpn         *fakeOpenvpnClient
+	fakeDialog          *fakeDialog
 	fakeStatsKeeper     *fakeSessionStatsKeeper
+	eventBus            *EventBus
+	historyStore        *historyStoreFake
+	vpnServiceType      string
+	promiseIssuer       *client_promise.IssuerFake
+	paidSession         session.SessionID
 }
 
 var (
 	activeProviderID      = "vpn-node-1"
 	activeProviderContact = dto_discovery.Contact{}
 	activeProposal        = dto_discovery.ServiceProposal{
+		ServiceType:      "openvpn",
 		ProviderID:       activeProviderID,
 		ProviderContacts: []dto_discovery.Contact{activeProviderContact},
 	}
@@ -35,24 +50,42 @@ var (
 
 func (tc *testContext) SetupTest() {
 	tc.fakeDiscoveryClient = server.NewClientFake()
-	tc.fakeDiscoveryClient.RegisterProposal(activeProposal, nil)
+	tc.fakeDiscoveryClient.RegisterProposal(activeProposal, &identity.SignerFake{})
 
+	tc.fakeDialog = &fakeDialog{
+		requestStarted: make(chan int, 1),
+	}
 	dialogEstablisherFactory := func(identity identity.Identity) communication.DialogEstablisher {
-		return &fakeDialog{}
+		return tc.fakeDialog
 	}
 
 	tc.fakeOpenVpn = &fakeOpenvpnClient{
-		false,
-		make(chan int, 1),
-		make(chan int, 1),
-		nil,
+		delayStateEnteredNotifier: make(chan int, 1),
+		resumeFromDelay:           make(chan int, 1),
+		startNotifier:             make(chan int, 10),
 	}
-	fakeVpnClientFactory := func(vpnSession session.SessionDto, identity identity.Identity) (openvpn.Client, error) {
+	fakeVpnClientFactory := func(serviceType string, vpnSession session.SessionDto, identity identity.Identity, stateCallback service.ConnectionStateCallback) (service.Connection, error) {
+		tc.vpnServiceType = serviceType
+		tc.fakeOpenVpn.stateCallback = stateCallback
 		return tc.fakeOpenVpn, nil
 	}
+	tc.promiseIssuer = &client_promise.IssuerFake{}
+	promiseIssuerFactory := func(myID identity.Identity, providerID identity.Identity, sessionID session.SessionID, paymentMethod dto_discovery.PaymentMethod, sender communication.Sender) client_promise.Issuer {
+		tc.paidSession = sessionID
+		return tc.promiseIssuer
+	}
 	tc.fakeStatsKeeper = &fakeSessionStatsKeeper{}
+	tc.eventBus = NewEventBus()
+	tc.historyStore = NewHistoryStoreFake()
+
+	reconnectPolicy := ReconnectPolicy{
+		MaxAttempts:  2,
+		InitialDelay: time.Millisecond,
+		MaxDelay:     time.Millisecond,
+		Multiplier:   1,
+	}
 
-	tc.connManager = NewManager(tc.fakeDiscoveryClient, dialogEstablisherFactory, fakeVpnClientFactory, tc.fakeStatsKeeper)
+	tc.connManager = NewManager(tc.fakeDiscoveryClient, dialogEstablisherFactory, fakeVpnClientFactory, promiseIssuerFactory, tc.fakeStatsKeeper, reconnectPolicy, tc.eventBus, SelectCheapestProposals, &providerExtractorFake{}, nil, tc.historyStore)
 }
 
 func (tc *testContext) TestWhenNoConnectionIsMadeStatusIsNotConnected() {
@@ -62,7 +95,7 @@ func (tc *testContext) TestWhenNoConnectionIsMadeStatusIsNotConnected() {
 func (tc *testContext) TestWithUnknownNodeKeyConnectionIsNotMade() {
 	noProposalsError := errors.New("node has no service proposals")
 
-	assert.Error(tc.T(), tc.connManager.Connect(identity.FromAddress("identity-1"), "unknown-node"))
+	assert.Error(tc.T(), tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "unknown-node", ProposalFilter{}))
 	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", noProposalsError}, tc.connManager.Status())
 
 	assert.False(tc.T(), tc.fakeStatsKeeper.SessionStartMarked)
@@ -72,38 +105,81 @@ func (tc *testContext) TestOnConnectErrorStatusIsNotConnectedAndLastErrorIsSetAn
 	fatalVpnError := errors.New("fatal connection error")
 	tc.fakeOpenVpn.onConnectReturnError = fatalVpnError
 
-	assert.Error(tc.T(), tc.connManager.Connect(identity.FromAddress("identity-1"), activeProviderID))
+	assert.Error(tc.T(), tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{}))
 	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", fatalVpnError}, tc.connManager.Status())
 
 	assert.False(tc.T(), tc.fakeStatsKeeper.SessionStartMarked)
 }
 
 func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateAndSessionId() {
-	err := tc.connManager.Connect(identity.FromAddress("identity-1"), activeProviderID)
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
 
 	assert.NoError(tc.T(), err)
 	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.Status())
 }
 
+func (tc *testContext) TestWhenManagerMadeConnectionVpnClientIsCreatedForServiceTypeOfProposal() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), "openvpn", tc.vpnServiceType)
+}
+
 func (tc *testContext) TestWhenManagerMadeConnectionSessionStartIsMarked() {
-	err := tc.connManager.Connect(identity.FromAddress("identity-1"), "vpn-node-1")
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "vpn-node-1", ProposalFilter{})
 
 	assert.NoError(tc.T(), err)
 
 	assert.True(tc.T(), tc.fakeStatsKeeper.SessionStartMarked)
 }
 
+func (tc *testContext) TestActiveProposalIsKnownWhileConnected() {
+	_, found := tc.connManager.ActiveProposal()
+	assert.False(tc.T(), found)
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	signedProposal, err := service_discovery.SignProposal(activeProposal, &identity.SignerFake{})
+	assert.NoError(tc.T(), err)
+	proposal, found := tc.connManager.ActiveProposal()
+	assert.True(tc.T(), found)
+	assert.Equal(tc.T(), signedProposal, proposal)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	_, found = tc.connManager.ActiveProposal()
+	assert.False(tc.T(), found)
+}
+
+func (tc *testContext) TestWhenManagerMadeConnectionSessionIsPaidUntilDisconnect() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), session.SessionID("vpn-session-id"), tc.paidSession)
+	assert.True(tc.T(), tc.promiseIssuer.Started())
+	assert.False(tc.T(), tc.promiseIssuer.Stopped())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.True(tc.T(), tc.promiseIssuer.Stopped())
+}
+
+func (tc *testContext) TestConnectionIsMadeWhenSessionCanNotBePaid() {
+	tc.promiseIssuer.StartError = errors.New("unsupported payment method")
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.Status())
+}
+
 func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
 	tc.fakeOpenVpn.delayableAction()
 	go func() {
-		tc.connManager.Connect(identity.FromAddress("identity-1"), activeProviderID)
+		tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
 	}()
 	tc.fakeOpenVpn.waitForDelayState()
 	assert.Equal(tc.T(), ConnectionStatus{Connecting, "", nil}, tc.connManager.Status())
 }
 
 func (tc *testContext) TestStatusReportsDisconnectingThenNotConnected() {
-	err := tc.connManager.Connect(identity.FromAddress("identity-1"), activeProviderID)
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
 
 	assert.NoError(tc.T(), err)
 	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.Status())
@@ -123,15 +199,508 @@ func (tc *testContext) TestStatusReportsDisconnectingThenNotConnected() {
 	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", nil}, tc.connManager.Status())
 }
 
+func (tc *testContext) TestStatusReportsReconnectingWhileOpenvpnReconnects() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	tc.fakeOpenVpn.reportState(service.ConnectionReconnecting)
+	assert.Equal(tc.T(), ConnectionStatus{Reconnecting, "vpn-session-id", nil}, tc.connManager.Status())
+
+	tc.fakeOpenVpn.reportState(service.ConnectionConnected)
+	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestConnectionIsRestoredWhenOpenvpnClientExits() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	tc.fakeOpenVpn.waitForStart()
+
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+	tc.fakeOpenVpn.waitForStart()
+
+	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.waitForStatusChange(Reconnecting))
+	assert.Equal(tc.T(), 2, tc.fakeStatsKeeper.SessionStartMarkCount)
+	assert.True(tc.T(), tc.promiseIssuer.Stopped())
+}
+
+func (tc *testContext) TestReconnectDestroysReplacedSession() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	tc.fakeOpenVpn.waitForStart()
+	assert.Equal(tc.T(), session.SessionID(""), tc.fakeDialog.destroyedSession)
+
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+	tc.fakeOpenVpn.waitForStart()
+
+	assert.Equal(tc.T(), Connected, tc.connManager.waitForStatusChange(Reconnecting).State)
+	assert.Equal(tc.T(), session.SessionID("vpn-session-id"), tc.fakeDialog.destroyedSession)
+}
+
+func (tc *testContext) TestConnectionIsRestoredWhenOpenvpnClientIsExiting() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	tc.fakeOpenVpn.waitForStart()
+
+	tc.fakeOpenVpn.reportState(service.ConnectionExiting)
+	tc.fakeOpenVpn.waitForStart()
+
+	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.waitForStatusChange(Reconnecting))
+}
+
+func (tc *testContext) TestConnectionIsLostWhenReconnectAttemptsAreExhausted() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	fatalVpnError := errors.New("fatal connection error")
+	tc.fakeOpenVpn.onConnectReturnError = fatalVpnError
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+
+	expectedError := errors.New("failed to reconnect after 2 attempts")
+	assert.Equal(tc.T(), expectedError, tc.connManager.Wait())
+	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", expectedError}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestDisconnectStopsConnectionSupervision() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.NoError(tc.T(), tc.connManager.Wait())
+	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", nil}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestDisconnectDestroysSessionBeforeClosingDialog() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.Equal(tc.T(), session.SessionID("vpn-session-id"), tc.fakeDialog.destroyedSession)
+	assert.True(tc.T(), tc.fakeDialog.closed)
+}
+
+func (tc *testContext) TestConnectIsRejectedWhileConnectionIsInProgress() {
+	tc.fakeOpenVpn.delayableAction()
+	go func() {
+		tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	}()
+	tc.fakeOpenVpn.waitForDelayState()
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.Equal(tc.T(), ErrAlreadyConnecting, err)
+	assert.Equal(tc.T(), ConnectionStatus{Connecting, "", nil}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestConnectIsRejectedWhenAlreadyConnected() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	err = tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.Equal(tc.T(), ErrAlreadyConnected, err)
+	assert.Equal(tc.T(), ConnectionStatus{Connected, "vpn-session-id", nil}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestDisconnectIsRejectedWhenThereIsNoConnection() {
+	assert.Equal(tc.T(), ErrNoConnection, tc.connManager.Disconnect())
+	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", nil}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestDisconnectCancelsConnectionInProgress() {
+	tc.fakeOpenVpn.delayableAction()
+	connectError := make(chan error)
+	go func() {
+		connectError <- tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	}()
+	tc.fakeOpenVpn.waitForDelayState()
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.Equal(tc.T(), ConnectionStatus{Disconnecting, "", nil}, tc.connManager.Status())
+
+	// resume start, then stop of the client which was started meanwhile
+	tc.fakeOpenVpn.resumeAction()
+	tc.fakeOpenVpn.waitForDelayState()
+	tc.fakeOpenVpn.resumeAction()
+
+	assert.Equal(tc.T(), ErrConnectionCancelled, <-connectError)
+	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", nil}, tc.connManager.Status())
+	assert.False(tc.T(), tc.fakeStatsKeeper.SessionStartMarked)
+}
+
+func (tc *testContext) TestDisconnectCancelsConnectWaitingForSession() {
+	tc.fakeDialog.blockRequests = true
+	connectError := make(chan error)
+	go func() {
+		connectError <- tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	}()
+	<-tc.fakeDialog.requestStarted
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+
+	assert.Equal(tc.T(), ErrConnectionCancelled, <-connectError)
+	assert.True(tc.T(), tc.fakeDialog.closed)
+	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", nil}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestConnectFailsWhenContextIsCancelled() {
+	tc.fakeDialog.blockRequests = true
+	ctx, cancel := context.WithCancel(context.Background())
+	connectError := make(chan error)
+	go func() {
+		connectError <- tc.connManager.Connect(ctx, identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	}()
+	<-tc.fakeDialog.requestStarted
+
+	cancel()
+
+	err := <-connectError
+	assert.Error(tc.T(), err)
+	assert.True(tc.T(), tc.fakeDialog.closed)
+	assert.Equal(tc.T(), NotConnected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) TestStatusChangesAndOpenvpnStatesArePublished() {
+	events := tc.eventBus.Subscribe()
+	defer tc.eventBus.Unsubscribe(events)
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	tc.fakeOpenVpn.reportState(service.ConnectionConnecting)
+
+	assert.Equal(tc.T(), Event{Type: StatusEvent, Status: ConnectionStatus{Connecting, "", nil}}, <-events)
+	assert.Equal(tc.T(), Event{Type: StatusEvent, Status: ConnectionStatus{Connected, "vpn-session-id", nil}}, <-events)
+	assert.Equal(tc.T(), Event{Type: StateEvent, State: service.ConnectionConnecting}, <-events)
+}
+
+func (tc *testContext) TestConnectFallsThroughToNextContactWhenDialogFails() {
+	unreachableContact := dto_discovery.Contact{Type: "unreachable"}
+	reachableContact := dto_discovery.Contact{Type: "reachable"}
+	tc.fakeDiscoveryClient.RegisterProposal(dto_discovery.ServiceProposal{
+		ID:               2,
+		ProviderID:       "vpn-node-2",
+		ProviderContacts: []dto_discovery.Contact{unreachableContact, reachableContact},
+	}, &identity.SignerFake{})
+	tc.fakeDialog.failingContacts = []string{"unreachable"}
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "vpn-node-2", ProposalFilter{})
+
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), reachableContact, tc.fakeDialog.usedContact)
+}
+
+func (tc *testContext) TestConnectFallsThroughToNextProposalWhenSessionIsNotCreated() {
+	for _, proposalID := range []int{2, 3} {
+		tc.fakeDiscoveryClient.RegisterProposal(dto_discovery.ServiceProposal{
+			ID:               proposalID,
+			ProviderID:       "vpn-node-2",
+			ProviderContacts: []dto_discovery.Contact{activeProviderContact},
+		}, &identity.SignerFake{})
+	}
+	tc.fakeDialog.failingProposals = []int{2}
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "vpn-node-2", ProposalFilter{})
+
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), 3, tc.fakeDialog.requestedProposal)
+}
+
+func (tc *testContext) TestConnectDoesNotFallThroughWhenProviderLimitsSessions() {
+	for _, proposalID := range []int{2, 3} {
+		tc.fakeDiscoveryClient.RegisterProposal(dto_discovery.ServiceProposal{
+			ID:               proposalID,
+			ProviderID:       "vpn-node-2",
+			ProviderContacts: []dto_discovery.Contact{activeProviderContact},
+		}, &identity.SignerFake{})
+	}
+	tc.fakeDialog.rejectCode = session.ErrorTooManySessions
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "vpn-node-2", ProposalFilter{})
+
+	assert.Equal(tc.T(), &session.CreateError{Code: session.ErrorTooManySessions, Message: "rejected"}, err)
+	assert.Equal(tc.T(), 2, tc.fakeDialog.requestedProposal)
+}
+
+func (tc *testContext) TestConnectUsesProposalMatchingFilter() {
+	for _, proposalID := range []int{2, 3} {
+		tc.fakeDiscoveryClient.RegisterProposal(dto_discovery.ServiceProposal{
+			ID:               proposalID,
+			ProviderID:       "vpn-node-2",
+			ProviderContacts: []dto_discovery.Contact{activeProviderContact},
+		}, &identity.SignerFake{})
+	}
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "vpn-node-2", ProposalFilter{ProposalID: 3})
+
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), 3, tc.fakeDialog.requestedProposal)
+}
+
+func (tc *testContext) TestConnectRejectsProposalsNotSignedByProvider() {
+	tc.fakeDiscoveryClient.RegisterProposal(dto_discovery.ServiceProposal{
+		ID:               2,
+		ProviderID:       "vpn-node-2",
+		ProviderContacts: []dto_discovery.Contact{activeProviderContact},
+	}, nil)
+	changedProposal, err := service_discovery.SignProposal(dto_discovery.ServiceProposal{
+		ID:               3,
+		ProviderID:       "vpn-node-2",
+		ProviderContacts: []dto_discovery.Contact{activeProviderContact},
+	}, &identity.SignerFake{})
+	assert.NoError(tc.T(), err)
+	changedProposal.ServiceType = "socks5"
+	tc.fakeDiscoveryClient.RegisterProposal(changedProposal, nil)
+
+	err = tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), "vpn-node-2", ProposalFilter{})
+
+	assert.EqualError(tc.T(), err, "node has no service proposals signed by provider")
+	assert.Equal(tc.T(), NotConnected, tc.connManager.Status().State)
+}
+
+func (tc *testContext) TestConnectFailsWhenNoProposalMatchesFilter() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{ServiceType: "socks5"})
+
+	expectedError := errors.New("node has no service proposals matching the request")
+	assert.Equal(tc.T(), expectedError, err)
+	assert.Equal(tc.T(), ConnectionStatus{NotConnected, "", expectedError}, tc.connManager.Status())
+}
+
+func (tc *testContext) TestConnectRejectsPriceCeilingWithoutPaymentMethodType() {
+	filter := ProposalFilter{PriceCeiling: money.NewMoney(0.2, money.CURRENCY_MYST)}
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, filter)
+
+	assert.EqualError(tc.T(), err, "price ceiling requires payment method type, prices of different payment methods are not comparable")
+	assert.Equal(tc.T(), statusNotConnected(), tc.connManager.Status())
+	assert.Nil(tc.T(), tc.fakeOpenVpn.stateCallback)
+}
+
+func (tc *testContext) TestKillSwitchBlocksTrafficWhileConnected() {
+	killSwitch := firewall.NewServiceFake()
+	tc.connManager.killSwitch = killSwitch
+	tc.fakeOpenVpn.killSwitchRules = []firewall.RuleAllowed{
+		{Interface: "tun+"},
+		{RemoteAddress: "vpn-server-ip"},
+	}
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	assert.True(tc.T(), killSwitch.Started)
+	assert.Equal(
+		tc.T(),
+		[]firewall.RuleAllowed{
+			{Interface: "tun+"},
+			{RemoteAddress: "vpn-server-ip"},
+		},
+		killSwitch.Rules,
+	)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.False(tc.T(), killSwitch.Started)
+}
+
+func (tc *testContext) TestKillSwitchStaysEnabledDuringReconnect() {
+	killSwitch := firewall.NewServiceFake()
+	tc.connManager.killSwitch = killSwitch
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	tc.fakeOpenVpn.waitForStart()
+
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+	tc.fakeOpenVpn.waitForStart()
+
+	assert.Equal(tc.T(), Connected, tc.connManager.waitForStatusChange(Reconnecting).State)
+	assert.True(tc.T(), killSwitch.Started)
+}
+
+func (tc *testContext) TestKillSwitchStaysEnabledAfterConnectionIsLostUntilDisconnect() {
+	killSwitch := firewall.NewServiceFake()
+	tc.connManager.killSwitch = killSwitch
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	tc.fakeOpenVpn.onConnectReturnError = errors.New("fatal connection error")
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+	assert.Error(tc.T(), tc.connManager.Wait())
+	assert.True(tc.T(), killSwitch.Started)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.False(tc.T(), killSwitch.Started)
+}
+
+func (tc *testContext) TestKillSwitchIsDisabledWhenConnectFails() {
+	killSwitch := firewall.NewServiceFake()
+	tc.connManager.killSwitch = killSwitch
+	tc.fakeOpenVpn.onConnectReturnError = errors.New("fatal connection error")
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+
+	assert.Error(tc.T(), err)
+	assert.False(tc.T(), killSwitch.Started)
+}
+
+func (tc *testContext) TestHistoryRecordsDisconnectedConnection() {
+	tc.fakeStatsKeeper.Stats = bytescount.SessionStats{BytesSent: 10, BytesReceived: 20}
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	assert.Len(tc.T(), tc.historyStore.Records, 1)
+	assert.Equal(tc.T(), session.SessionID("vpn-session-id"), tc.historyStore.Records[0].SessionID)
+	assert.True(tc.T(), tc.historyStore.Records[0].Ended.IsZero())
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+
+	record := tc.historyStore.Records[0]
+	assert.Equal(tc.T(), 1, record.ID)
+	assert.Equal(tc.T(), activeProviderID, record.ProviderID)
+	assert.Equal(tc.T(), activeProposal.ID, record.ProposalID)
+	assert.False(tc.T(), record.Ended.Before(record.Started))
+	assert.Equal(tc.T(), bytescount.SessionStats{BytesSent: 10, BytesReceived: 20}, record.Stats)
+	assert.Equal(tc.T(), ReasonDisconnected, record.DisconnectReason)
+	assert.Equal(tc.T(), "", record.Error)
+}
+
+func (tc *testContext) TestHistorySumsStatisticsOfSessionsRenewedByReconnect() {
+	tc.fakeStatsKeeper.Stats = bytescount.SessionStats{BytesSent: 10, BytesReceived: 20}
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	tc.fakeOpenVpn.waitForStart()
+
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+	tc.fakeOpenVpn.waitForStart()
+	assert.Equal(tc.T(), Connected, tc.connManager.waitForStatusChange(Reconnecting).State)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.Equal(tc.T(), bytescount.SessionStats{BytesSent: 20, BytesReceived: 40}, tc.historyStore.Records[0].Stats)
+}
+
+func (tc *testContext) TestSessionStatisticsAreResetOnDisconnect() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+	assert.False(tc.T(), tc.fakeStatsKeeper.SessionEndMarked)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.True(tc.T(), tc.fakeStatsKeeper.SessionEndMarked)
+}
+
+func (tc *testContext) TestHistoryRecordsFailedConnectAttempt() {
+	tc.fakeOpenVpn.onConnectReturnError = errors.New("fatal connection error")
+
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.Error(tc.T(), err)
+
+	assert.Len(tc.T(), tc.historyStore.Records, 1)
+	record := tc.historyStore.Records[0]
+	assert.Equal(tc.T(), session.SessionID(""), record.SessionID)
+	assert.False(tc.T(), record.Ended.IsZero())
+	assert.Equal(tc.T(), ReasonConnectFailed, record.DisconnectReason)
+	assert.Equal(tc.T(), "fatal connection error", record.Error)
+}
+
+func (tc *testContext) TestHistoryRecordsCancelledConnectAttempt() {
+	tc.fakeDialog.blockRequests = true
+
+	connectErr := make(chan error)
+	go func() {
+		connectErr <- tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	}()
+	<-tc.fakeDialog.requestStarted
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	assert.Equal(tc.T(), ErrConnectionCancelled, <-connectErr)
+
+	assert.Len(tc.T(), tc.historyStore.Records, 1)
+	assert.Equal(tc.T(), ReasonCancelled, tc.historyStore.Records[0].DisconnectReason)
+}
+
+func (tc *testContext) TestHistoryRecordsLostConnection() {
+	err := tc.connManager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+	assert.NoError(tc.T(), err)
+
+	tc.fakeOpenVpn.onConnectReturnError = errors.New("fatal connection error")
+	tc.fakeOpenVpn.exit(errors.New("process died"))
+	assert.Error(tc.T(), tc.connManager.Wait())
+
+	assert.Len(tc.T(), tc.historyStore.Records, 1)
+	record := tc.historyStore.Records[0]
+	assert.Equal(tc.T(), ReasonConnectionLost, record.DisconnectReason)
+	assert.Equal(tc.T(), "failed to reconnect after 2 attempts", record.Error)
+}
+
+func TestContactAddressesReturnsBrokerHosts(t *testing.T) {
+	contact := dto_discovery.Contact{
+		Type: nats_discovery.TypeContactNATSV1,
+		Definition: nats_discovery.ContactNATSV1{
+			Topic:           "provider-topic",
+			BrokerAddresses: []string{"nats://broker-host:4222", "nats://1.2.3.4:4222"},
+		},
+	}
+
+	assert.Equal(t, []string{"broker-host", "1.2.3.4"}, contactAddresses(contact))
+	assert.Empty(t, contactAddresses(dto_discovery.Contact{}))
+}
+
+func TestConcurrentConnectAndDisconnectKeepStateConsistent(t *testing.T) {
+	discoveryClient := server.NewClientFake()
+	discoveryClient.RegisterProposal(activeProposal, &identity.SignerFake{})
+	dialogEstablisherFactory := func(identity identity.Identity) communication.DialogEstablisher {
+		return &fakeDialog{}
+	}
+	vpnClientFactory := func(serviceType string, vpnSession session.SessionDto, identity identity.Identity, stateCallback service.ConnectionStateCallback) (service.Connection, error) {
+		return &fakeOpenvpnClient{startNotifier: make(chan int, 1)}, nil
+	}
+	promiseIssuerFactory := func(myID identity.Identity, providerID identity.Identity, sessionID session.SessionID, paymentMethod dto_discovery.PaymentMethod, sender communication.Sender) client_promise.Issuer {
+		return &client_promise.IssuerFake{}
+	}
+	manager := NewManager(discoveryClient, dialogEstablisherFactory, vpnClientFactory, promiseIssuerFactory, &fakeSessionStatsKeeper{}, ReconnectPolicy{}, NewEventBus(), SelectCheapestProposals, &providerExtractorFake{}, nil, NewHistoryStoreFake())
+
+	actionsDone := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		actionsDone.Add(2)
+		go func() {
+			defer actionsDone.Done()
+			manager.Connect(context.Background(), identity.FromAddress("identity-1"), activeProviderID, ProposalFilter{})
+		}()
+		go func() {
+			defer actionsDone.Done()
+			manager.Disconnect()
+		}()
+	}
+	actionsDone.Wait()
+
+	status := manager.Status()
+	assert.Contains(t, []State{NotConnected, Connected, Disconnecting}, status.State)
+	if status.State == Connected {
+		assert.NoError(t, manager.Disconnect())
+	}
+	assert.Equal(t, NotConnected, manager.waitForStatusChange(Disconnecting).State)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
 
+func (manager *connectionManager) waitForStatusChange(fromState State) ConnectionStatus {
+	for i := 0; i < 100; i++ {
+		if status := manager.Status(); status.State != fromState {
+			return status
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return manager.Status()
+}
+
 type fakeOpenvpnClient struct {
 	delayAction               bool
 	delayStateEnteredNotifier chan int
 	resumeFromDelay           chan int
+	startNotifier             chan int
 	onConnectReturnError      error
+	stateCallback             service.ConnectionStateCallback
+	killSwitchRules           []firewall.RuleAllowed
+
+	exitLock  sync.Mutex
+	exitError error
+	exited    chan bool
 }
 
 func (foc *fakeOpenvpnClient) Start() error {
@@ -139,11 +708,29 @@ func (foc *fakeOpenvpnClient) Start() error {
 		foc.delayStateEnteredNotifier <- 1
 		<-foc.resumeFromDelay
 	}
-	return foc.onConnectReturnError
+	if foc.onConnectReturnError != nil {
+		return foc.onConnectReturnError
+	}
+
+	foc.exitLock.Lock()
+	foc.exitError = nil
+	foc.exited = make(chan bool)
+	foc.exitLock.Unlock()
+
+	foc.startNotifier <- 1
+	return nil
 }
 
 func (foc *fakeOpenvpnClient) Wait() error {
-	return nil
+	foc.exitLock.Lock()
+	exited := foc.exited
+	foc.exitLock.Unlock()
+
+	<-exited
+
+	foc.exitLock.Lock()
+	defer foc.exitLock.Unlock()
+	return foc.exitError
 }
 
 func (foc *fakeOpenvpnClient) Stop() error {
@@ -151,9 +738,34 @@ func (foc *fakeOpenvpnClient) Stop() error {
 		foc.delayStateEnteredNotifier <- 1
 		<-foc.resumeFromDelay
 	}
+	foc.exit(nil)
 	return nil
 }
 
+func (foc *fakeOpenvpnClient) KillSwitchRules() []firewall.RuleAllowed {
+	return foc.killSwitchRules
+}
+
+func (foc *fakeOpenvpnClient) exit(err error) {
+	foc.exitLock.Lock()
+	defer foc.exitLock.Unlock()
+
+	select {
+	case <-foc.exited:
+	default:
+		foc.exitError = err
+		close(foc.exited)
+	}
+}
+
+func (foc *fakeOpenvpnClient) reportState(state service.ConnectionState) {
+	foc.stateCallback(state)
+}
+
+func (foc *fakeOpenvpnClient) waitForStart() {
+	<-foc.startNotifier
+}
+
 func (foc *fakeOpenvpnClient) delayableAction() {
 	foc.delayAction = true
 }
@@ -167,11 +779,27 @@ func (foc *fakeOpenvpnClient) resumeAction() {
 }
 
 type fakeDialog struct {
-	peerId identity.Identity
+	peerId            identity.Identity
+	blockRequests     bool
+	requestStarted    chan int
+	closed            bool
+	failingContacts   []string
+	failingProposals  []int
+	rejectCode        session.ErrorCode
+	usedContact       dto_discovery.Contact
+	requestedProposal int
+	destroyedSession  session.SessionID
 }
 
-func (fd *fakeDialog) CreateDialog(peerID identity.Identity, peerContact dto_discovery.Contact) (communication.Dialog, error) {
+func (fd *fakeDialog) CreateDialog(ctx context.Context, peerID identity.Identity, peerContact dto_discovery.Contact) (communication.Dialog, error) {
+	for _, contactType := range fd.failingContacts {
+		if contactType == peerContact.Type {
+			return nil, errors.New("contact unreachable")
+		}
+	}
+
 	fd.peerId = peerID
+	fd.usedContact = peerContact
 	return fd, nil
 }
 
@@ -180,6 +808,7 @@ func (fd *fakeDialog) PeerID() identity.Identity {
 }
 
 func (fd *fakeDialog) Close() error {
+	fd.closed = true
 	return nil
 }
 
@@ -194,33 +823,81 @@ func (fd *fakeDialog) Send(producer communication.MessageProducer) error {
 	return nil
 }
 
-func (fd *fakeDialog) Request(producer communication.RequestProducer) (responsePtr interface{}, err error) {
+func (fd *fakeDialog) Request(ctx context.Context, producer communication.RequestProducer) (responsePtr interface{}, err error) {
+	if fd.blockRequests {
+		fd.requestStarted <- 1
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	if destroyProducer, ok := producer.(*session.SessionDestroyProducer); ok {
+		fd.destroyedSession = destroyProducer.SessionID
+		return &session.SessionDestroyResponse{Success: true}, nil
+	}
+
+	fd.requestedProposal = producer.(*session.SessionCreateProducer).ProposalId
+	if fd.rejectCode != "" {
+		return &session.SessionCreateResponse{Success: false, ErrorCode: fd.rejectCode, Message: "rejected"}, nil
+	}
+	for _, proposalID := range fd.failingProposals {
+		if proposalID == fd.requestedProposal {
+			return nil, errors.New("proposal rejected")
+		}
+	}
+
 	return &session.SessionCreateResponse{
 			Success: true,
 			Message: "Everything is great!",
 			Session: session.SessionDto{
 				ID:     "vpn-session-id",
-				Config: "vpn-session-config",
+				Config: "remote vpn-server-ip",
 			},
 		},
 		nil
 }
 
 type fakeSessionStatsKeeper struct {
-	SessionStartMarked bool
+	SessionStartMarked    bool
+	SessionStartMarkCount int
+	SessionEndMarked      bool
+	Stats                 bytescount.SessionStats
 }
 
-func (fsk *fakeSessionStatsKeeper) Save(stats bytescount.SessionStats) {
+func (fsk *fakeSessionStatsKeeper) Save(sessionID session.SessionID, stats bytescount.SessionStats) {
 }
 
 func (fsk *fakeSessionStatsKeeper) Retrieve() bytescount.SessionStats {
-	return bytescount.SessionStats{}
+	return fsk.Stats
+}
+
+func (fsk *fakeSessionStatsKeeper) RetrieveRates() bytescount.SessionRates {
+	return bytescount.SessionRates{}
 }
 
-func (fsk *fakeSessionStatsKeeper) MarkSessionStart() {
+func (fsk *fakeSessionStatsKeeper) MarkSessionEnd() {
+	fsk.SessionEndMarked = true
+}
+
+func (fsk *fakeSessionStatsKeeper) MarkSessionStart(sessionID session.SessionID) {
 	fsk.SessionStartMarked = true
+	fsk.SessionStartMarkCount++
 }
 
 func (fsk *fakeSessionStatsKeeper) GetSessionDuration() time.Duration {
 	return time.Duration(0)
 }
+
+// providerExtractorFake recovers provider of proposals signed by identity.SignerFake
+type providerExtractorFake struct{}
+
+func (extractor *providerExtractorFake) Extract(message []byte, signature identity.Signature) (identity.Identity, error) {
+	if !bytes.Equal(append([]byte("signed"), message...), signature.Bytes()) {
+		return identity.Identity{}, errors.New("signature does not match message")
+	}
+
+	var proposal struct {
+		ProviderID string `json:"provider_id"`
+	}
+	err := json.Unmarshal(message, &proposal)
+	return identity.FromAddress(proposal.ProviderID), err
+}
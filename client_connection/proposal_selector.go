@@ -0,0 +1,110 @@
+package client_connection
+
+import (
+	"errors"
+	"github.com/mysterium/node/money"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"sort"
+)
+
+// ProposalFilter describes which proposals are acceptable for connection, zero valued fields match any proposal
+type ProposalFilter struct {
+	ProposalID        int
+	ServiceType       string
+	PaymentMethodType string
+	// Proposals which price is higher are skipped, price currency has to match as well. Prices of different
+	// payment methods are not comparable, so ceiling is applied only together with PaymentMethodType
+	PriceCeiling money.Money
+	// Country of service location
+	Country string
+}
+
+// ProposalSelector returns proposals matching the filter, in order connecting to them should be attempted
+type ProposalSelector func(proposals []dto_discovery.ServiceProposal, filter ProposalFilter) []dto_discovery.ServiceProposal
+
+// priceKind groups proposals which prices are comparable: the ones of the same payment method and currency
+type priceKind struct {
+	paymentMethodType string
+	currency          money.Currency
+}
+
+// SelectCheapestProposals is ProposalSelector which prefers cheaper proposals. Only prices of the same payment method
+// and currency are compared, proposals of different ones are kept in order of their first appearance
+func SelectCheapestProposals(proposals []dto_discovery.ServiceProposal, filter ProposalFilter) []dto_discovery.ServiceProposal {
+	selected := make([]dto_discovery.ServiceProposal, 0, len(proposals))
+	kindOrder := make(map[priceKind]int)
+	for _, proposal := range proposals {
+		if !filter.Matches(proposal) {
+			continue
+		}
+		selected = append(selected, proposal)
+		if _, found := kindOrder[proposalPriceKind(proposal)]; !found {
+			kindOrder[proposalPriceKind(proposal)] = len(kindOrder)
+		}
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		kindI, kindJ := kindOrder[proposalPriceKind(selected[i])], kindOrder[proposalPriceKind(selected[j])]
+		if kindI != kindJ {
+			return kindI < kindJ
+		}
+		return proposalPrice(selected[i]).Amount < proposalPrice(selected[j]).Amount
+	})
+	return selected
+}
+
+// Validate rejects filter which criteria can not be applied
+func (filter ProposalFilter) Validate() error {
+	if filter.PriceCeiling.Amount != 0 && filter.PaymentMethodType == "" {
+		return errors.New("price ceiling requires payment method type, prices of different payment methods are not comparable")
+	}
+	return nil
+}
+
+// Matches checks if proposal satisfies all criteria of the filter, invalid filter matches no proposal
+func (filter ProposalFilter) Matches(proposal dto_discovery.ServiceProposal) bool {
+	if filter.ProposalID != 0 && filter.ProposalID != proposal.ID {
+		return false
+	}
+	if filter.ServiceType != "" && filter.ServiceType != proposal.ServiceType {
+		return false
+	}
+	if filter.PaymentMethodType != "" && filter.PaymentMethodType != proposal.PaymentMethodType {
+		return false
+	}
+	if filter.PriceCeiling.Amount != 0 {
+		if filter.PaymentMethodType == "" {
+			return false
+		}
+		price := proposalPrice(proposal)
+		if price.Currency != filter.PriceCeiling.Currency || price.Amount > filter.PriceCeiling.Amount {
+			return false
+		}
+	}
+	if filter.Country != "" && filter.Country != proposalLocation(proposal).Country {
+		return false
+	}
+
+	return true
+}
+
+func proposalPrice(proposal dto_discovery.ServiceProposal) money.Money {
+	if proposal.PaymentMethod == nil {
+		return money.Money{}
+	}
+	return proposal.PaymentMethod.GetPrice()
+}
+
+func proposalPriceKind(proposal dto_discovery.ServiceProposal) priceKind {
+	return priceKind{
+		paymentMethodType: proposal.PaymentMethodType,
+		currency:          proposalPrice(proposal).Currency,
+	}
+}
+
+func proposalLocation(proposal dto_discovery.ServiceProposal) dto_discovery.Location {
+	if proposal.ServiceDefinition == nil {
+		return dto_discovery.Location{}
+	}
+	return proposal.ServiceDefinition.GetLocation()
+}
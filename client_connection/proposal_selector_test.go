@@ -0,0 +1,118 @@
+package client_connection
+
+import (
+	"github.com/mysterium/node/money"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type fakePaymentMethod struct {
+	price money.Money
+}
+
+func (method fakePaymentMethod) GetPrice() money.Money {
+	return method.price
+}
+
+type fakeServiceDefinition struct {
+	location dto_discovery.Location
+}
+
+func (definition fakeServiceDefinition) GetLocation() dto_discovery.Location {
+	return definition.location
+}
+
+func newProposal(id int, serviceType string, price float64, country string) dto_discovery.ServiceProposal {
+	return dto_discovery.ServiceProposal{
+		ID:                id,
+		ServiceType:       serviceType,
+		ServiceDefinition: fakeServiceDefinition{dto_discovery.Location{Country: country}},
+		PaymentMethodType: "PER_TIME",
+		PaymentMethod:     fakePaymentMethod{money.NewMoney(price, money.CURRENCY_MYST)},
+	}
+}
+
+func TestSelectCheapestProposalsOrdersByPrice(t *testing.T) {
+	proposals := []dto_discovery.ServiceProposal{
+		newProposal(1, "openvpn", 0.3, "LT"),
+		newProposal(2, "openvpn", 0.1, "LT"),
+		newProposal(3, "openvpn", 0.2, "LT"),
+	}
+
+	selected := SelectCheapestProposals(proposals, ProposalFilter{})
+
+	assert.Equal(t, []dto_discovery.ServiceProposal{proposals[1], proposals[2], proposals[0]}, selected)
+}
+
+func TestSelectCheapestProposalsComparesOnlyPricesOfSamePaymentMethodAndCurrency(t *testing.T) {
+	perBytes := newProposal(1, "openvpn", 0.05, "LT")
+	perBytes.PaymentMethodType = "PER_BYTES"
+	otherCurrency := newProposal(2, "openvpn", 0.01, "LT")
+	otherCurrency.PaymentMethod = fakePaymentMethod{money.NewMoney(0.01, money.Currency("ETH"))}
+	proposals := []dto_discovery.ServiceProposal{
+		newProposal(3, "openvpn", 0.3, "LT"),
+		perBytes,
+		otherCurrency,
+		newProposal(4, "openvpn", 0.1, "LT"),
+		newProposal(5, "openvpn", 0.02, "LT"),
+	}
+	proposals[4].PaymentMethodType = "PER_BYTES"
+
+	selected := SelectCheapestProposals(proposals, ProposalFilter{})
+
+	assert.Equal(
+		t,
+		[]dto_discovery.ServiceProposal{proposals[3], proposals[0], proposals[4], proposals[1], proposals[2]},
+		selected,
+	)
+}
+
+func TestSelectCheapestProposalsSkipsNotMatchingProposals(t *testing.T) {
+	proposals := []dto_discovery.ServiceProposal{
+		newProposal(1, "openvpn", 0.3, "LT"),
+		newProposal(2, "socks5", 0.1, "LT"),
+		newProposal(3, "openvpn", 0.2, "US"),
+	}
+
+	selected := SelectCheapestProposals(proposals, ProposalFilter{ServiceType: "openvpn", Country: "LT"})
+
+	assert.Equal(t, []dto_discovery.ServiceProposal{proposals[0]}, selected)
+}
+
+func TestProposalFilterMatches(t *testing.T) {
+	proposal := newProposal(1, "openvpn", 0.2, "LT")
+
+	var tests = []struct {
+		filter   ProposalFilter
+		expected bool
+	}{
+		{ProposalFilter{}, true},
+		{ProposalFilter{ProposalID: 1}, true},
+		{ProposalFilter{ProposalID: 2}, false},
+		{ProposalFilter{ServiceType: "openvpn"}, true},
+		{ProposalFilter{ServiceType: "socks5"}, false},
+		{ProposalFilter{PaymentMethodType: "PER_TIME"}, true},
+		{ProposalFilter{PaymentMethodType: "PER_BYTES"}, false},
+		{ProposalFilter{PaymentMethodType: "PER_TIME", PriceCeiling: money.NewMoney(0.2, money.CURRENCY_MYST)}, true},
+		{ProposalFilter{PaymentMethodType: "PER_TIME", PriceCeiling: money.NewMoney(0.1, money.CURRENCY_MYST)}, false},
+		{ProposalFilter{PaymentMethodType: "PER_TIME", PriceCeiling: money.NewMoney(1, money.Currency("ETH"))}, false},
+		{ProposalFilter{PriceCeiling: money.NewMoney(0.2, money.CURRENCY_MYST)}, false},
+		{ProposalFilter{Country: "LT"}, true},
+		{ProposalFilter{Country: "US"}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.filter.Matches(proposal), "%+v", test.filter)
+	}
+}
+
+func TestProposalFilterValidate(t *testing.T) {
+	assert.NoError(t, ProposalFilter{}.Validate())
+	assert.NoError(t, ProposalFilter{PaymentMethodType: "PER_TIME", PriceCeiling: money.NewMoney(0.2, money.CURRENCY_MYST)}.Validate())
+	assert.EqualError(
+		t,
+		ProposalFilter{PriceCeiling: money.NewMoney(0.2, money.CURRENCY_MYST)}.Validate(),
+		"price ceiling requires payment method type, prices of different payment methods are not comparable",
+	)
+}
@@ -0,0 +1,43 @@
+package client_connection
+
+import (
+	"time"
+)
+
+// ReconnectPolicy describes how dropped connection is restored
+type ReconnectPolicy struct {
+	// How many times reconnect is attempted before giving up, 0 disables reconnecting
+	MaxAttempts int
+
+	// Delay before the first reconnect attempt
+	InitialDelay time.Duration
+
+	// Upper bound of delay between reconnect attempts
+	MaxDelay time.Duration
+
+	// Factor by which delay grows after each failed attempt
+	Multiplier float64
+}
+
+// DefaultReconnectPolicy returns policy which is used by client by default
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		MaxAttempts:  5,
+		InitialDelay: 1 * time.Second,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+	}
+}
+
+// Delay returns how long to wait before given reconnect attempt (attempts are counted from 1)
+func (policy ReconnectPolicy) Delay(attempt int) time.Duration {
+	delay := float64(policy.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		delay = delay * policy.Multiplier
+		if delay >= float64(policy.MaxDelay) {
+			return policy.MaxDelay
+		}
+	}
+
+	return time.Duration(delay)
+}
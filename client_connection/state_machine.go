@@ -0,0 +1,61 @@
+package client_connection
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrAlreadyConnecting is returned when connect is requested while another connection is being established
+	ErrAlreadyConnecting = errors.New("connection is already in progress")
+	// ErrAlreadyConnected is returned when connect is requested while connection already exists
+	ErrAlreadyConnected = errors.New("connection already exists")
+	// ErrDisconnecting is returned when action is requested while connection is being closed
+	ErrDisconnecting = errors.New("connection is being closed")
+	// ErrNoConnection is returned when disconnect is requested while there is no connection
+	ErrNoConnection = errors.New("no connection exists")
+	// ErrConnectionCancelled is returned by connect when connection was closed before it got established
+	ErrConnectionCancelled = errors.New("connection was cancelled")
+)
+
+// TransitionError is returned when connection is requested to move to a state which is not reachable from current one
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (err *TransitionError) Error() string {
+	return fmt.Sprintf("illegal connection state transition: %s -> %s", err.From, err.To)
+}
+
+// stateTransitions lists states which are reachable from every state
+var stateTransitions = map[State][]State{
+	NotConnected:  {Connecting},
+	Connecting:    {Connected, NotConnected, Disconnecting},
+	Connected:     {Reconnecting, Disconnecting},
+	Reconnecting:  {Connected, NotConnected, Disconnecting},
+	Disconnecting: {NotConnected},
+}
+
+// validateTransition checks if connection is allowed to move from one state to another
+func validateTransition(from, to State) error {
+	for _, allowed := range stateTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+
+	switch {
+	case to == Connecting && from == Connecting:
+		return ErrAlreadyConnecting
+	case to == Connecting && (from == Connected || from == Reconnecting):
+		return ErrAlreadyConnected
+	case to == Connecting && from == Disconnecting:
+		return ErrDisconnecting
+	case to == Disconnecting && from == NotConnected:
+		return ErrNoConnection
+	case to == Disconnecting && from == Disconnecting:
+		return ErrDisconnecting
+	}
+	return &TransitionError{from, to}
+}
@@ -0,0 +1,30 @@
+package client_connection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidateTransitionAllowsConnectionLifecycle(t *testing.T) {
+	assert.NoError(t, validateTransition(NotConnected, Connecting))
+	assert.NoError(t, validateTransition(Connecting, Connected))
+	assert.NoError(t, validateTransition(Connected, Reconnecting))
+	assert.NoError(t, validateTransition(Reconnecting, Connected))
+	assert.NoError(t, validateTransition(Connected, Disconnecting))
+	assert.NoError(t, validateTransition(Disconnecting, NotConnected))
+}
+
+func TestValidateTransitionReturnsTypedErrors(t *testing.T) {
+	assert.Equal(t, ErrAlreadyConnecting, validateTransition(Connecting, Connecting))
+	assert.Equal(t, ErrAlreadyConnected, validateTransition(Connected, Connecting))
+	assert.Equal(t, ErrAlreadyConnected, validateTransition(Reconnecting, Connecting))
+	assert.Equal(t, ErrDisconnecting, validateTransition(Disconnecting, Connecting))
+	assert.Equal(t, ErrDisconnecting, validateTransition(Disconnecting, Disconnecting))
+	assert.Equal(t, ErrNoConnection, validateTransition(NotConnected, Disconnecting))
+	assert.Equal(t, &TransitionError{NotConnected, Connected}, validateTransition(NotConnected, Connected))
+}
+
+func TestTransitionErrorMessage(t *testing.T) {
+	err := &TransitionError{NotConnected, Connected}
+	assert.Equal(t, "illegal connection state transition: NotConnected -> Connected", err.Error())
+}
@@ -0,0 +1,29 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/identity"
+)
+
+// PromiseConsumer passes promises which peer sends for its sessions to tracker
+type PromiseConsumer struct {
+	Tracker Tracker
+	PeerID  identity.Identity
+}
+
+// GetMessageEndpoint returns endpoint of promise messages
+func (consumer *PromiseConsumer) GetMessageEndpoint() communication.MessageEndpoint {
+	return endpointPromise
+}
+
+// NewMessage creates empty promise message to unpack into
+func (consumer *PromiseConsumer) NewMessage() (messagePtr interface{}) {
+	var message PromiseMessage
+	return &message
+}
+
+// Consume accepts promise, invalid promises are rejected with error
+func (consumer *PromiseConsumer) Consume(messagePtr interface{}) error {
+	message := messagePtr.(*PromiseMessage)
+	return consumer.Tracker.Accept(consumer.PeerID, message.SessionID, message.Promise)
+}
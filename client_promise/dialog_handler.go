@@ -0,0 +1,32 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/communication"
+)
+
+// NewDialogHandler wraps handler of dialogs, so that promises are received in every dialog it handles
+func NewDialogHandler(handler communication.DialogHandler, tracker Tracker) *dialogHandler {
+	return &dialogHandler{
+		handler: handler,
+		tracker: tracker,
+	}
+}
+
+type dialogHandler struct {
+	handler communication.DialogHandler
+	tracker Tracker
+}
+
+// Handle lets wrapped handler serve the dialog and starts receiving promises in it
+func (handler *dialogHandler) Handle(dialog communication.Dialog) error {
+	if err := handler.handler.Handle(dialog); err != nil {
+		return err
+	}
+
+	return dialog.Receive(
+		&PromiseConsumer{
+			Tracker: handler.tracker,
+			PeerID:  dialog.PeerID(),
+		},
+	)
+}
@@ -0,0 +1,15 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/session"
+)
+
+const endpointPromise = communication.MessageEndpoint("session-promise")
+
+// PromiseMessage delivers consumer's promise to pay for given session
+type PromiseMessage struct {
+	SessionID session.SessionID `json:"session_id"`
+	Promise   dto.SignedPromise `json:"promise"`
+}
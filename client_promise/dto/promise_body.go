@@ -2,11 +2,15 @@ package dto
 
 import (
 	"github.com/mysterium/node/money"
+	"github.com/mysterium/node/session"
 )
 
+// PromiseBody is consumer's promise to pay benefiter given amount in total for the session,
+// promise with bigger serial number replaces previous ones of the same session
 type PromiseBody struct {
-	SerialNumber int
-	IssuerID     string
-	BenefiterID  string
-	Amount       money.Money
+	SerialNumber int               `json:"serial_number"`
+	SessionID    session.SessionID `json:"session_id"`
+	IssuerID     string            `json:"issuer_id"`
+	BenefiterID  string            `json:"benefiter_id"`
+	Amount       money.Money       `json:"amount"`
 }
@@ -1,3 +1,4 @@
 package dto
 
+// Signature is base64 encoded signature of promise
 type Signature string
@@ -1,6 +1,7 @@
 package dto
 
+// SignedPromise is promise signed by its issuer
 type SignedPromise struct {
-	Promise         PromiseBody
-	IssuerSignature Signature
+	Promise         PromiseBody `json:"promise"`
+	IssuerSignature Signature   `json:"issuer_signature"`
 }
@@ -0,0 +1,145 @@
+package client_promise
+
+import (
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/metering"
+	"github.com/mysterium/node/money"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+	"sync"
+	"time"
+)
+
+const enforcerLogPrefix = "[promise-enforcer] "
+
+// enforceInterval is how often sessions are checked for promises lagging behind
+const enforceInterval = 10 * time.Second
+
+// NewEnforcer creates enforcer which checks that sessions of proposal are paid for, according to given policy
+func NewEnforcer(
+	proposal dto_discovery.ServiceProposal,
+	policy Policy,
+	sessions session.Manager,
+	getStats SessionStatsGetter,
+	tracker Tracker,
+) *enforcer {
+	return &enforcer{
+		proposal:   proposal,
+		policy:     policy,
+		sessions:   sessions,
+		getStats:   getStats,
+		tracker:    tracker,
+		timeGetter: time.Now,
+		lagging:    make(map[session.SessionID]time.Time),
+	}
+}
+
+type enforcer struct {
+	proposal   dto_discovery.ServiceProposal
+	policy     Policy
+	sessions   session.Manager
+	getStats   SessionStatsGetter
+	tracker    Tracker
+	timeGetter func() time.Time
+
+	// lagging holds since when each session is not paid for
+	lagging map[session.SessionID]time.Time
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// Start checks sessions periodically in background
+func (enforcer *enforcer) Start() {
+	enforcer.stop = make(chan struct{})
+	enforcer.stopped.Add(1)
+	go enforcer.checkPeriodically(enforcer.stop)
+}
+
+// Stop finishes checking sessions and waits until check in progress is done
+func (enforcer *enforcer) Stop() {
+	if enforcer.stop == nil {
+		return
+	}
+	close(enforcer.stop)
+	enforcer.stop = nil
+	enforcer.stopped.Wait()
+}
+
+func (enforcer *enforcer) checkPeriodically(stop chan struct{}) {
+	defer enforcer.stopped.Done()
+
+	ticker := time.NewTicker(enforceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			enforcer.check()
+		}
+	}
+}
+
+// check finds sessions which consumed more than promised for longer than grace, and destroys them if policy enforces payments
+func (enforcer *enforcer) check() {
+	paymentMethod := enforcer.proposal.PaymentMethod
+	if _, err := metering.Unit(paymentMethod); err != nil {
+		return
+	}
+
+	traffic := make(map[session.SessionID]uint64)
+	for _, stats := range enforcer.getStats() {
+		traffic[stats.SessionID] = uint64(stats.BytesSent + stats.BytesReceived)
+	}
+
+	now := enforcer.timeGetter()
+	lagging := make(map[session.SessionID]time.Time)
+	for _, providerSession := range enforcer.sessions.List() {
+		usage := metering.Usage{
+			Duration: now.Sub(providerSession.Created),
+			Bytes:    traffic[providerSession.ID],
+		}
+		owed, err := metering.Cost(paymentMethod, usage)
+		if err != nil {
+			log.Error(enforcerLogPrefix, "Failed to compute cost of session ", providerSession.ID, ": ", err)
+			continue
+		}
+		promise, _ := enforcer.tracker.SessionPromise(providerSession.ID)
+		if covers(promise.Amount, owed) {
+			continue
+		}
+
+		since, found := enforcer.lagging[providerSession.ID]
+		if !found {
+			since = now
+		}
+		if now.Sub(since) <= enforcer.policy.Grace {
+			lagging[providerSession.ID] = since
+			continue
+		}
+
+		if !enforcer.policy.Enforce {
+			log.Warn(enforcerLogPrefix, "Session ", providerSession.ID, " has promised ", promise.Amount, " of ", owed)
+			lagging[providerSession.ID] = now
+			continue
+		}
+
+		log.Warn(enforcerLogPrefix, "Destroying session ", providerSession.ID, " which has promised ", promise.Amount, " of ", owed)
+		if err := enforcer.sessions.Destroy(providerSession.ID); err != nil {
+			log.Error(enforcerLogPrefix, "Failed to destroy session ", providerSession.ID, ": ", err)
+			lagging[providerSession.ID] = since
+		}
+	}
+	enforcer.lagging = lagging
+}
+
+// covers tells whether promised amount pays owed one
+func covers(promised, owed money.Money) bool {
+	if owed.Amount == 0 {
+		return true
+	}
+	_, err := promised.Sub(owed)
+	return err == nil
+}
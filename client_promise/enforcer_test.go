@@ -0,0 +1,138 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	dto_openvpn "github.com/mysterium/node/openvpn/discovery/dto"
+	server_dto "github.com/mysterium/node/server/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var sessionCreated = time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+
+type trackerFake struct {
+	promises map[session.SessionID]dto.PromiseBody
+}
+
+func (tracker *trackerFake) Accept(consumerID identity.Identity, sessionID session.SessionID, promise dto.SignedPromise) error {
+	tracker.promises[sessionID] = promise.Promise
+	return nil
+}
+
+func (tracker *trackerFake) SessionPromise(sessionID session.SessionID) (dto.PromiseBody, bool) {
+	promise, found := tracker.promises[sessionID]
+	return promise, found
+}
+
+func (tracker *trackerFake) promise(sessionID session.SessionID, amount float64) {
+	tracker.promises[sessionID] = dto.PromiseBody{Amount: money.NewMoney(amount, money.CURRENCY_MYST)}
+}
+
+func newEnforcer(paymentMethod dto_discovery.PaymentMethod, policy Policy, stats []server_dto.SessionStats) (*enforcer, *session.ManagerFake, *trackerFake, *utils.SettableClock) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+
+	sessions := &session.ManagerFake{
+		Sessions: map[session.SessionID]session.Session{
+			"session-1": {ID: "session-1", ConsumerID: consumerID, Created: sessionCreated},
+		},
+	}
+	tracker := &trackerFake{promises: make(map[session.SessionID]dto.PromiseBody)}
+	getStats := func() []server_dto.SessionStats {
+		return stats
+	}
+
+	enforcer := NewEnforcer(
+		dto_discovery.ServiceProposal{ID: 1, PaymentMethod: paymentMethod},
+		policy,
+		sessions,
+		getStats,
+		tracker,
+	)
+	enforcer.timeGetter = clock.GetTime
+	return enforcer, sessions, tracker, clock
+}
+
+func TestEnforcerDestroysSessionLaggingLongerThanGrace(t *testing.T) {
+	var _ Enforcer = &enforcer{}
+	enforcer, sessions, tracker, clock := newEnforcer(perTime, Policy{Enforce: true, Grace: time.Minute}, nil)
+	tracker.promise("session-1", 0.125)
+
+	clock.SetTime(sessionCreated.Add(time.Hour))
+	enforcer.check()
+	assert.Empty(t, enforcer.lagging)
+
+	clock.SetTime(sessionCreated.Add(time.Hour + time.Second))
+	enforcer.check()
+	clock.SetTime(sessionCreated.Add(time.Hour + time.Minute + time.Second))
+	enforcer.check()
+	assert.Empty(t, sessions.DestroyedSession)
+
+	clock.SetTime(sessionCreated.Add(time.Hour + 2*time.Minute))
+	enforcer.check()
+	assert.Equal(t, session.SessionID("session-1"), sessions.DestroyedSession)
+}
+
+func TestEnforcerKeepsSessionWhichCatchesUp(t *testing.T) {
+	enforcer, sessions, tracker, clock := newEnforcer(perTime, Policy{Enforce: true, Grace: time.Minute}, nil)
+
+	clock.SetTime(sessionCreated.Add(time.Second))
+	enforcer.check()
+	assert.Contains(t, enforcer.lagging, session.SessionID("session-1"))
+
+	tracker.promise("session-1", 0.125)
+	clock.SetTime(sessionCreated.Add(30 * time.Second))
+	enforcer.check()
+	clock.SetTime(sessionCreated.Add(2 * time.Minute))
+	enforcer.check()
+
+	assert.Empty(t, sessions.DestroyedSession)
+	assert.Empty(t, enforcer.lagging)
+}
+
+func TestEnforcerCountsTrafficOfSession(t *testing.T) {
+	stats := []server_dto.SessionStats{
+		{SessionID: "session-1", BytesSent: 1024 * 1024, BytesReceived: 1024 * 1024},
+	}
+	enforcer, sessions, tracker, _ := newEnforcer(perBytes, Policy{Enforce: true}, stats)
+	tracker.promise("session-1", 0.9)
+
+	enforcer.check()
+	assert.Contains(t, enforcer.lagging, session.SessionID("session-1"))
+
+	tracker.promise("session-1", 1)
+	enforcer.check()
+	assert.Empty(t, enforcer.lagging)
+	assert.Empty(t, sessions.DestroyedSession)
+}
+
+func TestEnforcerOnlyReportsLagWhenPaymentsAreNotEnforced(t *testing.T) {
+	enforcer, sessions, _, clock := newEnforcer(perTime, DefaultPolicy(), nil)
+
+	clock.SetTime(sessionCreated.Add(time.Second))
+	enforcer.check()
+	clock.SetTime(sessionCreated.Add(time.Hour))
+	enforcer.check()
+
+	assert.Empty(t, sessions.DestroyedSession)
+	assert.Equal(t, sessionCreated.Add(time.Hour), enforcer.lagging["session-1"])
+}
+
+func TestEnforcerIgnoresFreeProposals(t *testing.T) {
+	enforcer, sessions, _, clock := newEnforcer(nil, Policy{Enforce: true}, nil)
+	enforcer.check()
+	clock.SetTime(sessionCreated.Add(time.Hour))
+	enforcer.check()
+	assert.Empty(t, sessions.DestroyedSession)
+
+	enforcer.proposal.PaymentMethod = dto_openvpn.PaymentMethodPerTime{Duration: time.Hour}
+	enforcer.check()
+	enforcer.check()
+	assert.Empty(t, sessions.DestroyedSession)
+}
@@ -0,0 +1,43 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	server_dto "github.com/mysterium/node/server/dto"
+	"github.com/mysterium/node/session"
+)
+
+// Issuer pays provider for the session by sending it signed promises
+type Issuer interface {
+	Start() error
+	Stop()
+}
+
+// TrafficGetter returns number of bytes transferred in the session so far
+type TrafficGetter func() uint64
+
+// Tracker accepts promises which consumers send for provider's sessions and remembers the last one of each session
+type Tracker interface {
+	Accept(consumerID identity.Identity, sessionID session.SessionID, promise dto.SignedPromise) error
+	SessionPromise(sessionID session.SessionID) (dto.PromiseBody, bool)
+}
+
+// Ledger keeps the last promise of each session, implementations are safe for concurrent use.
+// Promises are kept per session rather than per consumer, because serial numbers and amounts
+// grow only within a session, consumer starts them anew in each of its sessions.
+// Promises of sessions, which got no promise for promiseRetention, are forgotten.
+type Ledger interface {
+	Save(promise dto.SignedPromise) error
+	Find(sessionID session.SessionID) (dto.SignedPromise, bool, error)
+	// ConsumerPromises returns the last promises of all sessions of the consumer, ordered by session
+	ConsumerPromises(consumerID identity.Identity) ([]dto.SignedPromise, error)
+}
+
+// SessionStatsGetter returns traffic of service's sessions
+type SessionStatsGetter func() []server_dto.SessionStats
+
+// Enforcer checks in background that sessions of one service are paid for
+type Enforcer interface {
+	Start()
+	Stop()
+}
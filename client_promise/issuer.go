@@ -0,0 +1,164 @@
+package client_promise
+
+import (
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/metering"
+	"github.com/mysterium/node/money"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+	"sync"
+	"time"
+)
+
+const issuerLogPrefix = "[promise-issuer] "
+
+// trafficCheckInterval is how often transferred bytes are checked when service is paid per bytes
+const trafficCheckInterval = 5 * time.Second
+
+// NewIssuer creates issuer which pays for the session in advance, according to payment method of proposal
+func NewIssuer(
+	issuerID identity.Identity,
+	benefiterID identity.Identity,
+	sessionID session.SessionID,
+	paymentMethod dto_discovery.PaymentMethod,
+	signer identity.Signer,
+	sender communication.Sender,
+	getTraffic TrafficGetter,
+) *issuer {
+	return &issuer{
+		issuerID:      issuerID,
+		benefiterID:   benefiterID,
+		sessionID:     sessionID,
+		paymentMethod: paymentMethod,
+		signer:        signer,
+		sender:        sender,
+		getTraffic:    getTraffic,
+		timeGetter:    time.Now,
+	}
+}
+
+type issuer struct {
+	issuerID      identity.Identity
+	benefiterID   identity.Identity
+	sessionID     session.SessionID
+	paymentMethod dto_discovery.PaymentMethod
+	signer        identity.Signer
+	sender        communication.Sender
+	getTraffic    TrafficGetter
+	timeGetter    func() time.Time
+
+	started      time.Time
+	serialNumber int
+	// promised is amount of the last delivered promise, zero until the first one is delivered
+	promised money.Money
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// Start pays for the first unit of service and keeps paying for consumed service in background
+func (issuer *issuer) Start() error {
+	interval, err := checkInterval(issuer.paymentMethod)
+	if err != nil {
+		return err
+	}
+
+	issuer.started = issuer.timeGetter()
+	issuer.stop = make(chan struct{})
+	issuer.stopped.Add(1)
+	go issuer.issuePeriodically(interval, issuer.stop)
+	return nil
+}
+
+// Stop finishes paying and waits until promise being sent is done
+func (issuer *issuer) Stop() {
+	if issuer.stop == nil {
+		return
+	}
+	close(issuer.stop)
+	issuer.stop = nil
+	issuer.stopped.Wait()
+}
+
+func (issuer *issuer) issuePeriodically(interval time.Duration, stop chan struct{}) {
+	defer issuer.stopped.Done()
+
+	issuer.issue()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			issuer.issue()
+		}
+	}
+}
+
+// issue sends cumulative promise, which pays one unit of service in advance, when delivered promise is used up.
+// Unsent promise is replaced by the next one
+func (issuer *issuer) issue() {
+	usage := metering.Usage{
+		Duration: issuer.timeGetter().Sub(issuer.started),
+		Bytes:    issuer.getTraffic(),
+	}
+	owed, err := metering.Cost(issuer.paymentMethod, usage)
+	if err != nil {
+		log.Error(issuerLogPrefix, "Failed to compute cost of session: ", err)
+		return
+	}
+	if issuer.serialNumber > 0 && owed.Amount < issuer.promised.Amount {
+		return
+	}
+
+	unit, err := metering.Unit(issuer.paymentMethod)
+	if err != nil {
+		log.Error(issuerLogPrefix, "Failed to compute cost of session: ", err)
+		return
+	}
+	amount, err := metering.Cost(issuer.paymentMethod, usage.Add(unit))
+	if err != nil {
+		log.Error(issuerLogPrefix, "Failed to compute cost of session: ", err)
+		return
+	}
+
+	issuer.serialNumber++
+	promise, err := SignPromise(
+		dto.PromiseBody{
+			SerialNumber: issuer.serialNumber,
+			SessionID:    issuer.sessionID,
+			IssuerID:     issuer.issuerID.Address,
+			BenefiterID:  issuer.benefiterID.Address,
+			Amount:       amount,
+		},
+		issuer.signer,
+	)
+	if err != nil {
+		log.Error(issuerLogPrefix, "Failed to sign promise: ", err)
+		return
+	}
+
+	if err := SendPromise(issuer.sender, issuer.sessionID, promise); err != nil {
+		log.Warn(issuerLogPrefix, "Failed to send promise ", promise.Promise.SerialNumber, ": ", err)
+		return
+	}
+	issuer.promised = amount
+}
+
+// checkInterval tells how often consumed service is checked for given payment method
+func checkInterval(paymentMethod dto_discovery.PaymentMethod) (time.Duration, error) {
+	unit, err := metering.Unit(paymentMethod)
+	if err != nil {
+		return 0, err
+	}
+	if unit.Duration > 0 {
+		return unit.Duration, nil
+	}
+	return trafficCheckInterval, nil
+}
@@ -0,0 +1,48 @@
+package client_promise
+
+import "sync"
+
+// IssuerFake records whether it was started and stopped
+type IssuerFake struct {
+	StartError error
+
+	mutex   sync.Mutex
+	started bool
+	stopped bool
+}
+
+// Start marks issuer started unless StartError is set
+func (issuer *IssuerFake) Start() error {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+
+	if issuer.StartError != nil {
+		return issuer.StartError
+	}
+	issuer.started = true
+	return nil
+}
+
+// Stop marks issuer stopped
+func (issuer *IssuerFake) Stop() {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+
+	issuer.stopped = true
+}
+
+// Started tells whether issuer was started
+func (issuer *IssuerFake) Started() bool {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+
+	return issuer.started
+}
+
+// Stopped tells whether issuer was stopped
+func (issuer *IssuerFake) Stopped() bool {
+	issuer.mutex.Lock()
+	defer issuer.mutex.Unlock()
+
+	return issuer.stopped
+}
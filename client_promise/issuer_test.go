@@ -0,0 +1,162 @@
+package client_promise
+
+import (
+	"context"
+	"errors"
+	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/datasize"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	dto_openvpn "github.com/mysterium/node/openvpn/discovery/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/utils"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	issuerStarted = time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+	perTime       = dto_openvpn.PaymentMethodPerTime{
+		Price:    money.NewMoney(0.125, money.CURRENCY_MYST),
+		Duration: time.Hour,
+	}
+	perBytes = dto_openvpn.PaymentMethodPerBytes{
+		Price: money.NewMoney(0.5, money.CURRENCY_MYST),
+		Bytes: datasize.MB,
+	}
+)
+
+type senderRecorder struct {
+	mutex    sync.Mutex
+	err      error
+	messages []PromiseMessage
+}
+
+func (sender *senderRecorder) Send(producer communication.MessageProducer) error {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	if sender.err != nil {
+		return sender.err
+	}
+	sender.messages = append(sender.messages, *producer.Produce().(*PromiseMessage))
+	return nil
+}
+
+func (sender *senderRecorder) Request(ctx context.Context, producer communication.RequestProducer) (responsePtr interface{}, err error) {
+	return nil, errors.New("not expected")
+}
+
+func (sender *senderRecorder) sentAmounts() []uint64 {
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	amounts := make([]uint64, 0, len(sender.messages))
+	for _, message := range sender.messages {
+		amounts = append(amounts, message.Promise.Promise.Amount.Amount)
+	}
+	return amounts
+}
+
+func newIssuer(paymentMethod dto_discovery.PaymentMethod, sender communication.Sender, traffic *uint64) (*issuer, *utils.SettableClock) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(issuerStarted)
+
+	getTraffic := func() uint64 {
+		return *traffic
+	}
+	issuer := NewIssuer(
+		identity.FromAddress("0x1"),
+		identity.FromAddress("0x2"),
+		"session-1",
+		paymentMethod,
+		&identity.SignerFake{},
+		sender,
+		getTraffic,
+	)
+	issuer.timeGetter = clock.GetTime
+	issuer.started = issuerStarted
+	return issuer, clock
+}
+
+func TestIssuerPaysFirstPeriodOnStart(t *testing.T) {
+	var _ Issuer = &issuer{}
+	sender := &senderRecorder{}
+	var traffic uint64
+	issuer, _ := newIssuer(perTime, sender, &traffic)
+
+	assert.NoError(t, issuer.Start())
+	issuer.Stop()
+
+	assert.Len(t, sender.messages, 1)
+	message := sender.messages[0]
+	assert.Equal(t, "session-1", string(message.SessionID))
+	assert.Equal(t, 1, message.Promise.Promise.SerialNumber)
+	assert.Equal(t, message.SessionID, message.Promise.Promise.SessionID)
+	assert.Equal(t, "0x1", message.Promise.Promise.IssuerID)
+	assert.Equal(t, "0x2", message.Promise.Promise.BenefiterID)
+	assert.Equal(t, perTime.Price, message.Promise.Promise.Amount)
+	assert.NotEmpty(t, message.Promise.IssuerSignature)
+}
+
+func TestIssuerPaysCumulativelyForStartedPeriods(t *testing.T) {
+	sender := &senderRecorder{}
+	var traffic uint64
+	issuer, clock := newIssuer(perTime, sender, &traffic)
+
+	issuer.issue()
+	clock.SetTime(issuerStarted.Add(30 * time.Minute))
+	issuer.issue()
+	clock.SetTime(issuerStarted.Add(time.Hour))
+	issuer.issue()
+	clock.SetTime(issuerStarted.Add(3 * time.Hour))
+	issuer.issue()
+
+	assert.Equal(t, []uint64{12500000, 25000000, 50000000}, sender.sentAmounts())
+	for i, message := range sender.messages {
+		assert.Equal(t, i+1, message.Promise.Promise.SerialNumber)
+	}
+}
+
+func TestIssuerPaysForStartedBlocksOfTraffic(t *testing.T) {
+	sender := &senderRecorder{}
+	var traffic uint64
+	issuer, _ := newIssuer(perBytes, sender, &traffic)
+
+	issuer.issue()
+	traffic = 1024*1024 - 1
+	issuer.issue()
+	traffic = 1024 * 1024
+	issuer.issue()
+	traffic = 5 * 1024 * 1024
+	issuer.issue()
+
+	assert.Equal(t, []uint64{50000000, 100000000, 300000000}, sender.sentAmounts())
+}
+
+func TestIssuerReplacesUnsentPromiseWithNextOne(t *testing.T) {
+	sender := &senderRecorder{err: errors.New("dialog closed")}
+	var traffic uint64
+	issuer, clock := newIssuer(perTime, sender, &traffic)
+
+	issuer.issue()
+	sender.err = nil
+	clock.SetTime(issuerStarted.Add(time.Hour))
+	issuer.issue()
+
+	assert.Len(t, sender.messages, 1)
+	assert.Equal(t, 2, sender.messages[0].Promise.Promise.SerialNumber)
+	assert.Equal(t, uint64(25000000), sender.messages[0].Promise.Promise.Amount.Amount)
+}
+
+func TestIssuerRejectsUnsupportedPaymentMethod(t *testing.T) {
+	var traffic uint64
+	issuer, _ := newIssuer(dto_openvpn.PaymentMethodPerTime{Price: perTime.Price}, &senderRecorder{}, &traffic)
+	assert.EqualError(t, issuer.Start(), "payment method has no duration")
+
+	issuer, _ = newIssuer(nil, &senderRecorder{}, &traffic)
+	assert.EqualError(t, issuer.Start(), "unsupported payment method: <nil>")
+	issuer.Stop()
+}
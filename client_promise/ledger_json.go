@@ -0,0 +1,79 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/utils/file"
+	"sync"
+	"time"
+)
+
+// NewLedgerJSON creates ledger which keeps promises in given JSON file, so that they can be redeemed after restart
+func NewLedgerJSON(path string) Ledger {
+	return &ledgerJSON{
+		path:       path,
+		timeGetter: time.Now,
+	}
+}
+
+type ledgerJSON struct {
+	path       string
+	timeGetter func() time.Time
+
+	mutex    sync.Mutex
+	promises ledgerEntries
+}
+
+func (ledger *ledgerJSON) Save(promise dto.SignedPromise) error {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+
+	if err := ledger.load(); err != nil {
+		return err
+	}
+
+	ledger.promises.add(promise, ledger.timeGetter())
+	return file.WriteJSON(ledger.path, ledger.promises.sorted())
+}
+
+func (ledger *ledgerJSON) Find(sessionID session.SessionID) (dto.SignedPromise, bool, error) {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+
+	if err := ledger.load(); err != nil {
+		return dto.SignedPromise{}, false, err
+	}
+
+	entry, found := ledger.promises[sessionID]
+	return entry.Promise, found, nil
+}
+
+func (ledger *ledgerJSON) ConsumerPromises(consumerID identity.Identity) ([]dto.SignedPromise, error) {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+
+	if err := ledger.load(); err != nil {
+		return nil, err
+	}
+
+	return ledger.promises.consumerPromises(consumerID), nil
+}
+
+// load reads promises from the file once, missing file means there are no promises
+func (ledger *ledgerJSON) load() error {
+	if ledger.promises != nil {
+		return nil
+	}
+
+	entries := make([]ledgerEntry, 0)
+	if err := file.ReadJSON(ledger.path, &entries); err != nil {
+		return err
+	}
+
+	ledger.promises = make(ledgerEntries, len(entries))
+	for _, entry := range entries {
+		ledger.promises[entry.Promise.Promise.SessionID] = entry
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createPromisesFile(t *testing.T) (string, func()) {
+	directory, err := ioutil.TempDir("", "promises-test-")
+	assert.NoError(t, err)
+
+	return filepath.Join(directory, "run", "promises.json"), func() { os.RemoveAll(directory) }
+}
+
+func TestLedgerJSON_PromisesArePersisted(t *testing.T) {
+	path, cleanup := createPromisesFile(t)
+	defer cleanup()
+
+	promise := signedPromise(t, "session-1", 2, 0.1)
+	otherPromise := signedPromise(t, "session-2", 1, 0.05)
+	ledger := NewLedgerJSON(path)
+	assert.NoError(t, ledger.Save(signedPromise(t, "session-1", 1, 0.05)))
+	assert.NoError(t, ledger.Save(promise))
+	assert.NoError(t, ledger.Save(otherPromise))
+
+	restored, found, err := NewLedgerJSON(path).Find("session-1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, promise, restored)
+
+	restored, found, err = NewLedgerJSON(path).Find("session-2")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, otherPromise, restored)
+
+	_, found, err = NewLedgerJSON(path).Find("session-3")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLedgerJSON_ReturnsPromisesOfConsumer(t *testing.T) {
+	path, cleanup := createPromisesFile(t)
+	defer cleanup()
+
+	promise := signedPromise(t, "session-1", 1, 0.1)
+	otherPromise := signedPromise(t, "session-2", 1, 0.05)
+	ledger := NewLedgerJSON(path)
+	assert.NoError(t, ledger.Save(otherPromise))
+	assert.NoError(t, ledger.Save(promise))
+
+	promises, err := NewLedgerJSON(path).ConsumerPromises(consumerID)
+	assert.NoError(t, err)
+	assert.Equal(t, []dto.SignedPromise{promise, otherPromise}, promises)
+
+	promises, err = NewLedgerJSON(path).ConsumerPromises(identity.FromAddress("0x3"))
+	assert.NoError(t, err)
+	assert.Empty(t, promises)
+}
+
+func TestLedgerJSON_ForgetsSessionsWithoutRecentPromises(t *testing.T) {
+	path, cleanup := createPromisesFile(t)
+	defer cleanup()
+
+	now := time.Date(2018, 8, 1, 0, 0, 0, 0, time.UTC)
+	ledger := &ledgerJSON{path: path, timeGetter: func() time.Time { return now }}
+	assert.NoError(t, ledger.Save(signedPromise(t, "session-1", 1, 0.1)))
+
+	now = now.Add(promiseRetention)
+	assert.NoError(t, ledger.Save(signedPromise(t, "session-2", 1, 0.1)))
+	_, found, err := NewLedgerJSON(path).Find("session-1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	now = now.Add(time.Second)
+	assert.NoError(t, ledger.Save(signedPromise(t, "session-2", 2, 0.2)))
+	_, found, err = NewLedgerJSON(path).Find("session-1")
+	assert.NoError(t, err)
+	assert.False(t, found)
+	_, found, err = NewLedgerJSON(path).Find("session-2")
+	assert.NoError(t, err)
+	assert.True(t, found)
+}
+
+func TestLedgerJSON_FailsWithMalformedFile(t *testing.T) {
+	path, cleanup := createPromisesFile(t)
+	defer cleanup()
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+
+	_, _, err := NewLedgerJSON(path).Find("session-1")
+	assert.Error(t, err)
+	assert.Error(t, NewLedgerJSON(path).Save(signedPromise(t, "session-1", 1, 0.1)))
+}
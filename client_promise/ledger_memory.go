@@ -0,0 +1,92 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+	"sort"
+	"sync"
+	"time"
+)
+
+// promiseRetention is how long promises of session are kept after the last one was received, they have to be redeemed within it
+const promiseRetention = 30 * 24 * time.Hour
+
+// NewLedgerMemory creates ledger which keeps promises until process exits
+func NewLedgerMemory() Ledger {
+	return &ledgerMemory{
+		promises:   make(ledgerEntries),
+		timeGetter: time.Now,
+	}
+}
+
+type ledgerMemory struct {
+	mutex      sync.RWMutex
+	promises   ledgerEntries
+	timeGetter func() time.Time
+}
+
+func (ledger *ledgerMemory) Save(promise dto.SignedPromise) error {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+
+	ledger.promises.add(promise, ledger.timeGetter())
+	return nil
+}
+
+func (ledger *ledgerMemory) Find(sessionID session.SessionID) (dto.SignedPromise, bool, error) {
+	ledger.mutex.RLock()
+	defer ledger.mutex.RUnlock()
+
+	entry, found := ledger.promises[sessionID]
+	return entry.Promise, found, nil
+}
+
+func (ledger *ledgerMemory) ConsumerPromises(consumerID identity.Identity) ([]dto.SignedPromise, error) {
+	ledger.mutex.RLock()
+	defer ledger.mutex.RUnlock()
+
+	return ledger.promises.consumerPromises(consumerID), nil
+}
+
+// ledgerEntry is the last promise of session and when it was received
+type ledgerEntry struct {
+	Promise  dto.SignedPromise `json:"promise"`
+	Received time.Time         `json:"received"`
+}
+
+type ledgerEntries map[session.SessionID]ledgerEntry
+
+// add replaces promise of the session and forgets sessions, which got no promise for promiseRetention
+func (entries ledgerEntries) add(promise dto.SignedPromise, now time.Time) {
+	entries[promise.Promise.SessionID] = ledgerEntry{Promise: promise, Received: now}
+
+	expired := now.Add(-promiseRetention)
+	for sessionID, entry := range entries {
+		if entry.Received.Before(expired) {
+			delete(entries, sessionID)
+		}
+	}
+}
+
+func (entries ledgerEntries) consumerPromises(consumerID identity.Identity) []dto.SignedPromise {
+	promises := make([]dto.SignedPromise, 0)
+	for _, entry := range entries.sorted() {
+		if identity.FromAddress(entry.Promise.Promise.IssuerID) == consumerID {
+			promises = append(promises, entry.Promise)
+		}
+	}
+	return promises
+}
+
+// sorted returns entries ordered by session
+func (entries ledgerEntries) sorted() []ledgerEntry {
+	sorted := make([]ledgerEntry, 0, len(entries))
+	for _, entry := range entries {
+		sorted = append(sorted, entry)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Promise.Promise.SessionID < sorted[j].Promise.Promise.SessionID
+	})
+	return sorted
+}
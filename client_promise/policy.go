@@ -0,0 +1,64 @@
+package client_promise
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultGrace is how long session may lag behind, when grace of enforced proposal is not given
+const DefaultGrace = 1 * time.Minute
+
+// Policy tells how sessions of proposal are treated when their promises lag behind consumed service
+type Policy struct {
+	// Enforce destroys lagging sessions, otherwise lag is only logged
+	Enforce bool
+	// Grace is how long session may stay behind, before promise for the started unit arrives
+	Grace time.Duration
+}
+
+// DefaultPolicy only logs lagging sessions
+func DefaultPolicy() Policy {
+	return Policy{
+		Enforce: false,
+		Grace:   DefaultGrace,
+	}
+}
+
+// Policies are payment policies keyed by ids of proposals
+type Policies map[int]Policy
+
+// Find returns policy of given proposal, proposals without own policy get default one
+func (policies Policies) Find(proposalID int) Policy {
+	if policy, found := policies[proposalID]; found {
+		return policy
+	}
+	return DefaultPolicy()
+}
+
+// ParsePolicies parses comma separated ids of proposals which are enforced, each with optional grace e.g. "1,2:30s"
+func ParsePolicies(value string) (Policies, error) {
+	policies := make(Policies)
+	if strings.TrimSpace(value) == "" {
+		return policies, nil
+	}
+
+	for _, policyValue := range strings.Split(value, ",") {
+		fields := strings.SplitN(strings.TrimSpace(policyValue), ":", 2)
+		proposalID, err := strconv.Atoi(fields[0])
+		if err != nil || proposalID < 1 {
+			return nil, fmt.Errorf("invalid payment policy %q, proposal id is expected", policyValue)
+		}
+
+		policy := Policy{Enforce: true, Grace: DefaultGrace}
+		if len(fields) == 2 {
+			policy.Grace, err = time.ParseDuration(fields[1])
+			if err != nil || policy.Grace < 0 {
+				return nil, fmt.Errorf("invalid payment policy %q, grace duration is expected", policyValue)
+			}
+		}
+		policies[proposalID] = policy
+	}
+	return policies, nil
+}
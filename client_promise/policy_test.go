@@ -0,0 +1,33 @@
+package client_promise
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies("1, 3:30s")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		Policies{
+			1: {Enforce: true, Grace: DefaultGrace},
+			3: {Enforce: true, Grace: 30 * time.Second},
+		},
+		policies,
+	)
+	assert.Equal(t, DefaultPolicy(), policies.Find(2))
+
+	policies, err = ParsePolicies("")
+	assert.NoError(t, err)
+	assert.Empty(t, policies)
+}
+
+func TestParsePoliciesWithInvalidValue(t *testing.T) {
+	_, err := ParsePolicies("openvpn")
+	assert.EqualError(t, err, `invalid payment policy "openvpn", proposal id is expected`)
+
+	_, err = ParsePolicies("1:soon")
+	assert.EqualError(t, err, `invalid payment policy "1:soon", grace duration is expected`)
+}
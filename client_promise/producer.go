@@ -0,0 +1,36 @@
+package client_promise
+
+import (
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/session"
+	"github.com/pkg/errors"
+)
+
+// PromiseProducer creates messages which deliver promise of session to provider
+type PromiseProducer struct {
+	SessionID session.SessionID
+	Promise   dto.SignedPromise
+}
+
+// GetMessageEndpoint returns endpoint of promise messages
+func (producer *PromiseProducer) GetMessageEndpoint() communication.MessageEndpoint {
+	return endpointPromise
+}
+
+// Produce creates promise message
+func (producer *PromiseProducer) Produce() (messagePtr interface{}) {
+	return &PromiseMessage{
+		SessionID: producer.SessionID,
+		Promise:   producer.Promise,
+	}
+}
+
+// SendPromise delivers signed promise of given session to provider
+func SendPromise(sender communication.Sender, sessionID session.SessionID, promise dto.SignedPromise) error {
+	err := sender.Send(&PromiseProducer{
+		SessionID: sessionID,
+		Promise:   promise,
+	})
+	return errors.Wrap(err, "Promise send failed")
+}
@@ -0,0 +1,30 @@
+package client_promise
+
+import (
+	"encoding/json"
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+)
+
+// SignPromise signs canonical serialization of promise body with issuer's identity
+func SignPromise(promise dto.PromiseBody, signer identity.Signer) (dto.SignedPromise, error) {
+	message, err := serializePromise(promise)
+	if err != nil {
+		return dto.SignedPromise{}, err
+	}
+
+	signature, err := signer.Sign(message)
+	if err != nil {
+		return dto.SignedPromise{}, err
+	}
+
+	return dto.SignedPromise{
+		Promise:         promise,
+		IssuerSignature: dto.Signature(signature.Base64()),
+	}, nil
+}
+
+// serializePromise returns bytes of promise body which signature is made of
+func serializePromise(promise dto.PromiseBody) ([]byte, error) {
+	return json.Marshal(promise)
+}
@@ -0,0 +1,39 @@
+package client_promise
+
+import (
+	"errors"
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSignPromiseSignsSerializedBody(t *testing.T) {
+	body := dto.PromiseBody{
+		SerialNumber: 3,
+		SessionID:    "session-1",
+		IssuerID:     "0x1",
+		BenefiterID:  "0x2",
+		Amount:       money.NewMoney(0.5, money.CURRENCY_MYST),
+	}
+
+	promise, err := SignPromise(body, &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.Equal(t, body, promise.Promise)
+
+	message, err := serializePromise(body)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"serial_number":3,"session_id":"session-1","issuer_id":"0x1","benefiter_id":"0x2","amount":{"amount":50000000,"currency":"MYST"}}`,
+		string(message),
+	)
+	signature := identity.SignatureBase64(string(promise.IssuerSignature))
+	assert.Equal(t, append([]byte("signed"), message...), signature.Bytes())
+}
+
+func TestSignPromiseReturnsSignerError(t *testing.T) {
+	_, err := SignPromise(dto.PromiseBody{}, &identity.SignerFake{ErrorMock: errors.New("keystore locked")})
+	assert.EqualError(t, err, "keystore locked")
+}
@@ -0,0 +1,97 @@
+package client_promise
+
+import (
+	"fmt"
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+	"sync"
+)
+
+const trackerLogPrefix = "[promise-tracker] "
+
+// NewTracker creates tracker which accepts valid promises to pay given provider and keeps the last promise of each session in ledger
+func NewTracker(providerID identity.Identity, sessions session.Registry, extractor identity.Extractor, ledger Ledger) *tracker {
+	return &tracker{
+		providerID: providerID,
+		sessions:   sessions,
+		extractor:  extractor,
+		ledger:     ledger,
+	}
+}
+
+type tracker struct {
+	providerID identity.Identity
+	sessions   session.Registry
+	extractor  identity.Extractor
+	ledger     Ledger
+
+	mutex sync.Mutex
+}
+
+// Accept verifies that promise is signed by consumer for the session and that it promises more than the previous one
+func (tracker *tracker) Accept(consumerID identity.Identity, sessionID session.SessionID, promise dto.SignedPromise) error {
+	providerSession, found := tracker.sessions.Lookup(sessionID)
+	if !found || providerSession.ConsumerID != consumerID {
+		return fmt.Errorf("session doesn't exist: %s", sessionID)
+	}
+	if promise.Promise.SessionID != sessionID {
+		return fmt.Errorf("promise is issued for session %s instead of %s", promise.Promise.SessionID, sessionID)
+	}
+	if err := tracker.verify(consumerID, promise); err != nil {
+		return err
+	}
+
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+
+	previous, found, err := tracker.ledger.Find(sessionID)
+	if err != nil {
+		return err
+	}
+	if found {
+		if promise.Promise.SerialNumber <= previous.Promise.SerialNumber {
+			return fmt.Errorf("promise serial number %d is not greater than %d", promise.Promise.SerialNumber, previous.Promise.SerialNumber)
+		}
+		if increase, err := promise.Promise.Amount.Sub(previous.Promise.Amount); err != nil || increase.Amount == 0 {
+			return fmt.Errorf("promise amount is not greater than %s", previous.Promise.Amount)
+		}
+	}
+
+	log.Info(trackerLogPrefix, "Consumer ", consumerID.Address, " promised ", promise.Promise.Amount, " for session ", sessionID)
+	return tracker.ledger.Save(promise)
+}
+
+// SessionPromise returns the last accepted promise of the session
+func (tracker *tracker) SessionPromise(sessionID session.SessionID) (dto.PromiseBody, bool) {
+	promise, found, err := tracker.ledger.Find(sessionID)
+	if err != nil {
+		log.Error(trackerLogPrefix, "Failed to find promise of session ", sessionID, ": ", err)
+		return dto.PromiseBody{}, false
+	}
+	return promise.Promise, found
+}
+
+// verify checks that promise is issued and signed by the consumer for this provider
+func (tracker *tracker) verify(consumerID identity.Identity, promise dto.SignedPromise) error {
+	if identity.FromAddress(promise.Promise.IssuerID) != consumerID {
+		return fmt.Errorf("promise is issued by %s instead of consumer", promise.Promise.IssuerID)
+	}
+	if identity.FromAddress(promise.Promise.BenefiterID) != tracker.providerID {
+		return fmt.Errorf("promise benefits %s instead of provider", promise.Promise.BenefiterID)
+	}
+
+	message, err := serializePromise(promise.Promise)
+	if err != nil {
+		return err
+	}
+	signerID, err := tracker.extractor.Extract(message, identity.SignatureBase64(string(promise.IssuerSignature)))
+	if err != nil {
+		return fmt.Errorf("promise signature is invalid: %s", err)
+	}
+	if signerID != consumerID {
+		return fmt.Errorf("promise is signed by %s instead of consumer", signerID.Address)
+	}
+	return nil
+}
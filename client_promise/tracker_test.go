@@ -0,0 +1,186 @@
+package client_promise
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mysterium/node/client_promise/dto"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	"github.com/mysterium/node/session"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	consumerID = identity.FromAddress("0x1")
+	providerID = identity.FromAddress("0x2")
+)
+
+// extractorFake recovers signer of messages signed by identity.SignerFake
+type extractorFake struct {
+	signerID identity.Identity
+}
+
+func (extractor *extractorFake) Extract(message []byte, signature identity.Signature) (identity.Identity, error) {
+	if !bytes.Equal(append([]byte("signed"), message...), signature.Bytes()) {
+		return identity.Identity{}, errors.New("signature does not match message")
+	}
+	return extractor.signerID, nil
+}
+
+func newTracker(ledger Ledger) *tracker {
+	sessions := &session.ManagerFake{
+		Sessions: map[session.SessionID]session.Session{
+			"session-1": {ID: "session-1", ConsumerID: consumerID},
+			"session-2": {ID: "session-2", ConsumerID: consumerID},
+		},
+	}
+	return NewTracker(providerID, sessions, &extractorFake{consumerID}, ledger)
+}
+
+func signedPromise(t *testing.T, sessionID session.SessionID, serialNumber int, amount float64) dto.SignedPromise {
+	promise, err := SignPromise(
+		dto.PromiseBody{
+			SerialNumber: serialNumber,
+			SessionID:    sessionID,
+			IssuerID:     consumerID.Address,
+			BenefiterID:  providerID.Address,
+			Amount:       money.NewMoney(amount, money.CURRENCY_MYST),
+		},
+		&identity.SignerFake{},
+	)
+	assert.NoError(t, err)
+	return promise
+}
+
+func TestTrackerAcceptsIncreasingPromises(t *testing.T) {
+	var _ Tracker = &tracker{}
+	ledger := NewLedgerMemory()
+	tracker := newTracker(ledger)
+
+	assert.NoError(t, tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 1, 0.1)))
+	assert.NoError(t, tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 2, 0.2)))
+
+	promise, found := tracker.SessionPromise("session-1")
+	assert.True(t, found)
+	assert.Equal(t, 2, promise.SerialNumber)
+
+	last, found, err := ledger.Find("session-1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, signedPromise(t, "session-1", 2, 0.2), last)
+}
+
+func TestTrackerRejectsPromiseWhichIsNotIncreasing(t *testing.T) {
+	tracker := newTracker(NewLedgerMemory())
+	assert.NoError(t, tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 2, 0.2)))
+
+	assert.EqualError(
+		t,
+		tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 2, 0.3)),
+		"promise serial number 2 is not greater than 2",
+	)
+	assert.EqualError(
+		t,
+		tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 3, 0.2)),
+		"promise amount is not greater than 0.2 MYST",
+	)
+
+	promise, _ := tracker.SessionPromise("session-1")
+	assert.Equal(t, 2, promise.SerialNumber)
+}
+
+func TestTrackerRejectsPromiseOfOtherConsumer(t *testing.T) {
+	tracker := newTracker(NewLedgerMemory())
+	otherID := identity.FromAddress("0x3")
+
+	assert.EqualError(t, tracker.Accept(otherID, "session-1", signedPromise(t, "session-1", 1, 0.1)), "session doesn't exist: session-1")
+	assert.EqualError(t, tracker.Accept(consumerID, "session-3", signedPromise(t, "session-1", 1, 0.1)), "session doesn't exist: session-3")
+
+	promise := signedPromise(t, "session-1", 1, 0.1)
+	promise.Promise.IssuerID = otherID.Address
+	assert.EqualError(t, tracker.Accept(consumerID, "session-1", promise), "promise is issued by 0x3 instead of consumer")
+
+	promise = signedPromise(t, "session-1", 1, 0.1)
+	promise.Promise.BenefiterID = otherID.Address
+	assert.EqualError(t, tracker.Accept(consumerID, "session-1", promise), "promise benefits 0x3 instead of provider")
+}
+
+func TestTrackerRejectsPromiseWithInvalidSignature(t *testing.T) {
+	tracker := newTracker(NewLedgerMemory())
+
+	promise := signedPromise(t, "session-1", 1, 0.1)
+	promise.Promise.Amount = money.NewMoney(10, money.CURRENCY_MYST)
+	assert.EqualError(
+		t,
+		tracker.Accept(consumerID, "session-1", promise),
+		"promise signature is invalid: signature does not match message",
+	)
+
+	tracker.extractor = &extractorFake{identity.FromAddress("0x3")}
+	assert.EqualError(
+		t,
+		tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 1, 0.1)),
+		"promise is signed by 0x3 instead of consumer",
+	)
+
+	_, found := tracker.SessionPromise("session-1")
+	assert.False(t, found)
+}
+
+func TestTrackerKeepsPromisesOfEverySession(t *testing.T) {
+	ledger := NewLedgerMemory()
+	tracker := newTracker(ledger)
+
+	assert.NoError(t, tracker.Accept(consumerID, "session-1", signedPromise(t, "session-1", 3, 0.3)))
+	assert.NoError(t, tracker.Accept(consumerID, "session-2", signedPromise(t, "session-2", 1, 0.1)))
+
+	first, found, err := ledger.Find("session-1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, signedPromise(t, "session-1", 3, 0.3), first)
+
+	second, found, err := ledger.Find("session-2")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, signedPromise(t, "session-2", 1, 0.1), second)
+}
+
+func TestTrackerRejectsPromiseOfOtherSession(t *testing.T) {
+	tracker := newTracker(NewLedgerMemory())
+	promise := signedPromise(t, "session-1", 1, 0.1)
+	assert.NoError(t, tracker.Accept(consumerID, "session-1", promise))
+
+	assert.EqualError(
+		t,
+		tracker.Accept(consumerID, "session-2", promise),
+		"promise is issued for session session-1 instead of session-2",
+	)
+
+	replayed := promise
+	replayed.Promise.SessionID = "session-2"
+	assert.EqualError(
+		t,
+		tracker.Accept(consumerID, "session-2", replayed),
+		"promise signature is invalid: signature does not match message",
+	)
+
+	_, found := tracker.SessionPromise("session-2")
+	assert.False(t, found)
+}
+
+func TestTrackerContinuesSessionFromLedgerAfterRestart(t *testing.T) {
+	ledger := NewLedgerMemory()
+	assert.NoError(t, newTracker(ledger).Accept(consumerID, "session-1", signedPromise(t, "session-1", 2, 0.2)))
+
+	restarted := newTracker(ledger)
+	promise, found := restarted.SessionPromise("session-1")
+	assert.True(t, found)
+	assert.Equal(t, 2, promise.SerialNumber)
+
+	assert.EqualError(
+		t,
+		restarted.Accept(consumerID, "session-1", signedPromise(t, "session-1", 1, 0.1)),
+		"promise serial number 1 is not greater than 2",
+	)
+}
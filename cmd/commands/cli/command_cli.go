@@ -220,6 +220,7 @@ func (c *Command) status() {
 			info(fmt.Sprintf("Connection duration: %ds", statistics.Duration))
 			info("Bytes sent:", statistics.BytesSent)
 			info("Bytes received:", statistics.BytesReceived)
+			info(fmt.Sprintf("Current rate: %.0f B/s up, %.0f B/s down", statistics.BytesSentPerSecond, statistics.BytesReceivedPerSecond))
 		}
 	}
 }
@@ -4,16 +4,19 @@ import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/mysterium/node/client_connection"
+	"github.com/mysterium/node/cmd"
 	"github.com/mysterium/node/communication"
 	nats_dialog "github.com/mysterium/node/communication/nats/dialog"
 	nats_discovery "github.com/mysterium/node/communication/nats/discovery"
+	"github.com/mysterium/node/dns"
+	"github.com/mysterium/node/firewall"
 	"github.com/mysterium/node/identity"
 	"github.com/mysterium/node/ip"
-	"github.com/mysterium/node/openvpn"
 	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
 	"github.com/mysterium/node/server"
 	"github.com/mysterium/node/tequilapi"
 	tequilapi_endpoints "github.com/mysterium/node/tequilapi/endpoints"
+	"path/filepath"
 	"time"
 )
 
@@ -31,7 +34,7 @@ func NewCommandWith(
 	mysteriumClient server.Client,
 ) *Command {
 	nats_discovery.Bootstrap()
-	openvpn.Bootstrap()
+	cmd.BootstrapServices()
 
 	keystoreInstance := keystore.NewKeyStore(options.DirectoryKeystore, keystore.StandardScryptN, keystore.StandardScryptP)
 
@@ -46,15 +49,53 @@ func NewCommandWith(
 	}
 
 	statsKeeper := bytescount.NewSessionStatsKeeper(time.Now)
+	eventBus := client_connection.NewEventBus()
 
-	vpnClientFactory := client_connection.ConfigureVpnClientFactory(mysteriumClient, options.DirectoryRuntime, signerFactory, statsKeeper)
+	dnsProtector := dns.NewProtector(dns.NewConfigurator())
 
-	connectionManager := client_connection.NewManager(mysteriumClient, dialogEstablisherFactory, vpnClientFactory, statsKeeper)
+	vpnClientFactory := client_connection.ConfigureVpnClientFactory(
+		mysteriumClient,
+		options.DirectoryRuntime,
+		signerFactory,
+		statsKeeper,
+		eventBus,
+		dnsProtector,
+	)
+
+	promiseIssuerFactory := client_connection.ConfigurePromiseIssuerFactory(signerFactory, statsKeeper)
+
+	reconnectPolicy := client_connection.DefaultReconnectPolicy()
+	reconnectPolicy.MaxAttempts = options.ReconnectAttempts
+	reconnectPolicy.InitialDelay = options.ReconnectDelay
+
+	var killSwitch firewall.FirewallService
+	if options.KillSwitch {
+		killSwitch = firewall.NewService()
+	}
+
+	historyStore := client_connection.NewHistoryStoreJSON(filepath.Join(options.DirectoryRuntime, "connection-history.json"))
+
+	connectionManager := client_connection.NewManager(
+		mysteriumClient,
+		dialogEstablisherFactory,
+		vpnClientFactory,
+		promiseIssuerFactory,
+		statsKeeper,
+		reconnectPolicy,
+		eventBus,
+		client_connection.SelectCheapestProposals,
+		identity.NewExtractor(),
+		killSwitch,
+		historyStore,
+	)
 
 	router := tequilapi.NewAPIRouter()
 	tequilapi_endpoints.AddRoutesForIdentities(router, identityManager, mysteriumClient, signerFactory)
 	ipResolver := ip.NewResolver()
 	tequilapi_endpoints.AddRoutesForConnection(router, connectionManager, ipResolver, statsKeeper)
+	tequilapi_endpoints.AddRoutesForConnectionEvents(router, connectionManager, eventBus)
+	tequilapi_endpoints.AddRoutesForConnectionDNS(router, dnsProtector)
+	tequilapi_endpoints.AddRoutesForConnectionHistory(router, historyStore)
 	tequilapi_endpoints.AddRoutesForProposals(router, mysteriumClient)
 
 	httpAPIServer := tequilapi.NewServer(options.TequilapiAddress, options.TequilapiPort, router)
@@ -95,7 +136,7 @@ func (cmd *Command) Wait() error {
 //Kill stops tequilapi service
 func (cmd *Command) Kill() error {
 	err := cmd.connectionManager.Disconnect()
-	if err != nil {
+	if err != nil && err != client_connection.ErrNoConnection {
 		return err
 	}
 
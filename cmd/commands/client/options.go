@@ -2,7 +2,9 @@ package client
 
 import (
 	"flag"
+	"github.com/mysterium/node/firewall"
 	"github.com/mysterium/node/utils/file"
+	"time"
 )
 
 // CommandOptions describes options which are required to start Command
@@ -12,6 +14,10 @@ type CommandOptions struct {
 	TequilapiAddress  string
 	TequilapiPort     int
 	CLI               bool
+
+	ReconnectAttempts int
+	ReconnectDelay    time.Duration
+	KillSwitch        bool
 }
 
 // ParseArguments parses CLI flags and adds to CommandOptions structure
@@ -50,10 +56,33 @@ func ParseArguments(args []string) (options CommandOptions, err error) {
 		"Run an interactive CLI based Mysterium UI",
 	)
 
+	flags.IntVar(
+		&options.ReconnectAttempts,
+		"reconnect.attempts",
+		5,
+		"How many times to try restoring dropped connection, 0 disables reconnecting",
+	)
+	flags.DurationVar(
+		&options.ReconnectDelay,
+		"reconnect.delay",
+		1*time.Second,
+		"Delay before the first reconnect attempt, doubled after each failed attempt",
+	)
+
+	flags.BoolVar(
+		&options.KillSwitch,
+		"killswitch",
+		false,
+		"Block all traffic outside of tunnel while connected, including when connection drops",
+	)
+
 	err = flags.Parse(args[1:])
 	if err != nil {
 		return
 	}
+	if options.KillSwitch && !firewall.Supported {
+		return options, firewall.ErrUnsupported
+	}
 
 	return options, err
 }
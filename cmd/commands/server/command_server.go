@@ -2,18 +2,19 @@ package server
 
 import (
 	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/access"
+	"github.com/mysterium/node/client_promise"
 	"github.com/mysterium/node/communication"
 	"github.com/mysterium/node/identity"
 	"github.com/mysterium/node/ip"
 	"github.com/mysterium/node/location"
 	"github.com/mysterium/node/nat"
-	"github.com/mysterium/node/openvpn"
-	"github.com/mysterium/node/openvpn/discovery"
 	"github.com/mysterium/node/server"
+	"github.com/mysterium/node/service"
 	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/tequilapi"
 	"github.com/pkg/errors"
-	"time"
 )
 
 // Command represent entrypoint for Mysterium server with top level components
@@ -24,14 +25,27 @@ type Command struct {
 	mysteriumClient  server.Client
 	natService       nat.NATService
 	locationDetector location.Detector
+	accessPolicy     access.ReloadingPolicy
 
 	dialogWaiterFactory func(identity identity.Identity) communication.DialogWaiter
 	dialogWaiter        communication.DialogWaiter
 
-	sessionManagerFactory func(serverIP string) session.Manager
+	servicesFactory func(
+		providerID identity.Identity,
+		providerContact dto_discovery.Contact,
+		serviceLocation dto_discovery.Location,
+		serverIP string,
+	) (service.Registry, error)
+	services service.Registry
 
-	vpnServerFactory func(sessionManager session.Manager) *openvpn.Server
-	vpnServer        *openvpn.Server
+	promiseLedger   client_promise.Ledger
+	paymentPolicies client_promise.Policies
+	enforcers       []client_promise.Enforcer
+
+	statsReporter server.StatsReporter
+
+	adminAPIServerFactory func(sessions session.Registry) tequilapi.APIServer
+	adminAPIServer        tequilapi.APIServer
 }
 
 // Run starts server - does not block
@@ -41,6 +55,10 @@ func (cmd *Command) Run() (err error) {
 		return err
 	}
 
+	if err := cmd.accessPolicy.Start(); err != nil {
+		return err
+	}
+
 	cmd.dialogWaiter = cmd.dialogWaiterFactory(providerID)
 	providerContact, err := cmd.dialogWaiter.Start()
 
@@ -50,43 +68,70 @@ func (cmd *Command) Run() (err error) {
 		return err
 	}
 
-	cmd.natService.Add(nat.RuleForwarding{
-		SourceAddress: "10.8.0.0/24",
-		TargetIP:      vpnServerIP,
-	})
-	if err = cmd.natService.Start(); err != nil {
+	serviceLocation, err := detectCountry(cmd.ipResolver, cmd.locationDetector)
+	if err != nil {
 		return err
 	}
 
-	serviceLocation, err := detectCountry(cmd.ipResolver, cmd.locationDetector)
+	cmd.services, err = cmd.servicesFactory(providerID, providerContact, serviceLocation, vpnServerIP)
 	if err != nil {
 		return err
 	}
-	proposal := discovery.NewServiceProposalWithLocation(providerID, providerContact, serviceLocation)
 
-	sessionManager := cmd.sessionManagerFactory(vpnServerIP)
-
-	dialogHandler := session.NewDialogHandler(proposal.ID, sessionManager)
-	if err := cmd.dialogWaiter.ServeDialogs(dialogHandler); err != nil {
+	if err = cmd.natService.Start(); err != nil {
 		return err
 	}
 
-	cmd.vpnServer = cmd.vpnServerFactory(sessionManager)
-	if err := cmd.vpnServer.Start(); err != nil {
+	sessionManagers := cmd.services.SessionManagers()
+	cmd.adminAPIServer = cmd.adminAPIServerFactory(sessionManagers)
+	if err := cmd.adminAPIServer.StartServing(); err != nil {
 		return err
 	}
 
-	signer := cmd.createSigner(providerID)
+	// consumers get sessions only once services serve them
+	if err := cmd.services.Start(); err != nil {
+		return err
+	}
 
-	if err := cmd.mysteriumClient.RegisterProposal(proposal, signer); err != nil {
+	promiseTracker := client_promise.NewTracker(providerID, sessionManagers, identity.NewExtractor(), cmd.promiseLedger)
+	dialogHandler := client_promise.NewDialogHandler(
+		session.NewDialogHandler(sessionManagers, cmd.accessPolicy),
+		promiseTracker,
+	)
+	if err := cmd.dialogWaiter.ServeDialogs(dialogHandler); err != nil {
 		return err
 	}
-	go func() {
-		for {
-			time.Sleep(1 * time.Minute)
-			cmd.mysteriumClient.NodeSendStats(providerID.Address, signer)
+
+	for _, providedService := range cmd.services.Services() {
+		proposalID := providedService.Proposal().ID
+		enforcer := client_promise.NewEnforcer(
+			providedService.Proposal(),
+			cmd.paymentPolicies.Find(proposalID),
+			providedService.SessionManager(),
+			providedService.SessionStats,
+			promiseTracker,
+		)
+		enforcer.Start()
+		cmd.enforcers = append(cmd.enforcers, enforcer)
+	}
+
+	signer := cmd.createSigner(providerID)
+
+	for _, providedService := range cmd.services.Services() {
+		if err := cmd.mysteriumClient.RegisterProposal(providedService.Proposal(), signer); err != nil {
+			return err
 		}
-	}()
+	}
+
+	cmd.statsReporter = server.NewStatsReporter(
+		cmd.mysteriumClient,
+		providerID.Address,
+		signer,
+		cmd.services.SessionStats,
+		cmd.services.AcknowledgeSessionStats,
+		server.DefaultReportPolicy(),
+	)
+	cmd.statsReporter.Start()
 
 	return nil
 }
@@ -108,12 +153,24 @@ func detectCountry(ipResolver ip.Resolver, locationDetector location.Detector) (
 
 // Wait blocks until server is stopped
 func (cmd *Command) Wait() error {
-	return cmd.vpnServer.Wait()
+	return cmd.services.Wait()
 }
 
 // Kill stops server
 func (cmd *Command) Kill() error {
-	cmd.vpnServer.Stop()
+	if cmd.statsReporter != nil {
+		cmd.statsReporter.Stop()
+	}
+	for _, enforcer := range cmd.enforcers {
+		enforcer.Stop()
+	}
+	if cmd.services != nil {
+		cmd.services.Stop()
+	}
+	cmd.accessPolicy.Stop()
+	if cmd.adminAPIServer != nil {
+		cmd.adminAPIServer.Stop()
+	}
 	err := cmd.dialogWaiter.Stop()
 	if err != nil {
 		return err
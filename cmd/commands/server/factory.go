@@ -1,7 +1,11 @@
 package server
 
 import (
+	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/mysterium/node/access"
+	"github.com/mysterium/node/client_promise"
+	"github.com/mysterium/node/cmd"
 	identity_handler "github.com/mysterium/node/cmd/commands/server/identity"
 	"github.com/mysterium/node/communication"
 	nats_dialog "github.com/mysterium/node/communication/nats/dialog"
@@ -10,14 +14,20 @@ import (
 	"github.com/mysterium/node/ip"
 	"github.com/mysterium/node/location"
 	"github.com/mysterium/node/nat"
-	"github.com/mysterium/node/openvpn"
-	"github.com/mysterium/node/openvpn/middlewares/server/auth"
 	openvpn_session "github.com/mysterium/node/openvpn/session"
 	"github.com/mysterium/node/server"
+	"github.com/mysterium/node/service"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/tequilapi"
+	tequilapi_endpoints "github.com/mysterium/node/tequilapi/endpoints"
 	"path/filepath"
+	"time"
 )
 
+// accessPolicyReloadInterval is how often access policy file is checked for changes
+const accessPolicyReloadInterval = 10 * time.Second
+
 // NewCommand function creates new server command by given options
 func NewCommand(options CommandOptions) *Command {
 	return NewCommandWith(
@@ -35,6 +45,7 @@ func NewCommandWith(
 	ipResolver ip.Resolver,
 	natService nat.NATService,
 ) *Command {
+	cmd.BootstrapServices()
 
 	keystoreInstance := keystore.NewKeyStore(options.DirectoryKeystore, keystore.StandardScryptN, keystore.StandardScryptP)
 	cache := identity.NewIdentityCache(options.DirectoryKeystore, "remember.json")
@@ -58,6 +69,22 @@ func NewCommandWith(
 		locationDetector = location.NewDetectorFake("")
 	}
 
+	expiryPolicy := openvpn_session.DefaultExpiryPolicy()
+	expiryPolicy.UnusedTTL = options.SessionUnusedTTL
+	expiryPolicy.IdleTTL = options.SessionIdleTTL
+
+	limitPolicy := openvpn_session.DefaultLimitPolicy()
+	limitPolicy.MaxSessions = options.SessionMax
+	limitPolicy.MaxSessionsPerConsumer = options.SessionMaxPerConsumer
+	limitPolicy.CreateRate = options.SessionCreateRate
+
+	accessPolicy := access.NewPolicyFile(
+		filepath.Join(options.DirectoryConfig, options.AccessPolicyFile),
+		accessPolicyReloadInterval,
+	)
+
+	promiseLedger := client_promise.NewLedgerJSON(filepath.Join(options.DirectoryRuntime, "promises.json"))
+
 	return &Command{
 		identityLoader: func() (identity.Identity, error) {
 			return identity_handler.LoadIdentity(identityHandler, options.NodeKey, options.Passphrase)
@@ -67,40 +94,89 @@ func NewCommandWith(
 		ipResolver:       ipResolver,
 		mysteriumClient:  mysteriumClient,
 		natService:       natService,
+		accessPolicy:     accessPolicy,
+		promiseLedger:    promiseLedger,
+		paymentPolicies:  options.PaymentPolicies,
 		dialogWaiterFactory: func(myID identity.Identity) communication.DialogWaiter {
 			return nats_dialog.NewDialogWaiter(
 				nats_discovery.NewAddressGenerate(myID),
 				identity.NewSigner(keystoreInstance, myID),
+				accessPolicy,
 			)
 		},
-		sessionManagerFactory: func(vpnServerIP string) session.Manager {
-			return openvpn_session.NewManager(
-				openvpn.NewClientConfig(
-					vpnServerIP,
-					filepath.Join(options.DirectoryConfig, "ca.crt"),
-					filepath.Join(options.DirectoryConfig, "ta.key"),
-				),
-				&session.UUIDGenerator{},
-			)
-		},
-		vpnServerFactory: func(manager session.Manager) *openvpn.Server {
-			vpnServerConfig := openvpn.NewServerConfig(
-				"10.8.0.0", "255.255.255.0",
-				filepath.Join(options.DirectoryConfig, "ca.crt"),
-				filepath.Join(options.DirectoryConfig, "server.crt"),
-				filepath.Join(options.DirectoryConfig, "server.key"),
-				filepath.Join(options.DirectoryConfig, "dh.pem"),
-				filepath.Join(options.DirectoryConfig, "crl.pem"),
-				filepath.Join(options.DirectoryConfig, "ta.key"),
-			)
-			sessionValidator := openvpn_session.NewSessionValidator(
-				manager.FindSession,
-				identity.NewExtractor(),
-			)
-			vpnMiddlewares := []openvpn.ManagementMiddleware{
-				auth.NewMiddleware(sessionValidator),
+		servicesFactory: func(
+			providerID identity.Identity,
+			providerContact dto_discovery.Contact,
+			serviceLocation dto_discovery.Location,
+			serverIP string,
+		) (service.Registry, error) {
+			services := service.NewRegistry()
+			for index, spec := range options.Services {
+				plugin, found := service.FindPlugin(spec.ServiceType)
+				if !found {
+					return nil, fmt.Errorf("unsupported service type: %s", spec.ServiceType)
+				}
+				serviceOptions, err := plugin.ParseOptions(spec.Options)
+				if err != nil {
+					return nil, err
+				}
+
+				proposalID := index + 1
+				newService, err := plugin.NewService(
+					service.ServiceParams{
+						ProposalID:        proposalID,
+						ProviderID:        providerID,
+						ProviderContact:   providerContact,
+						Location:          serviceLocation,
+						ServerIP:          serverIP,
+						DirectoryConfig:   options.DirectoryConfig,
+						DirectoryRuntime:  options.DirectoryRuntime,
+						NATService:        natService,
+						NewSessionManager: newSessionManagerFactory(options, spec, expiryPolicy, limitPolicy),
+					},
+					serviceOptions,
+				)
+				if err != nil {
+					return nil, err
+				}
+				if err := services.Add(newService); err != nil {
+					return nil, err
+				}
 			}
-			return openvpn.NewServer(vpnServerConfig, options.DirectoryRuntime, vpnMiddlewares...)
+			return services, nil
+		},
+		adminAPIServerFactory: func(sessions session.Registry) tequilapi.APIServer {
+			router := tequilapi.NewAPIRouter()
+			tequilapi_endpoints.AddRoutesForSessions(router, sessions)
+			// admin api destroys sessions, so web pages opened by provider must not reach it
+			return tequilapi.NewServerWithoutCors(options.AdminAddress, options.AdminPort, router)
 		},
 	}
 }
+
+// newSessionManagerFactory creates factory of session managers, which keep sessions of each service in its own storage.
+// Storage is named by type and options of service, so that sessions are not loaded by other service after restart
+func newSessionManagerFactory(
+	options CommandOptions,
+	spec service.Spec,
+	expiryPolicy openvpn_session.ExpiryPolicy,
+	limitPolicy openvpn_session.LimitPolicy,
+) service.SessionManagerFactory {
+	return func(generateConfig session.ConfigGenerator, killClient session.ClientKiller) session.ExpiringManager {
+		sessionStorage := session.NewStorageMemory()
+		if options.SessionPersist {
+			sessionStorage = session.NewStorageJSON(
+				filepath.Join(options.DirectoryRuntime, fmt.Sprintf("sessions-%s.json", spec.Key())),
+			)
+		}
+
+		return openvpn_session.NewManager(
+			generateConfig,
+			&session.UUIDGenerator{},
+			sessionStorage,
+			expiryPolicy,
+			limitPolicy,
+			killClient,
+		)
+	}
+}
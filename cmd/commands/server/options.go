@@ -2,7 +2,10 @@ package server
 
 import (
 	"flag"
+	"github.com/mysterium/node/client_promise"
+	"github.com/mysterium/node/service"
 	"github.com/mysterium/node/utils/file"
+	"time"
 )
 
 // CommandOptions describes options which are required to start Command
@@ -15,6 +18,23 @@ type CommandOptions struct {
 
 	LocationCountry  string
 	LocationDatabase string
+
+	AccessPolicyFile string
+
+	Services []service.Spec
+
+	PaymentPolicies client_promise.Policies
+
+	AdminAddress string
+	AdminPort    int
+
+	SessionUnusedTTL time.Duration
+	SessionIdleTTL   time.Duration
+	SessionPersist   bool
+
+	SessionMax            int
+	SessionMaxPerConsumer int
+	SessionCreateRate     int
 }
 
 // ParseArguments parses CLI flags and adds to CommandOptions structure
@@ -68,10 +88,89 @@ func ParseArguments(args []string) (options CommandOptions, err error) {
 		"Service location country. If not given country is autodetected",
 	)
 
+	flags.StringVar(
+		&options.AccessPolicyFile,
+		"access.policy",
+		"access-policy.json",
+		"Consumer allow and deny lists in JSON format e.g. {\"allow\": [], \"deny\": [\"0x...\"]}, reloaded when changed",
+	)
+
+	var services string
+	flags.StringVar(
+		&services,
+		"services",
+		"openvpn:udp:1194",
+		"Comma separated services node runs, each with its own proposal, given as type:options e.g. openvpn:udp:1194,openvpn:tcp:443:0.2,socks5:1080",
+	)
+
+	var paymentPolicies string
+	flags.StringVar(
+		&paymentPolicies,
+		"payments.enforce",
+		"",
+		"Comma separated ids of proposals whose sessions are destroyed when not paid for, each with optional grace e.g. 1,2:30s",
+	)
+
+	flags.StringVar(
+		&options.AdminAddress,
+		"admin.address",
+		"localhost",
+		"IP address of interface to listen for incoming admin api requests",
+	)
+	flags.IntVar(
+		&options.AdminPort,
+		"admin.port",
+		4051,
+		"Port for listening incoming admin api requests, which manage sessions of consumers",
+	)
+
+	flags.DurationVar(
+		&options.SessionUnusedTTL,
+		"session.unused-ttl",
+		5*time.Minute,
+		"How long created session stays valid when consumer never connects with it",
+	)
+	flags.DurationVar(
+		&options.SessionIdleTTL,
+		"session.idle-ttl",
+		10*time.Minute,
+		"How long session stays valid after consumer was seen last time",
+	)
+	flags.BoolVar(
+		&options.SessionPersist,
+		"session.persist",
+		true,
+		"Keep sessions in runtime directory, so that consumers stay connected after restart",
+	)
+	flags.IntVar(
+		&options.SessionMax,
+		"session.max",
+		0,
+		"Maximum number of concurrent sessions each service serves, 0 means no limit",
+	)
+	flags.IntVar(
+		&options.SessionMaxPerConsumer,
+		"session.max-per-consumer",
+		5,
+		"Maximum number of concurrent sessions of one consumer, 0 means no limit",
+	)
+	flags.IntVar(
+		&options.SessionCreateRate,
+		"session.create-rate",
+		10,
+		"Maximum number of sessions one consumer may create per minute, 0 means no limit",
+	)
+
 	err = flags.Parse(args[1:])
 	if err != nil {
 		return
 	}
 
+	options.Services, err = service.ParseSpecs(services)
+	if err != nil {
+		return
+	}
+
+	options.PaymentPolicies, err = client_promise.ParsePolicies(paymentPolicies)
 	return options, err
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/mysterium/node/ip"
 	"github.com/mysterium/node/nat"
 	"github.com/mysterium/node/server"
+	"github.com/mysterium/node/service"
 	"os"
 	"sync"
 )
@@ -24,6 +25,7 @@ func main() {
 		command_server.CommandOptions{
 			DirectoryConfig:  NodeDirectoryConfig,
 			DirectoryRuntime: ClientDirectoryRuntime,
+			Services:         []service.Spec{{ServiceType: "openvpn"}},
 		},
 		mysteriumClient,
 		ip.NewFakeResolver(NodeIP),
@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	openvpn_service "github.com/mysterium/node/openvpn/service"
+	socks5_service "github.com/mysterium/node/socks5/service"
+)
+
+// BootstrapServices registers plugins of all service types, which node is able to provide and consume
+func BootstrapServices() {
+	openvpn_service.Bootstrap()
+	socks5_service.Bootstrap()
+}
@@ -1,6 +1,7 @@
 package communication
 
 import (
+	"context"
 	"github.com/mysterium/node/identity"
 	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 )
@@ -24,7 +25,7 @@ type DialogHandler interface {
 //   - initiates Dialog requests to network
 //   - creates Dialog, when it is negotiated
 type DialogEstablisher interface {
-	CreateDialog(peerID identity.Identity, peerContact dto_discovery.Contact) (Dialog, error)
+	CreateDialog(ctx context.Context, peerID identity.Identity, peerContact dto_discovery.Contact) (Dialog, error)
 }
 
 // Dialog represent established connection between 2 peers in network.
@@ -49,5 +50,5 @@ type Receiver interface {
 //   - sending and HTTP-like request and waiting for response
 type Sender interface {
 	Send(producer MessageProducer) error
-	Request(producer RequestProducer) (responsePtr interface{}, err error)
+	Request(ctx context.Context, producer RequestProducer) (responsePtr interface{}, err error)
 }
@@ -2,6 +2,7 @@ package dialog
 
 import (
 	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/communication/nats/discovery"
 	"github.com/mysterium/node/identity"
 )
 
@@ -9,9 +10,14 @@ type dialog struct {
 	communication.Sender
 	communication.Receiver
 	peerID identity.Identity
+	// peerAddress is owned by dialog only when dialog was established by this side
+	peerAddress *discovery.AddressNATS
 }
 
 func (dialog *dialog) Close() error {
+	if dialog.peerAddress != nil {
+		dialog.peerAddress.Disconnect()
+	}
 	return nil
 }
 
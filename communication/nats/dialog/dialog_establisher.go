@@ -1,6 +1,7 @@
 package dialog
 
 import (
+	"context"
 	"fmt"
 	log "github.com/cihub/seelog"
 	"github.com/mysterium/node/communication"
@@ -36,33 +37,33 @@ type dialogEstablisher struct {
 }
 
 func (establisher *dialogEstablisher) CreateDialog(
+	ctx context.Context,
 	peerID identity.Identity,
 	peerContact dto_discovery.Contact,
 ) (communication.Dialog, error) {
-	var dialog *dialog
-
 	log.Info(establisherLogPrefix, fmt.Sprintf("Connecting to: %#v", peerContact))
 	peerAddress, err := establisher.peerAddressFactory(peerContact)
 	if err != nil {
-		return dialog, fmt.Errorf("failed to connect to: %#v. %s", peerContact, err)
+		return nil, fmt.Errorf("failed to connect to: %#v. %s", peerContact, err)
 	}
 
 	peerCodec := establisher.newCodecForPeer(peerID)
 
 	peerSender := establisher.newSenderToPeer(peerAddress, peerCodec)
-	err = establisher.negotiateDialog(peerSender)
+	err = establisher.negotiateDialog(ctx, peerSender)
 	if err != nil {
-		return dialog, err
+		peerAddress.Disconnect()
+		return nil, err
 	}
 
-	dialog = establisher.newDialogToPeer(peerID, peerAddress, peerCodec)
+	dialog := establisher.newDialogToPeer(peerID, peerAddress, peerCodec)
 	log.Info(establisherLogPrefix, fmt.Sprintf("Dialog established with: %#v", peerContact))
 
 	return dialog, nil
 }
 
-func (establisher *dialogEstablisher) negotiateDialog(sender communication.Sender) error {
-	response, err := sender.Request(&dialogCreateProducer{
+func (establisher *dialogEstablisher) negotiateDialog(ctx context.Context, sender communication.Sender) error {
+	response, err := sender.Request(ctx, &dialogCreateProducer{
 		&dialogCreateRequest{
 			PeerID: establisher.myID.Address,
 		},
@@ -106,8 +107,9 @@ func (establisher *dialogEstablisher) newDialogToPeer(
 
 	subTopic := peerAddress.GetTopic() + "." + establisher.myID.Address
 	return &dialog{
-		peerID:   peerID,
-		Sender:   nats.NewSender(peerAddress.GetConnection(), peerCodec, subTopic),
-		Receiver: nats.NewReceiver(peerAddress.GetConnection(), peerCodec, subTopic),
+		peerID:      peerID,
+		peerAddress: peerAddress,
+		Sender:      nats.NewSender(peerAddress.GetConnection(), peerCodec, subTopic),
+		Receiver:    nats.NewReceiver(peerAddress.GetConnection(), peerCodec, subTopic),
 	}
 }
@@ -1,6 +1,7 @@
 package dialog
 
 import (
+	"context"
 	"github.com/mysterium/node/communication"
 	"github.com/mysterium/node/communication/nats"
 	"github.com/mysterium/node/communication/nats/discovery"
@@ -41,7 +42,7 @@ func TestDialogEstablisher_CreateDialog(t *testing.T) {
 	signer := &identity.SignerFake{}
 	establisher := mockEstablisher(myID, connection, signer)
 
-	dialogInstance, err := establisher.CreateDialog(peerID, dto_discovery.Contact{})
+	dialogInstance, err := establisher.CreateDialog(context.Background(), peerID, dto_discovery.Contact{})
 	defer dialogInstance.Close()
 	assert.NoError(t, err)
 	assert.NotNil(t, dialogInstance)
@@ -78,8 +79,7 @@ func TestDialogEstablisher_CreateDialogWhenResponseHijacked(t *testing.T) {
 
 	establisher := mockEstablisher(myID, connection, &identity.SignerFake{})
 
-	dialogInstance, err := establisher.CreateDialog(peerID, dto_discovery.Contact{})
-	defer dialogInstance.Close()
+	dialogInstance, err := establisher.CreateDialog(context.Background(), peerID, dto_discovery.Contact{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dialog creation error. failed to unpack response 'peer-topic.dialog-create'. invalid message signature ")
 	assert.Nil(t, dialogInstance)
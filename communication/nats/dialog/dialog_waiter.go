@@ -2,6 +2,7 @@ package dialog
 
 import (
 	"fmt"
+	"github.com/mysterium/node/access"
 	"github.com/mysterium/node/communication"
 
 	log "github.com/cihub/seelog"
@@ -12,20 +13,23 @@ import (
 )
 
 // NewDialogWaiter constructs new DialogWaiter which works thru NATS connection.
-func NewDialogWaiter(address *discovery.AddressNATS, signer identity.Signer) *dialogWaiter {
+// Dialogs are accepted only from peers allowed by access policy.
+func NewDialogWaiter(address *discovery.AddressNATS, signer identity.Signer, accessPolicy access.Policy) *dialogWaiter {
 	return &dialogWaiter{
-		myAddress: address,
-		mySigner:  signer,
-		dialogs:   make([]communication.Dialog, 0),
+		myAddress:    address,
+		mySigner:     signer,
+		accessPolicy: accessPolicy,
+		dialogs:      make([]communication.Dialog, 0),
 	}
 }
 
 const waiterLogPrefix = "[NATS.DialogWaiter] "
 
 type dialogWaiter struct {
-	myAddress *discovery.AddressNATS
-	mySigner  identity.Signer
-	dialogs   []communication.Dialog
+	myAddress    *discovery.AddressNATS
+	mySigner     identity.Signer
+	accessPolicy access.Policy
+	dialogs      []communication.Dialog
 }
 
 func (waiter *dialogWaiter) Start() (dto_discovery.Contact, error) {
@@ -54,6 +58,9 @@ func (waiter *dialogWaiter) ServeDialogs(dialogHandler communication.DialogHandl
 			return &responseInvalidIdentity, nil
 		}
 		peerID := identity.FromAddress(request.PeerID)
+		if !waiter.accessPolicy.Allows(peerID) {
+			return &responseAccessDenied, nil
+		}
 
 		dialog := waiter.newDialogToPeer(peerID, waiter.newCodecForPeer(peerID))
 		err := dialogHandler.Handle(dialog)
@@ -2,6 +2,7 @@ package dialog
 
 import (
 	"errors"
+	"github.com/mysterium/node/access"
 	"github.com/mysterium/node/communication"
 	"github.com/mysterium/node/communication/nats"
 	"github.com/mysterium/node/communication/nats/discovery"
@@ -19,10 +20,13 @@ func TestDialogWaiter_Factory(t *testing.T) {
 	address := discovery.NewAddress("custom", "nats://far-server:4222")
 	signer := &identity.SignerFake{}
 
-	waiter := NewDialogWaiter(address, signer)
+	accessPolicy := access.NewPolicyFake()
+
+	waiter := NewDialogWaiter(address, signer, accessPolicy)
 	assert.NotNil(t, waiter)
 	assert.Equal(t, address, waiter.myAddress)
 	assert.Equal(t, signer, waiter.mySigner)
+	assert.Equal(t, accessPolicy, waiter.accessPolicy)
 }
 
 func TestDialogWaiter_ServeDialogs(t *testing.T) {
@@ -32,7 +36,7 @@ func TestDialogWaiter_ServeDialogs(t *testing.T) {
 	defer connection.Close()
 
 	signer := &identity.SignerFake{}
-	waiter, handler := dialogServe(connection, signer)
+	waiter, handler := dialogServe(connection, signer, access.NewPolicyFake())
 	defer waiter.Stop()
 
 	dialogAsk(connection, `{
@@ -65,7 +69,7 @@ func TestDialogWaiter_ServeDialogsRejectInvalidSignature(t *testing.T) {
 	defer connection.Close()
 
 	signer := &identity.SignerFake{}
-	waiter, handler := dialogServe(connection, signer)
+	waiter, handler := dialogServe(connection, signer, access.NewPolicyFake())
 	defer waiter.Stop()
 
 	dialogAsk(connection, `{
@@ -77,11 +81,35 @@ func TestDialogWaiter_ServeDialogsRejectInvalidSignature(t *testing.T) {
 	assert.Nil(t, dialogInstance)
 }
 
-func dialogServe(connection nats.Connection, mySigner identity.Signer) (waiter *dialogWaiter, handler *dialogHandler) {
+func TestDialogWaiter_ServeDialogsRejectDeniedPeer(t *testing.T) {
+	connection := nats.StartConnectionFake()
+	defer connection.Close()
+
+	signer := &identity.SignerFake{}
+	accessPolicy := access.NewPolicyFake(identity.FromAddress("0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea"))
+	waiter, handler := dialogServe(connection, signer, accessPolicy)
+	defer waiter.Stop()
+
+	dialogAsk(connection, `{
+		"payload": {"peer_id":"0x28bf83df144ab7a566bc8509d1fff5d5470bd4ea"},
+		"signature": "tl+WbYkJdXD5foaIP3bqVGFHfr6kdd5FzmJAmu1GdpINEnNR3bTto6wgEoke/Fpy4zsWOjrulDVfrc32f5ArTgA="
+	}`)
+	dialogInstance, err := dialogWait(handler)
+	assert.EqualError(t, err, "dialog not received")
+	assert.Nil(t, dialogInstance)
+	assert.Equal(t, uint64(1), accessPolicy.Rejections())
+}
+
+func dialogServe(
+	connection nats.Connection,
+	mySigner identity.Signer,
+	accessPolicy access.Policy,
+) (waiter *dialogWaiter, handler *dialogHandler) {
 	myTopic := "my-topic"
 	waiter = &dialogWaiter{
-		myAddress: discovery.NewAddressWithConnection(connection, myTopic),
-		mySigner:  mySigner,
+		myAddress:    discovery.NewAddressWithConnection(connection, myTopic),
+		mySigner:     mySigner,
+		accessPolicy: accessPolicy,
 	}
 	handler = &dialogHandler{
 		dialogReceived: make(chan communication.Dialog),
@@ -10,6 +10,7 @@ const endpointDialogCreate = communication.RequestEndpoint("dialog-create")
 var (
 	responseOK              = dialogCreateResponse{200, "OK"}
 	responseInvalidIdentity = dialogCreateResponse{400, "Invalid identity"}
+	responseAccessDenied    = dialogCreateResponse{403, "Access denied"}
 	responseInternalError   = dialogCreateResponse{500, "Failed to create dialog"}
 )
 
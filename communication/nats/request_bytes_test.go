@@ -1,6 +1,7 @@
 package nats
 
 import (
+	"context"
 	"github.com/mysterium/node/communication"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -35,7 +36,7 @@ func TestBytesRequest(t *testing.T) {
 		timeoutRequest: time.Millisecond,
 	}
 
-	response, err := sender.Request(&bytesRequestProducer{
+	response, err := sender.Request(context.Background(), &bytesRequestProducer{
 		[]byte("REQUEST"),
 	})
 	assert.NoError(t, err)
@@ -43,6 +44,25 @@ func TestBytesRequest(t *testing.T) {
 	assert.Equal(t, []byte("RESPONSE"), *response.(*[]byte))
 }
 
+func TestBytesRequestIsAbortedWhenContextIsCancelled(t *testing.T) {
+	connection := StartConnectionFake()
+	defer connection.Close()
+
+	sender := &senderNATS{
+		connection:     connection,
+		codec:          communication.NewCodecBytes(),
+		timeoutRequest: time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := sender.Request(ctx, &bytesRequestProducer{
+		[]byte("REQUEST"),
+	})
+	assert.EqualError(t, err, "failed to send request 'bytes-request'. context canceled")
+}
+
 type bytesRequestConsumer struct {
 	requestReceived interface{}
 }
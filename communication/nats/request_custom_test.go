@@ -1,6 +1,7 @@
 package nats
 
 import (
+	"context"
 	"github.com/mysterium/node/communication"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -42,7 +43,7 @@ func TestCustomRequest(t *testing.T) {
 		timeoutRequest: 100 * time.Millisecond,
 	}
 
-	response, err := sender.Request(&customRequestProducer{
+	response, err := sender.Request(context.Background(), &customRequestProducer{
 		&customRequest{"REQUEST"},
 	})
 	assert.NoError(t, err)
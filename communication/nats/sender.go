@@ -1,7 +1,9 @@
 package nats
 
 import (
+	"context"
 	"github.com/mysterium/node/communication"
+	"github.com/nats-io/go-nats"
 	"time"
 
 	"fmt"
@@ -49,7 +51,7 @@ func (sender *senderNATS) Send(producer communication.MessageProducer) error {
 	return nil
 }
 
-func (sender *senderNATS) Request(producer communication.RequestProducer) (responsePtr interface{}, err error) {
+func (sender *senderNATS) Request(ctx context.Context, producer communication.RequestProducer) (responsePtr interface{}, err error) {
 
 	requestTopic := sender.messageTopic + string(producer.GetRequestEndpoint())
 	responsePtr = producer.NewResponse()
@@ -61,7 +63,7 @@ func (sender *senderNATS) Request(producer communication.RequestProducer) (respo
 	}
 
 	log.Debug(senderLogPrefix, fmt.Sprintf("Request '%s' sending: %s", requestTopic, requestData))
-	msg, err := sender.connection.Request(requestTopic, requestData, sender.timeoutRequest)
+	msg, err := sender.request(ctx, requestTopic, requestData)
 	if err != nil {
 		err = fmt.Errorf("failed to send request '%s'. %s", requestTopic, err)
 		return
@@ -77,3 +79,24 @@ func (sender *senderNATS) Request(producer communication.RequestProducer) (respo
 
 	return responsePtr, nil
 }
+
+// request waits for response to given request, but gives up as soon as context is done
+func (sender *senderNATS) request(ctx context.Context, topic string, data []byte) (*nats.Msg, error) {
+	type requestResult struct {
+		msg *nats.Msg
+		err error
+	}
+
+	resultChannel := make(chan requestResult, 1)
+	go func() {
+		msg, err := sender.connection.Request(topic, data, sender.timeoutRequest)
+		resultChannel <- requestResult{msg, err}
+	}()
+
+	select {
+	case result := <-resultChannel:
+		return result.msg, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,32 @@
+package dns
+
+func NewConfiguratorFake(servers ...string) *configuratorFake {
+	return &configuratorFake{
+		Servers: servers,
+	}
+}
+
+type configuratorFake struct {
+	Device        string
+	Servers       []string
+	backupServers []string
+}
+
+func (configurator *configuratorFake) Configure(device string, servers []string) error {
+	if configurator.Device == "" {
+		configurator.backupServers = configurator.Servers
+	}
+	configurator.Device = device
+	configurator.Servers = servers
+	return nil
+}
+
+func (configurator *configuratorFake) Restore() error {
+	configurator.Device = ""
+	configurator.Servers = configurator.backupServers
+	return nil
+}
+
+func (configurator *configuratorFake) CurrentServers() ([]string, error) {
+	return configurator.Servers, nil
+}
@@ -0,0 +1,109 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+const DNSLogPrefix = "[dns] "
+
+const resolvConfPath = "/etc/resolv.conf"
+
+// backupSuffix is appended to resolv.conf path to get the path where original configuration is kept
+const backupSuffix = ".mysterium-backup"
+
+// NewConfiguratorResolvConf creates configurator which rewrites given resolv.conf file
+func NewConfiguratorResolvConf(path string) *configuratorResolvConf {
+	return &configuratorResolvConf{
+		path:       path,
+		backupPath: path + backupSuffix,
+	}
+}
+
+type configuratorResolvConf struct {
+	path       string
+	backupPath string
+	// noOriginal tells that there was no configuration before, so generated one is removed on restore
+	noOriginal bool
+}
+
+func (configurator *configuratorResolvConf) Configure(device string, servers []string) error {
+	if err := configurator.backup(); err != nil {
+		return err
+	}
+
+	content := bytes.NewBufferString("# Generated by mysterium node, original configuration is kept in " + configurator.backupPath + "\n")
+	for _, server := range servers {
+		fmt.Fprintf(content, "nameserver %s\n", server)
+	}
+
+	os.Remove(configurator.path)
+	if err := ioutil.WriteFile(configurator.path, content.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	log.Info(DNSLogPrefix, "Name servers configured in ", configurator.path, ": ", servers)
+	return nil
+}
+
+func (configurator *configuratorResolvConf) Restore() error {
+	if configurator.noOriginal {
+		if err := os.Remove(configurator.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		configurator.noOriginal = false
+
+		log.Info(DNSLogPrefix, "Name servers removed from ", configurator.path)
+		return nil
+	}
+
+	if _, err := os.Lstat(configurator.backupPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := os.Rename(configurator.backupPath, configurator.path); err != nil {
+		return err
+	}
+
+	log.Info(DNSLogPrefix, "Name servers restored in ", configurator.path)
+	return nil
+}
+
+func (configurator *configuratorResolvConf) CurrentServers() ([]string, error) {
+	content, err := ioutil.ReadFile(configurator.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseResolvConf(content), nil
+}
+
+// backup moves original configuration aside, unless it was done already and was not restored after e.g. crash
+func (configurator *configuratorResolvConf) backup() error {
+	if _, err := os.Lstat(configurator.backupPath); err == nil || configurator.noOriginal {
+		return nil
+	}
+	if _, err := os.Lstat(configurator.path); os.IsNotExist(err) {
+		configurator.noOriginal = true
+		return nil
+	}
+	return os.Rename(configurator.path, configurator.backupPath)
+}
+
+func parseResolvConf(content []byte) []string {
+	servers := make([]string, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			servers = append(servers, fields[1])
+		}
+	}
+	return servers
+}
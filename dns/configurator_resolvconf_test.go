@@ -0,0 +1,109 @@
+package dns
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const originalResolvConf = "# local resolver\nsearch lan\nnameserver 192.168.1.1\nnameserver 8.8.8.8\n"
+
+func createResolvConf(t *testing.T) (string, func()) {
+	directory, err := ioutil.TempDir("", "dns-test-")
+	assert.NoError(t, err)
+
+	path := filepath.Join(directory, "resolv.conf")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(originalResolvConf), 0644))
+
+	return path, func() { os.RemoveAll(directory) }
+}
+
+func TestConfiguratorResolvConf_CurrentServers(t *testing.T) {
+	path, cleanup := createResolvConf(t)
+	defer cleanup()
+
+	servers, err := NewConfiguratorResolvConf(path).CurrentServers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.1.1", "8.8.8.8"}, servers)
+}
+
+func TestConfiguratorResolvConf_ConfigureAndRestore(t *testing.T) {
+	path, cleanup := createResolvConf(t)
+	defer cleanup()
+	configurator := NewConfiguratorResolvConf(path)
+
+	assert.NoError(t, configurator.Configure("tun0", []string{"10.8.0.1", "10.8.0.2"}))
+	servers, err := configurator.CurrentServers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.8.0.1", "10.8.0.2"}, servers)
+
+	assert.NoError(t, configurator.Restore())
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, originalResolvConf, string(content))
+
+	_, err = os.Lstat(path + backupSuffix)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConfiguratorResolvConf_ReconfigureKeepsOriginalBackup(t *testing.T) {
+	path, cleanup := createResolvConf(t)
+	defer cleanup()
+	configurator := NewConfiguratorResolvConf(path)
+
+	assert.NoError(t, configurator.Configure("tun0", []string{"10.8.0.1"}))
+	assert.NoError(t, configurator.Configure("tun0", []string{"10.9.0.1"}))
+	assert.NoError(t, configurator.Restore())
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, originalResolvConf, string(content))
+}
+
+func TestConfiguratorResolvConf_ConfigureReplacesSymlink(t *testing.T) {
+	path, cleanup := createResolvConf(t)
+	defer cleanup()
+
+	stubPath := path + ".stub"
+	assert.NoError(t, os.Rename(path, stubPath))
+	assert.NoError(t, os.Symlink(stubPath, path))
+	configurator := NewConfiguratorResolvConf(path)
+
+	assert.NoError(t, configurator.Configure("tun0", []string{"10.8.0.1"}))
+	content, err := ioutil.ReadFile(stubPath)
+	assert.NoError(t, err)
+	assert.Equal(t, originalResolvConf, string(content))
+
+	assert.NoError(t, configurator.Restore())
+	target, err := os.Readlink(path)
+	assert.NoError(t, err)
+	assert.Equal(t, stubPath, target)
+}
+
+func TestConfiguratorResolvConf_RestoreRemovesConfigurationWhenThereWasNoOriginal(t *testing.T) {
+	path, cleanup := createResolvConf(t)
+	defer cleanup()
+	assert.NoError(t, os.Remove(path))
+	configurator := NewConfiguratorResolvConf(path)
+
+	assert.NoError(t, configurator.Configure("tun0", []string{"10.8.0.1"}))
+	assert.NoError(t, configurator.Configure("tun0", []string{"10.9.0.1"}))
+	_, err := os.Lstat(path + backupSuffix)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, configurator.Restore())
+	_, err = os.Lstat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestConfiguratorResolvConf_RestoreWithoutBackupDoesNothing(t *testing.T) {
+	path, cleanup := createResolvConf(t)
+	defer cleanup()
+
+	assert.NoError(t, NewConfiguratorResolvConf(path).Restore())
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, originalResolvConf, string(content))
+}
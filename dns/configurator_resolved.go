@@ -0,0 +1,130 @@
+package dns
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+// routeAllDomains is systemd-resolved routing domain which routes queries of all domains to the link
+const routeAllDomains = "~."
+
+// configuratorResolved configures name servers of tunnel link through systemd-resolved
+type configuratorResolved struct {
+	device string
+}
+
+func (configurator *configuratorResolved) Configure(device string, servers []string) error {
+	if err := resolvectl(append([]string{"dns", device}, servers...)...); err != nil {
+		return err
+	}
+	if err := resolvectl("domain", device, routeAllDomains); err != nil {
+		return err
+	}
+	configurator.device = device
+
+	log.Info(DNSLogPrefix, "Name servers configured for link ", device, ": ", servers)
+	return nil
+}
+
+func (configurator *configuratorResolved) Restore() error {
+	device := configurator.device
+	configurator.device = ""
+	if device == "" {
+		return nil
+	}
+
+	// Configuration is forgotten together with the link, when tunnel is already gone
+	if _, err := net.InterfaceByName(device); err != nil {
+		return nil
+	}
+
+	if err := resolvectl("revert", device); err != nil {
+		return err
+	}
+
+	log.Info(DNSLogPrefix, "Name servers restored for link ", device)
+	return nil
+}
+
+func (configurator *configuratorResolved) CurrentServers() ([]string, error) {
+	linkServers, err := resolvectlOutput("dns")
+	if err != nil {
+		return nil, err
+	}
+	linkDomains, err := resolvectlOutput("domain")
+	if err != nil {
+		return nil, err
+	}
+	return resolvedServers(linkServers, linkDomains), nil
+}
+
+// resolvedServers picks servers of links which all queries are routed to, or servers of every link when there are none
+func resolvedServers(linkServers, linkDomains map[string][]string) []string {
+	servers := make([]string, 0)
+	for link, domains := range linkDomains {
+		if contains(domains, routeAllDomains) {
+			servers = append(servers, linkServers[link]...)
+		}
+	}
+	if len(servers) > 0 {
+		return servers
+	}
+
+	for _, values := range linkServers {
+		servers = append(servers, values...)
+	}
+	return servers
+}
+
+// parseResolvectlOutput parses lines of form "Link 2 (eth0): value1 value2" to values of every link
+func parseResolvectlOutput(output []byte) map[string][]string {
+	links := make(map[string][]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		link := strings.TrimSpace(parts[0])
+		links[link] = append(links[link], strings.Fields(parts[1])...)
+	}
+	return links
+}
+
+func resolvectl(args ...string) error {
+	_, err := resolvectlCommand(args...)
+	return err
+}
+
+func resolvectlOutput(args ...string) (map[string][]string, error) {
+	output, err := resolvectlCommand(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseResolvectlOutput(output), nil
+}
+
+func resolvectlCommand(args ...string) ([]byte, error) {
+	cmd := exec.Command("resolvectl", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to execute dns command: %s. %s %s", cmd.Args, err.Error(), output)
+	}
+	return output, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package dns
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseResolvectlOutput(t *testing.T) {
+	output := "Global:\nLink 2 (eth0): 192.168.1.1 fe80::1\nLink 5 (tun0): 10.8.0.1\n"
+
+	assert.Equal(
+		t,
+		map[string][]string{
+			"Global":        nil,
+			"Link 2 (eth0)": {"192.168.1.1", "fe80::1"},
+			"Link 5 (tun0)": {"10.8.0.1"},
+		},
+		parseResolvectlOutput([]byte(output)),
+	)
+}
+
+func TestResolvedServers_PrefersLinksRoutingAllDomains(t *testing.T) {
+	linkServers := map[string][]string{
+		"Link 2 (eth0)": {"192.168.1.1"},
+		"Link 5 (tun0)": {"10.8.0.1"},
+	}
+	linkDomains := map[string][]string{
+		"Link 2 (eth0)": {"lan"},
+		"Link 5 (tun0)": {"~."},
+	}
+
+	assert.Equal(t, []string{"10.8.0.1"}, resolvedServers(linkServers, linkDomains))
+}
+
+func TestResolvedServers_ReturnsAllServersWithoutRoutingDomain(t *testing.T) {
+	linkServers := map[string][]string{
+		"Link 2 (eth0)": {"192.168.1.1"},
+	}
+
+	assert.Equal(t, []string{"192.168.1.1"}, resolvedServers(linkServers, map[string][]string{}))
+}
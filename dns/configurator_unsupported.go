@@ -0,0 +1,24 @@
+package dns
+
+import (
+	"errors"
+)
+
+// ErrUnsupported is returned when name servers can not be configured on this platform
+var ErrUnsupported = errors.New("name server configuration is not supported on this platform")
+
+// configuratorUnsupported is used on platforms where name servers can not be configured, so that protection
+// is never reported without name servers being applied
+type configuratorUnsupported struct{}
+
+func (configurator *configuratorUnsupported) Configure(device string, servers []string) error {
+	return ErrUnsupported
+}
+
+func (configurator *configuratorUnsupported) Restore() error {
+	return nil
+}
+
+func (configurator *configuratorUnsupported) CurrentServers() ([]string, error) {
+	return nil, ErrUnsupported
+}
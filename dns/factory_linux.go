@@ -0,0 +1,29 @@
+package dns
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// resolvedStubDirectory holds resolv.conf which is managed by systemd-resolved
+const resolvedStubDirectory = "/run/systemd/resolve/"
+
+func NewConfigurator() Configurator {
+	if usesResolved() {
+		return &configuratorResolved{}
+	}
+	return NewConfiguratorResolvConf(resolvConfPath)
+}
+
+func usesResolved() bool {
+	if _, err := exec.LookPath("resolvectl"); err != nil {
+		return false
+	}
+
+	target, err := os.Readlink(resolvConfPath)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(target, resolvedStubDirectory) || strings.HasPrefix(target, "../"+strings.TrimPrefix(resolvedStubDirectory, "/"))
+}
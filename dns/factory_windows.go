@@ -0,0 +1,5 @@
+package dns
+
+func NewConfigurator() Configurator {
+	return &configuratorUnsupported{}
+}
@@ -0,0 +1,29 @@
+package dns
+
+// Configurator switches name servers which system resolves names through
+type Configurator interface {
+	// Configure makes system use given name servers, previous configuration is kept for Restore
+	Configure(device string, servers []string) error
+	// Restore brings back configuration which was active before Configure
+	Restore() error
+	// CurrentServers lists name servers which system resolves names through at the moment
+	CurrentServers() ([]string, error)
+}
+
+// Protector applies name servers pushed through tunnel and verifies that system uses them
+type Protector interface {
+	Protect(device string, servers []string) error
+	Release() error
+	// Status fails with ErrUnsupported when name servers can not be configured on this platform
+	Status() (Status, error)
+}
+
+// Status describes whether names are resolved only through tunnel name servers
+type Status struct {
+	// Tunnel name servers are configured to be used by system
+	Active        bool
+	TunnelServers []string
+	SystemServers []string
+	// System resolves names only through tunnel name servers
+	Protected bool
+}
@@ -0,0 +1,82 @@
+package dns
+
+import (
+	"sync"
+
+	log "github.com/cihub/seelog"
+)
+
+// NewProtector creates protector which applies tunnel name servers through given configurator
+func NewProtector(configurator Configurator) Protector {
+	return &protector{
+		configurator: configurator,
+	}
+}
+
+type protector struct {
+	configurator Configurator
+
+	mutex         sync.Mutex
+	tunnelServers []string
+}
+
+// Protect makes system resolve names only through name servers of the tunnel.
+// Tunnel stays usable on platforms where name servers can not be configured, it is just not protected.
+func (protector *protector) Protect(device string, servers []string) error {
+	protector.mutex.Lock()
+	defer protector.mutex.Unlock()
+
+	err := protector.configurator.Configure(device, servers)
+	if err == ErrUnsupported {
+		log.Warn(DNSLogPrefix, "Name servers of tunnel are not applied: ", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	protector.tunnelServers = servers
+	return nil
+}
+
+// Release restores name servers which were used before tunnel was established
+func (protector *protector) Release() error {
+	protector.mutex.Lock()
+	defer protector.mutex.Unlock()
+
+	if protector.tunnelServers == nil {
+		return nil
+	}
+	protector.tunnelServers = nil
+	return protector.configurator.Restore()
+}
+
+// Status verifies which name servers system actually uses at the moment
+func (protector *protector) Status() (Status, error) {
+	protector.mutex.Lock()
+	defer protector.mutex.Unlock()
+
+	systemServers, err := protector.configurator.CurrentServers()
+	if err != nil {
+		return Status{}, err
+	}
+
+	return Status{
+		Active:        protector.tunnelServers != nil,
+		TunnelServers: protector.tunnelServers,
+		SystemServers: systemServers,
+		Protected:     protector.tunnelServers != nil && usesOnly(systemServers, protector.tunnelServers),
+	}, nil
+}
+
+// usesOnly checks that there are some used servers and all of them are allowed
+func usesOnly(used, allowed []string) bool {
+	if len(used) == 0 {
+		return false
+	}
+	for _, server := range used {
+		if !contains(allowed, server) {
+			return false
+		}
+	}
+	return true
+}
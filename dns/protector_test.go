@@ -0,0 +1,95 @@
+package dns
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProtector_StatusIsNotActiveInitially(t *testing.T) {
+	protector := NewProtector(NewConfiguratorFake("192.168.1.1"))
+
+	status, err := protector.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, Status{SystemServers: []string{"192.168.1.1"}}, status)
+}
+
+func TestProtector_ProtectConfiguresTunnelServers(t *testing.T) {
+	configurator := NewConfiguratorFake("192.168.1.1")
+	protector := NewProtector(configurator)
+
+	assert.NoError(t, protector.Protect("tun0", []string{"10.8.0.1"}))
+	assert.Equal(t, "tun0", configurator.Device)
+
+	status, err := protector.Status()
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		Status{
+			Active:        true,
+			TunnelServers: []string{"10.8.0.1"},
+			SystemServers: []string{"10.8.0.1"},
+			Protected:     true,
+		},
+		status,
+	)
+}
+
+func TestProtector_StatusReportsLeakWhenSystemUsesOtherServers(t *testing.T) {
+	configurator := NewConfiguratorFake("192.168.1.1")
+	protector := NewProtector(configurator)
+
+	assert.NoError(t, protector.Protect("tun0", []string{"10.8.0.1"}))
+	configurator.Servers = []string{"10.8.0.1", "192.168.1.1"}
+
+	status, err := protector.Status()
+	assert.NoError(t, err)
+	assert.True(t, status.Active)
+	assert.False(t, status.Protected)
+}
+
+func TestProtector_ReleaseRestoresOriginalServers(t *testing.T) {
+	configurator := NewConfiguratorFake("192.168.1.1")
+	protector := NewProtector(configurator)
+
+	assert.NoError(t, protector.Protect("tun0", []string{"10.8.0.1"}))
+	assert.NoError(t, protector.Release())
+
+	status, err := protector.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, Status{SystemServers: []string{"192.168.1.1"}}, status)
+}
+
+func TestProtector_ReleaseWithoutProtectionDoesNothing(t *testing.T) {
+	configurator := NewConfiguratorFake("192.168.1.1")
+	configurator.Device = "eth0"
+	protector := NewProtector(configurator)
+
+	assert.NoError(t, protector.Release())
+	assert.Equal(t, "eth0", configurator.Device)
+}
+
+func TestProtector_StatusReturnsConfiguratorError(t *testing.T) {
+	protector := NewProtector(&failingConfigurator{})
+
+	_, err := protector.Status()
+	assert.EqualError(t, err, "resolver is unavailable")
+}
+
+type failingConfigurator struct {
+	configuratorFake
+}
+
+func (configurator *failingConfigurator) CurrentServers() ([]string, error) {
+	return nil, errors.New("resolver is unavailable")
+}
+
+func TestProtector_UnsupportedPlatformIsNeverProtected(t *testing.T) {
+	protector := NewProtector(&configuratorUnsupported{})
+
+	assert.NoError(t, protector.Protect("tun0", []string{"10.8.0.1"}))
+
+	_, err := protector.Status()
+	assert.Equal(t, ErrUnsupported, err)
+	assert.NoError(t, protector.Release())
+}
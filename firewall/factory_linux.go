@@ -0,0 +1,8 @@
+package firewall
+
+// Supported tells if outgoing traffic can be blocked on this platform
+const Supported = true
+
+func NewService() FirewallService {
+	return &serviceIPTables{}
+}
@@ -0,0 +1,17 @@
+package firewall
+
+// FirewallService blocks all outgoing traffic, except the one explicitly allowed
+type FirewallService interface {
+	Allow(rule RuleAllowed)
+	Start() error
+	// Stop removes blocking and forgets allowed rules
+	Stop() error
+}
+
+// RuleAllowed describes traffic which is let through, empty fields are ignored
+type RuleAllowed struct {
+	// Network interface, "+" suffix matches any interface starting with the name
+	Interface string
+	// Address of remote host
+	RemoteAddress string
+}
@@ -0,0 +1,25 @@
+package firewall
+
+func NewServiceFake() *serviceFake {
+	return &serviceFake{}
+}
+
+type serviceFake struct {
+	Rules   []RuleAllowed
+	Started bool
+}
+
+func (service *serviceFake) Allow(rule RuleAllowed) {
+	service.Rules = append(service.Rules, rule)
+}
+
+func (service *serviceFake) Start() error {
+	service.Started = true
+	return nil
+}
+
+func (service *serviceFake) Stop() error {
+	service.Started = false
+	service.Rules = nil
+	return nil
+}
@@ -0,0 +1,137 @@
+package firewall
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+const FirewallLogPrefix = "[firewall] "
+
+// chainName is iptables chain which holds all rules of the service
+const chainName = "MYSTERIUM-KILLSWITCH"
+
+// ipTables and ip6Tables are commands which manage rules of IPv4 and IPv6 traffic
+const (
+	ipTables  = "iptables"
+	ip6Tables = "ip6tables"
+)
+
+// serviceIPTables blocks both IPv4 and IPv6 traffic, so that it does not leak outside of tunnel through IPv6
+type serviceIPTables struct {
+	rules []RuleAllowed
+}
+
+func (service *serviceIPTables) Allow(rule RuleAllowed) {
+	service.rules = append(service.rules, rule)
+}
+
+// Start blocks traffic of both families, nothing stays blocked and allowed rules are forgotten when it fails
+func (service *serviceIPTables) Start() error {
+	for _, command := range []string{ipTables, ip6Tables} {
+		if err := service.start(command); err != nil {
+			service.clearStaleRules(ipTables)
+			service.clearStaleRules(ip6Tables)
+			service.rules = nil
+			return err
+		}
+	}
+
+	log.Info(FirewallLogPrefix, "Outgoing traffic blocked, except allowed one")
+	return nil
+}
+
+// Stop unblocks traffic of both families even when one of them fails, allowed rules are always forgotten
+func (service *serviceIPTables) Stop() error {
+	var errs []string
+	for _, command := range []string{ipTables, ip6Tables} {
+		if err := service.stop(command); err != nil {
+			service.clearStaleRules(command)
+			errs = append(errs, err.Error())
+		}
+	}
+	service.rules = nil
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	log.Info(FirewallLogPrefix, "Outgoing traffic unblocked")
+	return nil
+}
+
+func (service *serviceIPTables) start(command string) error {
+	service.clearStaleRules(command)
+
+	if err := service.execute(command, "--new-chain", chainName); err != nil {
+		return err
+	}
+	if err := service.enableRules(command); err != nil {
+		return err
+	}
+	return service.execute(command, "--insert", "OUTPUT", "--jump", chainName)
+}
+
+func (service *serviceIPTables) stop(command string) error {
+	if err := service.execute(command, "--delete", "OUTPUT", "--jump", chainName); err != nil {
+		return err
+	}
+	if err := service.execute(command, "--flush", chainName); err != nil {
+		return err
+	}
+	return service.execute(command, "--delete-chain", chainName)
+}
+
+func (service *serviceIPTables) enableRules(command string) error {
+	if err := service.execute(command, "--append", chainName, "--out-interface", "lo", "--jump", "ACCEPT"); err != nil {
+		return err
+	}
+
+	for _, rule := range service.rules {
+		if rule.RemoteAddress != "" && commandOfAddress(rule.RemoteAddress) != command {
+			continue
+		}
+
+		args := []string{"--append", chainName}
+		if rule.Interface != "" {
+			args = append(args, "--out-interface", rule.Interface)
+		}
+		if rule.RemoteAddress != "" {
+			args = append(args, "--destination", rule.RemoteAddress)
+		}
+		args = append(args, "--jump", "ACCEPT")
+
+		if err := service.execute(command, args...); err != nil {
+			return err
+		}
+		log.Info(FirewallLogPrefix, "Allowing traffic to '", rule.RemoteAddress, "' through interface: ", rule.Interface)
+	}
+
+	return service.execute(command, "--append", chainName, "--jump", "REJECT")
+}
+
+// commandOfAddress picks command which manages traffic to given address, host names are resolved by iptables to IPv4
+func commandOfAddress(address string) string {
+	if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+		return ip6Tables
+	}
+	return ipTables
+}
+
+func (service *serviceIPTables) execute(command string, args ...string) error {
+	cmd := exec.Command(command, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("Failed to execute firewall command: %s. %s %s", cmd.Args, err.Error(), output)
+	}
+	return nil
+}
+
+func (service *serviceIPTables) clearStaleRules(command string) {
+	service.execute(command, "--delete", "OUTPUT", "--jump", chainName)
+	service.execute(command, "--flush", chainName)
+	service.execute(command, "--delete-chain", chainName)
+}
@@ -0,0 +1,22 @@
+package firewall
+
+import (
+	"errors"
+)
+
+// ErrUnsupported is returned when outgoing traffic can not be blocked on this platform
+var ErrUnsupported = errors.New("kill switch is not supported on this platform")
+
+// serviceUnsupported refuses to start, so that traffic is never assumed to be blocked when it is not
+type serviceUnsupported struct{}
+
+func (service *serviceUnsupported) Allow(rule RuleAllowed) {
+}
+
+func (service *serviceUnsupported) Start() error {
+	return ErrUnsupported
+}
+
+func (service *serviceUnsupported) Stop() error {
+	return nil
+}
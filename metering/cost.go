@@ -0,0 +1,61 @@
+package metering
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mysterium/node/money"
+	dto_openvpn "github.com/mysterium/node/openvpn/discovery/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"time"
+)
+
+// Usage is amount of service consumed in session
+type Usage struct {
+	Duration time.Duration
+	Bytes    uint64
+}
+
+// Add returns usage increased by other usage
+func (usage Usage) Add(other Usage) Usage {
+	return Usage{
+		Duration: usage.Duration + other.Duration,
+		Bytes:    usage.Bytes + other.Bytes,
+	}
+}
+
+// Unit returns usage which price of payment method is paid for
+func Unit(paymentMethod dto_discovery.PaymentMethod) (Usage, error) {
+	switch method := paymentMethod.(type) {
+	case dto_openvpn.PaymentMethodPerTime:
+		if method.Duration <= 0 {
+			return Usage{}, errors.New("payment method has no duration")
+		}
+		return Usage{Duration: method.Duration}, nil
+	case dto_openvpn.PaymentMethodPerBytes:
+		bytes := uint64(method.Bytes.Bytes())
+		if bytes == 0 {
+			return Usage{}, errors.New("payment method has no bytes")
+		}
+		return Usage{Bytes: bytes}, nil
+	}
+	return Usage{}, fmt.Errorf("unsupported payment method: %T", paymentMethod)
+}
+
+// Cost computes amount owed for usage of service paid by given method. Cost is proportional to usage
+// and its fraction of the smallest money unit is rounded up, so that consumer and provider get the same amount.
+// money.ErrOverflow is returned when cost does not fit into money amount
+func Cost(paymentMethod dto_discovery.PaymentMethod, usage Usage) (money.Money, error) {
+	unit, err := Unit(paymentMethod)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	price := paymentMethod.GetPrice()
+	if unit.Duration > 0 {
+		if usage.Duration < 0 {
+			usage.Duration = 0
+		}
+		return price.MulDuration(usage.Duration, unit.Duration)
+	}
+	return price.MulBytes(usage.Bytes, unit.Bytes)
+}
@@ -0,0 +1,100 @@
+package metering
+
+import (
+	"github.com/mysterium/node/datasize"
+	"github.com/mysterium/node/money"
+	dto_openvpn "github.com/mysterium/node/openvpn/discovery/dto"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	perTime = dto_openvpn.PaymentMethodPerTime{
+		Price:    money.NewMoney(0.125, money.CURRENCY_MYST),
+		Duration: time.Hour,
+	}
+	perBytes = dto_openvpn.PaymentMethodPerBytes{
+		Price: money.NewMoney(0.5, money.CURRENCY_MYST),
+		Bytes: datasize.MB,
+	}
+)
+
+func TestCostIsProportionalToDuration(t *testing.T) {
+	table := []struct {
+		duration time.Duration
+		amount   uint64
+	}{
+		{0, 0},
+		{-time.Minute, 0},
+		{time.Hour, 12500000},
+		{30 * time.Minute, 6250000},
+		{90 * time.Minute, 18750000},
+		{time.Second, 3473},
+		{time.Nanosecond, 1},
+		{1000 * time.Hour, 12500000000},
+	}
+
+	for _, tt := range table {
+		cost, err := Cost(perTime, Usage{Duration: tt.duration, Bytes: 1024})
+		assert.NoError(t, err)
+		assert.Equal(t, money.Money{Amount: tt.amount, Currency: money.CURRENCY_MYST}, cost, tt.duration.String())
+	}
+}
+
+func TestCostIsProportionalToBytes(t *testing.T) {
+	table := []struct {
+		bytes  uint64
+		amount uint64
+	}{
+		{0, 0},
+		{1024 * 1024, 50000000},
+		{512 * 1024, 25000000},
+		{1, 48},
+		{3 * 1024 * 1024, 150000000},
+	}
+
+	for _, tt := range table {
+		cost, err := Cost(perBytes, Usage{Duration: time.Hour, Bytes: tt.bytes})
+		assert.NoError(t, err)
+		assert.Equal(t, money.Money{Amount: tt.amount, Currency: money.CURRENCY_MYST}, cost)
+	}
+}
+
+func TestCostFailsWhenItOverflows(t *testing.T) {
+	method := dto_openvpn.PaymentMethodPerBytes{
+		Price: money.Money{Amount: math.MaxUint64, Currency: money.CURRENCY_MYST},
+		Bytes: datasize.B,
+	}
+
+	cost, err := Cost(method, Usage{Bytes: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), cost.Amount)
+
+	_, err = Cost(method, Usage{Bytes: 2})
+	assert.Equal(t, money.ErrOverflow, err)
+}
+
+func TestCostOfUnsupportedPaymentMethod(t *testing.T) {
+	_, err := Cost(nil, Usage{})
+	assert.EqualError(t, err, "unsupported payment method: <nil>")
+
+	_, err = Cost(dto_openvpn.PaymentMethodPerTime{Price: perTime.Price}, Usage{})
+	assert.EqualError(t, err, "payment method has no duration")
+
+	_, err = Cost(dto_openvpn.PaymentMethodPerBytes{Price: perBytes.Price}, Usage{})
+	assert.EqualError(t, err, "payment method has no bytes")
+}
+
+func TestUnitAndUsageAdd(t *testing.T) {
+	unit, err := Unit(perTime)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Duration: time.Hour}, unit)
+
+	unit, err = Unit(perBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, Usage{Bytes: 1024 * 1024}, unit)
+
+	assert.Equal(t, Usage{Duration: 2 * time.Hour, Bytes: 10}, Usage{Duration: time.Hour, Bytes: 10}.Add(Usage{Duration: time.Hour}))
+}
@@ -0,0 +1,88 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+var (
+	// ErrOverflow is returned when result does not fit into amount of money
+	ErrOverflow = errors.New("money amount overflows")
+	// ErrNegative is returned when result would be negative amount of money
+	ErrNegative = errors.New("money amount is negative")
+)
+
+// CurrencyMismatchError is returned when money of different currencies is combined
+type CurrencyMismatchError struct {
+	Expected Currency
+	Actual   Currency
+}
+
+func (err *CurrencyMismatchError) Error() string {
+	return fmt.Sprintf("currency mismatch: %s and %s", err.Expected, err.Actual)
+}
+
+// Add returns sum of money of the same currency
+func (money Money) Add(other Money) (Money, error) {
+	if err := money.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum := money.Amount + other.Amount
+	if sum < money.Amount {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: sum, Currency: money.Currency}, nil
+}
+
+// Sub returns difference of money of the same currency
+func (money Money) Sub(other Money) (Money, error) {
+	if err := money.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if other.Amount > money.Amount {
+		return Money{}, ErrNegative
+	}
+	return Money{Amount: money.Amount - other.Amount, Currency: money.Currency}, nil
+}
+
+// MulDuration returns price of given duration, when money is price of unit duration. Fraction of the smallest unit
+// is rounded up, so that service is never paid less than it is used
+func (money Money) MulDuration(duration time.Duration, unit time.Duration) (Money, error) {
+	if unit <= 0 {
+		return Money{}, errors.New("unit duration is not positive")
+	}
+	if duration < 0 {
+		return Money{}, ErrNegative
+	}
+	return money.mulDiv(uint64(duration), uint64(unit))
+}
+
+// MulBytes returns price of given bytes, when money is price of unit bytes. Fraction of the smallest unit
+// is rounded up, so that service is never paid less than it is used
+func (money Money) MulBytes(bytes uint64, unit uint64) (Money, error) {
+	if unit == 0 {
+		return Money{}, errors.New("unit bytes is zero")
+	}
+	return money.mulDiv(bytes, unit)
+}
+
+// mulDiv multiplies amount by given ratio exactly and rounds result up
+func (money Money) mulDiv(multiplier, divisor uint64) (Money, error) {
+	amount := new(big.Int).SetUint64(money.Amount)
+	amount.Mul(amount, new(big.Int).SetUint64(multiplier))
+	amount.Add(amount, new(big.Int).SetUint64(divisor-1))
+	amount.Quo(amount, new(big.Int).SetUint64(divisor))
+	if !amount.IsUint64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: amount.Uint64(), Currency: money.Currency}, nil
+}
+
+func (money Money) checkCurrency(other Money) error {
+	if money.Currency != other.Currency {
+		return &CurrencyMismatchError{Expected: money.Currency, Actual: other.Currency}
+	}
+	return nil
+}
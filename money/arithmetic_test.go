@@ -0,0 +1,87 @@
+package money
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func myst(amount uint64) Money {
+	return Money{Amount: amount, Currency: CURRENCY_MYST}
+}
+
+func TestAddAndSub(t *testing.T) {
+	sum, err := myst(12500000).Add(myst(7500000))
+	assert.NoError(t, err)
+	assert.Equal(t, myst(20000000), sum)
+
+	difference, err := sum.Sub(myst(20000000))
+	assert.NoError(t, err)
+	assert.Equal(t, myst(0), difference)
+}
+
+func TestAddAndSubDetectOverflow(t *testing.T) {
+	_, err := myst(math.MaxUint64).Add(myst(1))
+	assert.Equal(t, ErrOverflow, err)
+
+	_, err = myst(1).Sub(myst(2))
+	assert.Equal(t, ErrNegative, err)
+}
+
+func TestAddAndSubRejectOtherCurrency(t *testing.T) {
+	other := Money{Amount: 1, Currency: "Token"}
+
+	_, err := myst(1).Add(other)
+	assert.EqualError(t, err, "currency mismatch: MYST and Token")
+	assert.Equal(t, &CurrencyMismatchError{Expected: CURRENCY_MYST, Actual: "Token"}, err)
+
+	_, err = myst(1).Sub(other)
+	assert.EqualError(t, err, "currency mismatch: MYST and Token")
+}
+
+func TestMulDuration(t *testing.T) {
+	table := []struct {
+		duration time.Duration
+		amount   uint64
+	}{
+		{0, 0},
+		{time.Hour, 12500000},
+		{90 * time.Minute, 18750000},
+		{time.Second, 3473},
+		{time.Nanosecond, 1},
+		{1000 * time.Hour, 12500000000},
+	}
+
+	for _, tt := range table {
+		cost, err := myst(12500000).MulDuration(tt.duration, time.Hour)
+		assert.NoError(t, err)
+		assert.Equal(t, myst(tt.amount), cost, tt.duration.String())
+	}
+
+	_, err := myst(1).MulDuration(-time.Second, time.Hour)
+	assert.Equal(t, ErrNegative, err)
+
+	_, err = myst(1).MulDuration(time.Second, 0)
+	assert.EqualError(t, err, "unit duration is not positive")
+}
+
+func TestMulBytes(t *testing.T) {
+	cost, err := myst(50000000).MulBytes(1, 1024*1024)
+	assert.NoError(t, err)
+	assert.Equal(t, myst(48), cost)
+
+	cost, err = myst(50000000).MulBytes(3*1024*1024, 1024*1024)
+	assert.NoError(t, err)
+	assert.Equal(t, myst(150000000), cost)
+
+	cost, err = myst(math.MaxUint64).MulBytes(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, myst(math.MaxUint64), cost)
+
+	_, err = myst(math.MaxUint64).MulBytes(2, 1)
+	assert.Equal(t, ErrOverflow, err)
+
+	_, err = myst(1).MulBytes(1, 0)
+	assert.EqualError(t, err, "unit bytes is zero")
+}
@@ -0,0 +1,109 @@
+package money
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	amountRule = regexp.MustCompile(`^(\d+)(?:\.(\d{1,8}))?$`)
+	moneyRule  = regexp.MustCompile(`^(\S+)(?:\s+(\S+))?$`)
+)
+
+// String formats money as coins followed by currency e.g. "0.125 MYST", trailing zeros of fraction are omitted
+func (money Money) String() string {
+	amount := FormatAmount(money.Amount)
+	if money.Currency == "" {
+		return amount
+	}
+	return amount + " " + string(money.Currency)
+}
+
+// FormatAmount formats amount of the smallest units as coins e.g. "0.125"
+func FormatAmount(amount uint64) string {
+	coins := amount / unitsPerCoin
+	fraction := amount % unitsPerCoin
+	if fraction == 0 {
+		return strconv.FormatUint(coins, 10)
+	}
+	return strings.TrimRight(fmt.Sprintf("%d.%08d", coins, fraction), "0")
+}
+
+// ParseAmount parses coins with up to 8 decimal digits e.g. "0.125" into amount of the smallest units
+func ParseAmount(value string) (uint64, error) {
+	match := amountRule.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid money amount %q", value)
+	}
+
+	coins, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil || coins > math.MaxUint64/unitsPerCoin {
+		return 0, ErrOverflow
+	}
+	fraction, _ := strconv.ParseUint(match[2]+strings.Repeat("0", Decimals-len(match[2])), 10, 64)
+
+	amount := coins*unitsPerCoin + fraction
+	if amount < coins*unitsPerCoin {
+		return 0, ErrOverflow
+	}
+	return amount, nil
+}
+
+// ParseMoney parses coins followed by optional currency e.g. "0.125 MYST" or "0.125", formatted by String
+func ParseMoney(value string) (Money, error) {
+	match := moneyRule.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return Money{}, fmt.Errorf("invalid money %q, expected amount and optional currency", value)
+	}
+
+	amount, err := ParseAmount(match[1])
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: Currency(match[2])}, nil
+}
+
+// MarshalText formats money as String does
+func (money Money) MarshalText() ([]byte, error) {
+	return []byte(money.String()), nil
+}
+
+// UnmarshalText parses money formatted by MarshalText
+func (money *Money) UnmarshalText(text []byte) error {
+	parsed, err := ParseMoney(string(text))
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
+
+// moneyJSON is JSON form of money, it keeps amount in the smallest units so that it is exact
+type moneyJSON struct {
+	Amount   uint64   `json:"amount,omitempty"`
+	Currency Currency `json:"currency,omitempty"`
+}
+
+// MarshalJSON keeps JSON form of money an object with amount of the smallest units, instead of text
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON(money))
+}
+
+// UnmarshalJSON parses object with amount of the smallest units, or text formatted by MarshalText
+func (money *Money) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return money.UnmarshalText([]byte(text))
+	}
+
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*money = Money(value)
+	return nil
+}
@@ -0,0 +1,133 @@
+package money
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestStringAndParseMoney(t *testing.T) {
+	table := []struct {
+		money Money
+		text  string
+	}{
+		{myst(12500000), "0.125 MYST"},
+		{myst(0), "0 MYST"},
+		{myst(100000000), "1 MYST"},
+		{myst(1), "0.00000001 MYST"},
+		{myst(1234500000000), "12345 MYST"},
+		{myst(18446744073709551615), "184467440737.09551615 MYST"},
+		{Money{Amount: 5}, "0.00000005"},
+		{Money{}, "0"},
+	}
+
+	for _, tt := range table {
+		assert.Equal(t, tt.text, tt.money.String())
+
+		parsed, err := ParseMoney(tt.text)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.money, parsed)
+	}
+}
+
+func TestParseMoneyWithInvalidValue(t *testing.T) {
+	_, err := ParseMoney("0.125 MYST extra")
+	assert.EqualError(t, err, `invalid money "0.125 MYST extra", expected amount and optional currency`)
+
+	_, err = ParseMoney("0.123456789 MYST")
+	assert.EqualError(t, err, `invalid money amount "0.123456789"`)
+
+	_, err = ParseMoney("-1 MYST")
+	assert.EqualError(t, err, `invalid money amount "-1"`)
+
+	_, err = ParseMoney("184467440737.09551616 MYST")
+	assert.Equal(t, ErrOverflow, err)
+
+	_, err = ParseMoney("184467440738 MYST")
+	assert.Equal(t, ErrOverflow, err)
+}
+
+func TestParseAmount(t *testing.T) {
+	amount, err := ParseAmount("0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20000000), amount)
+
+	amount, err = ParseAmount("10")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000000000), amount)
+
+	_, err = ParseAmount(".5")
+	assert.EqualError(t, err, `invalid money amount ".5"`)
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	money := myst(12345678901)
+
+	text, err := money.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "123.45678901 MYST", string(text))
+
+	var parsed Money
+	assert.NoError(t, parsed.UnmarshalText(text))
+	assert.Equal(t, money, parsed)
+}
+
+func TestTextRoundTripWithoutCurrency(t *testing.T) {
+	for _, money := range []Money{{}, {Amount: 12500000}} {
+		text, err := money.MarshalText()
+		assert.NoError(t, err)
+
+		var parsed Money
+		assert.NoError(t, parsed.UnmarshalText(text))
+		assert.Equal(t, money, parsed)
+	}
+}
+
+func TestJSONMapKeyedByMoney(t *testing.T) {
+	counts := map[Money]int{{}: 1, {Amount: 12500000}: 2, myst(12500000): 3}
+
+	data, err := json.Marshal(counts)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"0": 1, "0.125": 2, "0.125 MYST": 3}`, string(data))
+
+	var parsed map[Money]int
+	assert.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, counts, parsed)
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	money := myst(18446744073709551615)
+
+	data, err := json.Marshal(money)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"amount": 18446744073709551615, "currency": "MYST"}`, string(data))
+
+	var parsed Money
+	assert.NoError(t, json.Unmarshal(data, &parsed))
+	assert.Equal(t, money, parsed)
+
+	data, err = json.Marshal(Money{})
+	assert.NoError(t, err)
+	assert.Equal(t, `{}`, string(data))
+}
+
+func TestJSONAcceptsText(t *testing.T) {
+	var parsed Money
+	assert.NoError(t, json.Unmarshal([]byte(`"0.125 MYST"`), &parsed))
+	assert.Equal(t, myst(12500000), parsed)
+
+	assert.Error(t, json.Unmarshal([]byte(`"free"`), &parsed))
+}
+
+func TestNewMoneyRoundsToNearestUnit(t *testing.T) {
+	assert.Equal(t, myst(29000000), NewMoney(0.29, CURRENCY_MYST))
+	assert.Equal(t, myst(1), NewMoney(0.00000001, CURRENCY_MYST))
+}
+
+func TestNewMoneyClampsOutOfRangeAmount(t *testing.T) {
+	assert.Equal(t, myst(0), NewMoney(-1, CURRENCY_MYST))
+	assert.Equal(t, myst(0), NewMoney(math.NaN(), CURRENCY_MYST))
+	assert.Equal(t, myst(math.MaxUint64), NewMoney(1e12, CURRENCY_MYST))
+	assert.Equal(t, myst(math.MaxUint64), NewMoney(math.Inf(1), CURRENCY_MYST))
+}
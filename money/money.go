@@ -1,10 +1,28 @@
 package money
 
+import "math"
+
+// Decimals is number of decimal digits which amount of money has, Amount counts the smallest units
+const Decimals = 8
+
+// unitsPerCoin is number of the smallest units in one coin of currency
+const unitsPerCoin = 100000000
+
+// Money is amount of currency, counted in the smallest units of 1e-8 coin
 type Money struct {
 	Amount   uint64   `json:"amount,omitempty"`
 	Currency Currency `json:"currency,omitempty"`
 }
 
+// NewMoney converts amount of coins to money, rounding it to the nearest unit. Negative and NaN amounts are clamped
+// to zero and too large ones to the largest amount. Prefer ParseMoney or Money literal, when amount is known exactly
 func NewMoney(amount float64, currency Currency) Money {
-	return Money{uint64(amount * 100000000), currency}
+	units := math.Round(amount * unitsPerCoin)
+	switch {
+	case math.IsNaN(units) || units <= 0:
+		return Money{0, currency}
+	case units >= math.MaxUint64:
+		return Money{math.MaxUint64, currency}
+	}
+	return Money{uint64(units), currency}
 }
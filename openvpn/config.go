@@ -39,15 +39,15 @@ func (c *Config) SetManagementSocket(socketAddress string) {
 	c.setFlag("management-client")
 }
 
-func (c *Config) SetScripts() {
-	c.setParam("up", "update-resolv-conf")
-	c.setParam("down", "update-resolv-conf")
-}
-
 func (c *Config) SetPort(port int) {
 	c.setParam("port", strconv.Itoa(port))
 }
 
+// SetProtocol sets transport protocol, udp or tcp, which peers connect with
+func (c *Config) SetProtocol(protocol string) {
+	c.setParam("proto", protocol)
+}
+
 func (c *Config) SetDevice(deviceName string) {
 	c.setParam("dev", deviceName)
 }
@@ -9,13 +9,18 @@ import (
 	"time"
 )
 
+// DefaultPricePerHour is the price of service: 15 MYST/month = 0,5 MYST/day = 0,125 MYST/hour
+var DefaultPricePerHour = money.NewMoney(0.125, money.CURRENCY_MYST)
+
 func NewServiceProposalWithLocation(
+	proposalID int,
 	providerID identity.Identity,
 	providerContact dto_discovery.Contact,
 	serviceLocation dto_discovery.Location,
+	pricePerHour money.Money,
 ) dto_discovery.ServiceProposal {
 	return dto_discovery.ServiceProposal{
-		ID:          1,
+		ID:          proposalID,
 		Format:      "service-proposal/v1",
 		ServiceType: "openvpn",
 		ServiceDefinition: dto.ServiceDefinition{
@@ -25,8 +30,7 @@ func NewServiceProposalWithLocation(
 		},
 		PaymentMethodType: dto.PAYMENT_METHOD_PER_TIME,
 		PaymentMethod: dto.PaymentMethodPerTime{
-			// 15 MYST/month = 0,5 MYST/day = 0,125 MYST/hour
-			Price:    money.NewMoney(0.125, money.CURRENCY_MYST),
+			Price:    pricePerHour,
 			Duration: 1 * time.Hour,
 		},
 		ProviderID:       providerID.Address,
@@ -19,10 +19,10 @@ var (
 )
 
 func Test_NewServiceProposalWithLocation(t *testing.T) {
-	proposal := NewServiceProposalWithLocation(providerID, providerContact, locationLTTelia)
+	proposal := NewServiceProposalWithLocation(2, providerID, providerContact, locationLTTelia, DefaultPricePerHour)
 
 	assert.NotNil(t, proposal)
-	assert.Equal(t, 1, proposal.ID)
+	assert.Equal(t, 2, proposal.ID)
 	assert.Equal(t, "service-proposal/v1", proposal.Format)
 	assert.Equal(t, "openvpn", proposal.ServiceType)
 	assert.Equal(
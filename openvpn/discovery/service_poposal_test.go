@@ -40,6 +40,7 @@ func TestServiceProposalUnserialize(t *testing.T) {
 		PaymentMethod:     dto_openvpn.PaymentMethodPerTime{},
 		ProviderID:        "node",
 		ProviderContacts:  []dto_discovery.Contact{},
+		Raw:               jsonData,
 	}
 	assert.Equal(t, expected, actual)
 }
@@ -1,16 +1,20 @@
 package openvpn
 
 import (
+	"errors"
 	"io/ioutil"
+	"strings"
 )
 
 func NewServerConfig(
 	network, netmask string,
+	protocol string, port int,
 	caFile, certFile, certKeyFile,
 	dhFile, caCrtFile, authFile string,
 ) *ServerConfig {
 	config := ServerConfig{NewConfig()}
-	config.SetServerMode(1194, network, netmask)
+	config.SetServerMode(port, network, netmask)
+	config.SetProtocol(protocol)
 	config.SetTLSCACertificate(caFile)
 	config.SetTLSPrivatePubKeys(certFile, certKeyFile)
 	config.SetTlsServer(dhFile, caCrtFile)
@@ -34,10 +38,12 @@ func NewServerConfig(
 
 func NewClientConfig(
 	remote string,
+	protocol string, port int,
 	caFile, authFile string,
 ) *ClientConfig {
 	config := ClientConfig{NewConfig()}
-	config.SetClientMode(remote, 1194)
+	config.SetClientMode(remote, port)
+	config.SetProtocol(protocol)
 	config.SetTLSCACertificate(caFile)
 	config.SetTlsAuth(authFile)
 
@@ -69,3 +75,15 @@ func NewClientConfigFromString(configString, configFile string) (*ClientConfig,
 	config.AddOptions(OptionParam("config", configFile))
 	return &config, nil
 }
+
+// RemoteFromConfigString extracts address of server which client configuration connects to
+func RemoteFromConfigString(configString string) (string, error) {
+	for _, line := range strings.Split(configString, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "remote" {
+			return fields[1], nil
+		}
+	}
+
+	return "", errors.New("remote is not defined in client configuration")
+}
@@ -0,0 +1,26 @@
+package openvpn
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRemoteFromConfigString(t *testing.T) {
+	remote, err := RemoteFromConfigString("client\nremote 1.2.3.4\nport 1194\n")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", remote)
+}
+
+func TestRemoteFromConfigStringWithPortAndProtocol(t *testing.T) {
+	remote, err := RemoteFromConfigString("remote vpn.example.com 1194 udp\n")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "vpn.example.com", remote)
+}
+
+func TestRemoteFromConfigStringWhenRemoteIsMissing(t *testing.T) {
+	_, err := RemoteFromConfigString("client\nport 1194\n")
+
+	assert.EqualError(t, err, "remote is not defined in client configuration")
+}
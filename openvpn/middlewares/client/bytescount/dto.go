@@ -4,3 +4,8 @@ package bytescount
 type SessionStats struct {
 	BytesSent, BytesReceived int
 }
+
+// SessionRates represents throughput of session, computed from successive statistics samples
+type SessionRates struct {
+	BytesSentPerSecond, BytesReceivedPerSecond float64
+}
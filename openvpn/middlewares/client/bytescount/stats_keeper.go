@@ -1,14 +1,21 @@
 package bytescount
 
 import (
+	"github.com/mysterium/node/session"
+	"sync"
 	"time"
 )
 
-// SessionStatsKeeper keeps session stats
+// SessionStatsKeeper keeps stats of current session
 type SessionStatsKeeper interface {
-	Save(stats SessionStats)
+	// Save stores stats of given session, stats of any other than current session are ignored
+	Save(sessionID session.SessionID, stats SessionStats)
 	Retrieve() SessionStats
-	MarkSessionStart()
+	RetrieveRates() SessionRates
+	// MarkSessionStart makes given session current, its stats start from zero
+	MarkSessionStart(sessionID session.SessionID)
+	// MarkSessionEnd forgets current session, until next one is started
+	MarkSessionEnd()
 	GetSessionDuration() time.Duration
 }
 
@@ -16,9 +23,14 @@ type SessionStatsKeeper interface {
 type TimeGetter func() time.Time
 
 type sessionStatsKeeper struct {
+	timeGetter TimeGetter
+
+	mutex        sync.RWMutex
+	sessionID    session.SessionID
 	sessionStats SessionStats
-	timeGetter   TimeGetter
+	sessionRates SessionRates
 	sessionStart *time.Time
+	lastSaved    time.Time
 }
 
 // NewSessionStatsKeeper returns new session stats keeper with given timeGetter function
@@ -26,27 +38,83 @@ func NewSessionStatsKeeper(timeGetter TimeGetter) SessionStatsKeeper {
 	return &sessionStatsKeeper{timeGetter: timeGetter}
 }
 
-// Save saves session stats to keeper
-func (keeper *sessionStatsKeeper) Save(stats SessionStats) {
+// Save saves session stats to keeper and updates rates from difference with previous stats
+func (keeper *sessionStatsKeeper) Save(sessionID session.SessionID, stats SessionStats) {
+	keeper.mutex.Lock()
+	defer keeper.mutex.Unlock()
+
+	if keeper.sessionStart == nil || sessionID != keeper.sessionID {
+		return
+	}
+
+	now := keeper.timeGetter()
+	elapsed := now.Sub(keeper.lastSaved).Seconds()
+	if elapsed > 0 {
+		keeper.sessionRates = SessionRates{
+			BytesSentPerSecond:     rate(keeper.sessionStats.BytesSent, stats.BytesSent, elapsed),
+			BytesReceivedPerSecond: rate(keeper.sessionStats.BytesReceived, stats.BytesReceived, elapsed),
+		}
+	}
 	keeper.sessionStats = stats
+	keeper.lastSaved = now
 }
 
 // Retrieve retrieves session stats from keeper
 func (keeper *sessionStatsKeeper) Retrieve() SessionStats {
+	keeper.mutex.RLock()
+	defer keeper.mutex.RUnlock()
+
 	return keeper.sessionStats
 }
 
+// RetrieveRates retrieves session throughput computed from the last two saved stats
+func (keeper *sessionStatsKeeper) RetrieveRates() SessionRates {
+	keeper.mutex.RLock()
+	defer keeper.mutex.RUnlock()
+
+	return keeper.sessionRates
+}
+
 // MarkSessionStart marks current time as session start time for statistics
-func (keeper *sessionStatsKeeper) MarkSessionStart() {
+func (keeper *sessionStatsKeeper) MarkSessionStart(sessionID session.SessionID) {
+	keeper.mutex.Lock()
+	defer keeper.mutex.Unlock()
+
 	time := keeper.timeGetter()
+	keeper.sessionID = sessionID
+	keeper.sessionStats = SessionStats{}
+	keeper.sessionRates = SessionRates{}
 	keeper.sessionStart = &time
+	keeper.lastSaved = time
+}
+
+// MarkSessionEnd resets statistics, so that they do not bleed into next session
+func (keeper *sessionStatsKeeper) MarkSessionEnd() {
+	keeper.mutex.Lock()
+	defer keeper.mutex.Unlock()
+
+	keeper.sessionID = ""
+	keeper.sessionStats = SessionStats{}
+	keeper.sessionRates = SessionRates{}
+	keeper.sessionStart = nil
 }
 
 // GetSessionDuration returns elapsed time from marked session start
 func (keeper *sessionStatsKeeper) GetSessionDuration() time.Duration {
+	keeper.mutex.RLock()
+	defer keeper.mutex.RUnlock()
+
 	if keeper.sessionStart == nil {
 		return time.Duration(0)
 	}
 	duration := keeper.timeGetter().Sub(*keeper.sessionStart)
 	return duration
 }
+
+// rate returns bytes per second transferred between two byte counts, counter going backwards gives zero
+func rate(previous, current int, seconds float64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current-previous) / seconds
+}
@@ -11,16 +11,69 @@ func TestStatsSavingWorks(t *testing.T) {
 	statsKeeper := NewSessionStatsKeeper(time.Now)
 	stats := SessionStats{BytesSent: 1, BytesReceived: 2}
 
-	statsKeeper.Save(stats)
+	statsKeeper.MarkSessionStart("session-1")
+	statsKeeper.Save("session-1", stats)
 	assert.Equal(t, stats, statsKeeper.Retrieve())
 }
 
+func TestStatsOfOtherSessionAreIgnored(t *testing.T) {
+	statsKeeper := NewSessionStatsKeeper(time.Now)
+
+	statsKeeper.Save("session-1", SessionStats{BytesSent: 1, BytesReceived: 2})
+	assert.Equal(t, SessionStats{}, statsKeeper.Retrieve())
+
+	statsKeeper.MarkSessionStart("session-2")
+	statsKeeper.Save("session-1", SessionStats{BytesSent: 1, BytesReceived: 2})
+	assert.Equal(t, SessionStats{}, statsKeeper.Retrieve())
+}
+
+func TestStatsAreResetOnSessionStartAndEnd(t *testing.T) {
+	settableClock := utils.SettableClock{}
+	statsKeeper := NewSessionStatsKeeper(settableClock.GetTime)
+
+	settableClock.SetTime(time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC))
+	statsKeeper.MarkSessionStart("session-1")
+	settableClock.SetTime(time.Date(2000, time.January, 0, 10, 12, 4, 0, time.UTC))
+	statsKeeper.Save("session-1", SessionStats{BytesSent: 1, BytesReceived: 2})
+
+	statsKeeper.MarkSessionStart("session-2")
+	assert.Equal(t, SessionStats{}, statsKeeper.Retrieve())
+	assert.Equal(t, SessionRates{}, statsKeeper.RetrieveRates())
+
+	statsKeeper.Save("session-2", SessionStats{BytesSent: 1, BytesReceived: 2})
+	statsKeeper.MarkSessionEnd()
+	assert.Equal(t, SessionStats{}, statsKeeper.Retrieve())
+	assert.Equal(t, SessionRates{}, statsKeeper.RetrieveRates())
+	assert.Equal(t, time.Duration(0), statsKeeper.GetSessionDuration())
+}
+
+func TestRatesAreComputedFromSuccessiveStats(t *testing.T) {
+	settableClock := utils.SettableClock{}
+	statsKeeper := NewSessionStatsKeeper(settableClock.GetTime)
+	sessionStart := time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
+
+	settableClock.SetTime(sessionStart)
+	statsKeeper.MarkSessionStart("session-1")
+
+	settableClock.SetTime(sessionStart.Add(2 * time.Second))
+	statsKeeper.Save("session-1", SessionStats{BytesSent: 100, BytesReceived: 1000})
+	assert.Equal(t, SessionRates{BytesSentPerSecond: 50, BytesReceivedPerSecond: 500}, statsKeeper.RetrieveRates())
+
+	settableClock.SetTime(sessionStart.Add(6 * time.Second))
+	statsKeeper.Save("session-1", SessionStats{BytesSent: 110, BytesReceived: 3000})
+	assert.Equal(t, SessionRates{BytesSentPerSecond: 2.5, BytesReceivedPerSecond: 500}, statsKeeper.RetrieveRates())
+
+	settableClock.SetTime(sessionStart.Add(7 * time.Second))
+	statsKeeper.Save("session-1", SessionStats{BytesSent: 0, BytesReceived: 0})
+	assert.Equal(t, SessionRates{}, statsKeeper.RetrieveRates())
+}
+
 func TestGetSessionDurationReturnsFlooredDuration(t *testing.T) {
 	settableClock := utils.SettableClock{}
 	statsKeeper := NewSessionStatsKeeper(settableClock.GetTime)
 
 	settableClock.SetTime(time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC))
-	statsKeeper.MarkSessionStart()
+	statsKeeper.MarkSessionStart("session-1")
 
 	settableClock.SetTime(time.Date(2000, time.January, 0, 10, 12, 4, 700000000, time.UTC))
 	expectedDuration, err := time.ParseDuration("1s700000000ns")
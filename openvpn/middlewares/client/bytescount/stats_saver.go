@@ -1,9 +1,11 @@
 package bytescount
 
-// NewSessionStatsSaver returns stats handler, which saves stats stats keeper
-func NewSessionStatsSaver(statsKeeper SessionStatsKeeper) SessionStatsHandler {
+import "github.com/mysterium/node/session"
+
+// NewSessionStatsSaver returns stats handler, which saves stats of given session to stats keeper
+func NewSessionStatsSaver(statsKeeper SessionStatsKeeper, sessionID session.SessionID) SessionStatsHandler {
 	return func(sessionStats SessionStats) error {
-		statsKeeper.Save(sessionStats)
+		statsKeeper.Save(sessionID, sessionStats)
 		return nil
 	}
 }
@@ -8,8 +8,9 @@ import (
 
 func TestNewSessionStatsSaver(t *testing.T) {
 	statsKeeper := NewSessionStatsKeeper(time.Now)
+	statsKeeper.MarkSessionStart("session-1")
 
-	saver := NewSessionStatsSaver(statsKeeper)
+	saver := NewSessionStatsSaver(statsKeeper, "session-1")
 	stats := SessionStats{BytesSent: 1, BytesReceived: 2}
 	saver(stats)
 	assert.Equal(t, stats, statsKeeper.Retrieve())
@@ -0,0 +1,19 @@
+package bytescount
+
+import (
+	"time"
+)
+
+// NewThrottledStatsHandler returns stats handler, which passes stats to given handler at most once per interval
+func NewThrottledStatsHandler(statsHandler SessionStatsHandler, interval time.Duration, timeGetter TimeGetter) SessionStatsHandler {
+	var lastHandled *time.Time
+	return func(sessionStats SessionStats) error {
+		now := timeGetter()
+		if lastHandled != nil && now.Sub(*lastHandled) < interval {
+			return nil
+		}
+
+		lastHandled = &now
+		return statsHandler(sessionStats)
+	}
+}
@@ -0,0 +1,27 @@
+package bytescount
+
+import (
+	"github.com/mysterium/node/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestThrottledHandlerPassesStatsOncePerInterval(t *testing.T) {
+	settableClock := utils.SettableClock{}
+	start := time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
+	statsRecorder := fakeStatsRecorder{}
+	handler := NewThrottledStatsHandler(statsRecorder.record, time.Minute, settableClock.GetTime)
+
+	settableClock.SetTime(start)
+	assert.NoError(t, handler(SessionStats{BytesSent: 1, BytesReceived: 1}))
+	assert.Equal(t, SessionStats{BytesSent: 1, BytesReceived: 1}, statsRecorder.LastSessionStats)
+
+	settableClock.SetTime(start.Add(59 * time.Second))
+	assert.NoError(t, handler(SessionStats{BytesSent: 2, BytesReceived: 2}))
+	assert.Equal(t, SessionStats{BytesSent: 1, BytesReceived: 1}, statsRecorder.LastSessionStats)
+
+	settableClock.SetTime(start.Add(time.Minute))
+	assert.NoError(t, handler(SessionStats{BytesSent: 3, BytesReceived: 3}))
+	assert.Equal(t, SessionStats{BytesSent: 3, BytesReceived: 3}, statsRecorder.LastSessionStats)
+}
@@ -0,0 +1,90 @@
+package dns
+
+import (
+	"github.com/mysterium/node/openvpn"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// ServersCallback is invoked when tunnel is up and name servers pushed by openvpn server can be used
+type ServersCallback func(device string, servers []string) error
+
+// ReleaseCallback is invoked when openvpn client stops and pushed name servers are no longer reachable
+type ReleaseCallback func() error
+
+var (
+	logRule          = regexp.MustCompile("^>LOG:\\d+,[A-Z]*,(.*)$")
+	pushReplyRule    = regexp.MustCompile("PUSH_REPLY,(.*)'$")
+	deviceOpenedRule = regexp.MustCompile("^TUN/TAP device (\\S+) opened")
+)
+
+// initializationCompleted is logged by openvpn client when tunnel is ready for traffic
+const initializationCompleted = "Initialization Sequence Completed"
+
+type middleware struct {
+	onServers ServersCallback
+	onRelease ReleaseCallback
+
+	connection net.Conn
+	device     string
+	servers    []string
+}
+
+// NewMiddleware creates middleware which handles name servers pushed by openvpn server as dhcp-option DNS
+func NewMiddleware(onServers ServersCallback, onRelease ReleaseCallback) openvpn.ManagementMiddleware {
+	return &middleware{
+		onServers: onServers,
+		onRelease: onRelease,
+
+		connection: nil,
+	}
+}
+
+func (middleware *middleware) Start(connection net.Conn) error {
+	middleware.connection = connection
+
+	_, err := middleware.connection.Write([]byte("log on all\n"))
+	return err
+}
+
+func (middleware *middleware) Stop() error {
+	if middleware.connection != nil {
+		middleware.connection.Write([]byte("log off\n"))
+	}
+	return middleware.onRelease()
+}
+
+func (middleware *middleware) ConsumeLine(line string) (consumed bool, err error) {
+	match := logRule.FindStringSubmatch(line)
+	consumed = len(match) > 0
+	if !consumed {
+		return
+	}
+	message := match[1]
+
+	if match := pushReplyRule.FindStringSubmatch(message); len(match) > 0 {
+		middleware.servers = parsePushedServers(match[1])
+		return
+	}
+	if match := deviceOpenedRule.FindStringSubmatch(message); len(match) > 0 {
+		middleware.device = match[1]
+		return
+	}
+	if strings.HasPrefix(message, initializationCompleted) && len(middleware.servers) > 0 {
+		err = middleware.onServers(middleware.device, middleware.servers)
+	}
+	return
+}
+
+// parsePushedServers extracts name servers from options of PUSH_REPLY message
+func parsePushedServers(options string) []string {
+	servers := make([]string, 0)
+	for _, option := range strings.Split(options, ",") {
+		fields := strings.Fields(option)
+		if len(fields) == 3 && fields[0] == "dhcp-option" && fields[1] == "DNS" {
+			servers = append(servers, fields[2])
+		}
+	}
+	return servers
+}
@@ -0,0 +1,90 @@
+package dns
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type serversRecorder struct {
+	device   string
+	servers  []string
+	released bool
+}
+
+func (recorder *serversRecorder) onServers(device string, servers []string) error {
+	recorder.device = device
+	recorder.servers = servers
+	return nil
+}
+
+func (recorder *serversRecorder) onRelease() error {
+	recorder.released = true
+	return nil
+}
+
+func Test_Factory(t *testing.T) {
+	recorder := &serversRecorder{}
+	middleware := NewMiddleware(recorder.onServers, recorder.onRelease)
+	assert.NotNil(t, middleware)
+}
+
+func Test_ConsumeLineSkips(t *testing.T) {
+	var tests = []struct {
+		line string
+	}{
+		{"OTHER"},
+		{">STATE:1495493709,AUTH,,,,,,"},
+		{"LOG:1495493709,I,Initialization Sequence Completed"},
+	}
+
+	recorder := &serversRecorder{}
+	middleware := NewMiddleware(recorder.onServers, recorder.onRelease)
+	for _, test := range tests {
+		consumed, err := middleware.ConsumeLine(test.line)
+		assert.NoError(t, err, test.line)
+		assert.False(t, consumed, test.line)
+	}
+}
+
+func Test_ConsumeLineAppliesPushedServers(t *testing.T) {
+	var lines = []string{
+		">LOG:1495493709,I,PUSH: Received control message: 'PUSH_REPLY,dhcp-option DNS 10.8.0.1,route-gateway 10.8.0.1,dhcp-option DNS 208.67.222.222,ifconfig 10.8.0.6 255.255.255.0'",
+		">LOG:1495493710,,TUN/TAP device tun0 opened",
+		">LOG:1495493711,I,Initialization Sequence Completed",
+	}
+
+	recorder := &serversRecorder{}
+	middleware := NewMiddleware(recorder.onServers, recorder.onRelease)
+	for _, line := range lines {
+		consumed, err := middleware.ConsumeLine(line)
+		assert.NoError(t, err, line)
+		assert.True(t, consumed, line)
+	}
+
+	assert.Equal(t, "tun0", recorder.device)
+	assert.Equal(t, []string{"10.8.0.1", "208.67.222.222"}, recorder.servers)
+}
+
+func Test_ConsumeLineIgnoresCompletionWithoutPushedServers(t *testing.T) {
+	var lines = []string{
+		">LOG:1495493709,I,PUSH: Received control message: 'PUSH_REPLY,route-gateway 10.8.0.1,ifconfig 10.8.0.6 255.255.255.0'",
+		">LOG:1495493711,I,Initialization Sequence Completed",
+	}
+
+	recorder := &serversRecorder{}
+	middleware := NewMiddleware(recorder.onServers, recorder.onRelease)
+	for _, line := range lines {
+		_, err := middleware.ConsumeLine(line)
+		assert.NoError(t, err, line)
+	}
+
+	assert.Nil(t, recorder.servers)
+}
+
+func Test_StopReleasesServers(t *testing.T) {
+	recorder := &serversRecorder{}
+	middleware := NewMiddleware(recorder.onServers, recorder.onRelease)
+
+	assert.NoError(t, middleware.Stop())
+	assert.True(t, recorder.released)
+}
@@ -7,13 +7,15 @@ import (
 )
 
 type middleware struct {
-	listeners  []clientStateCallback
+	listeners  []ClientStateCallback
 	connection net.Conn
 }
 
-type clientStateCallback func(state openvpn.State) error
+// ClientStateCallback is invoked when openvpn client reports its state change
+type ClientStateCallback func(state openvpn.State) error
 
-func NewMiddleware(listeners ...clientStateCallback) openvpn.ManagementMiddleware {
+// NewMiddleware creates client state tracking middleware
+func NewMiddleware(listeners ...ClientStateCallback) openvpn.ManagementMiddleware {
 	return &middleware{
 		listeners:  listeners,
 		connection: nil,
@@ -33,7 +35,7 @@ func (middleware *middleware) Stop() error {
 }
 
 func (middleware *middleware) ConsumeLine(line string) (consumed bool, err error) {
-	rule, err := regexp.Compile("^>STATE:\\d+,([a-zA-Z]+),.*$")
+	rule, err := regexp.Compile("^>STATE:\\d+,([a-zA-Z_]+),.*$")
 	if err != nil {
 		return
 	}
@@ -55,6 +57,6 @@ func (middleware *middleware) ConsumeLine(line string) (consumed bool, err error
 	return
 }
 
-func (middleware *middleware) Subscribe(listener clientStateCallback) {
+func (middleware *middleware) Subscribe(listener ClientStateCallback) {
 	middleware.listeners = append(middleware.listeners, listener)
 }
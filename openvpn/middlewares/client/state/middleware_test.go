@@ -42,6 +42,8 @@ func Test_ConsumeLineTakes(t *testing.T) {
 		{">STATE:1495493709,AUTH,,,,,,", openvpn.STATE_AUTH},
 		{">STATE:1495891020,RECONNECTING,ping-restart,,,,,", openvpn.STATE_RECONNECTING},
 		{">STATE:1495891025,WAIT,,,,,,", openvpn.STATE_WAIT},
+		{">STATE:1495891026,GET_CONFIG,,,,,,", openvpn.STATE_GET_CONFIG},
+		{">STATE:1495891027,ASSIGN_IP,,10.8.0.6,,,,", openvpn.STATE_ASSIGN_IP},
 	}
 
 	middleware := &middleware{}
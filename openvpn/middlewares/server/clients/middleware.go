@@ -0,0 +1,288 @@
+package clients
+
+import (
+	"fmt"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	establishedRule = regexp.MustCompile("^>CLIENT:ESTABLISHED,(\\d+)$")
+	disconnectRule  = regexp.MustCompile("^>CLIENT:DISCONNECT,(\\d+)$")
+	envRule         = regexp.MustCompile("^>CLIENT:ENV,([^=]*)=(.*)$")
+	envEndRule      = regexp.MustCompile("^>CLIENT:ENV,END$")
+	bytecountRule   = regexp.MustCompile("^>BYTECOUNT_CLI:(\\d+),(\\d+),(\\d+)$")
+)
+
+// ConsumerResolver returns identity of consumer which owns given session
+type ConsumerResolver func(sessionID session.SessionID) (identity.Identity, bool)
+
+// Client is openvpn client connected to server, traffic is counted from consumer's side
+type Client struct {
+	ID            int
+	SessionID     session.SessionID
+	ConsumerID    identity.Identity
+	VirtualIP     string
+	Connected     time.Time
+	BytesSent     int
+	BytesReceived int
+}
+
+// SessionStats is traffic of all clients which have used the session, counted from consumer's side
+type SessionStats struct {
+	SessionID     session.SessionID
+	BytesSent     int
+	BytesReceived int
+}
+
+// Registry keeps track of clients connected to openvpn server
+type Registry interface {
+	Clients() []Client
+	SessionStats() []SessionStats
+	AcknowledgeSessionStats(reported []SessionStats)
+	KillClient(username string) error
+}
+
+type middleware struct {
+	resolveConsumer   ConsumerResolver
+	bytecountInterval time.Duration
+	timeGetter        func() time.Time
+
+	mutex      sync.Mutex
+	connection net.Conn
+	clients    map[int]*Client
+	// notified is the client which environment is being received at the moment
+	notified     *Client
+	disconnected bool
+	// finished holds traffic of disconnected clients, until their sessions are killed and the traffic is reported
+	finished map[session.SessionID]SessionStats
+	// killed are sessions which have no clients anymore, their traffic is kept until it is reported
+	killed map[session.SessionID]bool
+}
+
+// NewMiddleware creates middleware which keeps registry of connected clients, counts their traffic
+// and allows disconnecting them
+func NewMiddleware(resolveConsumer ConsumerResolver, bytecountInterval time.Duration) *middleware {
+	return &middleware{
+		resolveConsumer:   resolveConsumer,
+		bytecountInterval: bytecountInterval,
+		timeGetter:        time.Now,
+		clients:           make(map[int]*Client),
+		finished:          make(map[session.SessionID]SessionStats),
+		killed:            make(map[session.SessionID]bool),
+	}
+}
+
+func (m *middleware) Start(connection net.Conn) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.connection = connection
+	command := fmt.Sprintf("bytecount %d\n", int(m.bytecountInterval.Seconds()))
+	_, err := m.connection.Write([]byte(command))
+	return err
+}
+
+func (m *middleware) Stop() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.clients = make(map[int]*Client)
+	m.notified = nil
+	if m.connection == nil {
+		return nil
+	}
+	_, err := m.connection.Write([]byte("bytecount 0\n"))
+	return err
+}
+
+func (m *middleware) ConsumeLine(line string) (consumed bool, err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if match := establishedRule.FindStringSubmatch(line); len(match) > 0 {
+		clientID, err := strconv.Atoi(match[1])
+		m.notified = &Client{ID: clientID, Connected: m.timeGetter()}
+		m.disconnected = false
+		return true, err
+	}
+
+	if match := disconnectRule.FindStringSubmatch(line); len(match) > 0 {
+		clientID, err := strconv.Atoi(match[1])
+		m.notified = m.clients[clientID]
+		m.disconnected = true
+		delete(m.clients, clientID)
+		return true, err
+	}
+
+	if match := bytecountRule.FindStringSubmatch(line); len(match) > 0 {
+		return true, m.countBytes(match[1], match[2], match[3])
+	}
+
+	if m.notified == nil {
+		return false, nil
+	}
+
+	if match := envRule.FindStringSubmatch(line); len(match) > 0 {
+		return true, m.setEnv(match[1], match[2])
+	}
+
+	if envEndRule.MatchString(line) {
+		m.finishNotification()
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// countBytes updates traffic of the client, openvpn reports bytes received from client first
+func (m *middleware) countBytes(clientIDValue, bytesInValue, bytesOutValue string) error {
+	clientID, err := strconv.Atoi(clientIDValue)
+	if err != nil {
+		return err
+	}
+	bytesIn, err := strconv.Atoi(bytesInValue)
+	if err != nil {
+		return err
+	}
+	bytesOut, err := strconv.Atoi(bytesOutValue)
+	if err != nil {
+		return err
+	}
+
+	if client, found := m.clients[clientID]; found {
+		client.BytesSent = bytesIn
+		client.BytesReceived = bytesOut
+	}
+	return nil
+}
+
+func (m *middleware) setEnv(name, value string) (err error) {
+	switch name {
+	case "username":
+		m.notified.SessionID = session.SessionID(value)
+	case "ifconfig_pool_remote_ip":
+		m.notified.VirtualIP = value
+	case "bytes_received":
+		m.notified.BytesSent, err = strconv.Atoi(value)
+	case "bytes_sent":
+		m.notified.BytesReceived, err = strconv.Atoi(value)
+	}
+	return err
+}
+
+// finishNotification registers established client or moves traffic of disconnected client to its session
+func (m *middleware) finishNotification() {
+	client := m.notified
+	m.notified = nil
+
+	if m.disconnected {
+		stats := m.finished[client.SessionID]
+		stats.SessionID = client.SessionID
+		stats.BytesSent += client.BytesSent
+		stats.BytesReceived += client.BytesReceived
+		m.finished[client.SessionID] = stats
+		return
+	}
+
+	client.ConsumerID, _ = m.resolveConsumer(client.SessionID)
+	m.clients[client.ID] = client
+}
+
+// Clients returns connected clients ordered by connection time
+func (m *middleware) Clients() []Client {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	clients := make([]Client, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, *client)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		if clients[i].Connected.Equal(clients[j].Connected) {
+			return clients[i].ID < clients[j].ID
+		}
+		return clients[i].Connected.Before(clients[j].Connected)
+	})
+	return clients
+}
+
+// SessionStats returns traffic of sessions which have connected or disconnected clients, ordered by session id.
+// Killed sessions are included until their traffic is acknowledged as reported.
+func (m *middleware) SessionStats() []SessionStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	statsMap := make(map[session.SessionID]SessionStats, len(m.finished))
+	for sessionID, stats := range m.finished {
+		statsMap[sessionID] = stats
+	}
+	for _, client := range m.clients {
+		stats := statsMap[client.SessionID]
+		stats.SessionID = client.SessionID
+		stats.BytesSent += client.BytesSent
+		stats.BytesReceived += client.BytesReceived
+		statsMap[client.SessionID] = stats
+	}
+
+	stats := make([]SessionStats, 0, len(statsMap))
+	for _, sessionStats := range statsMap {
+		stats = append(stats, sessionStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].SessionID < stats[j].SessionID
+	})
+	return stats
+}
+
+// AcknowledgeSessionStats forgets traffic of killed sessions, once their final traffic is reported
+func (m *middleware) AcknowledgeSessionStats(reported []SessionStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, stats := range reported {
+		if m.killed[stats.SessionID] && m.finished[stats.SessionID] == stats {
+			delete(m.killed, stats.SessionID)
+			delete(m.finished, stats.SessionID)
+		}
+	}
+}
+
+// KillClient disconnects clients which have authenticated with given username.
+// Traffic of their session is kept until it is reported.
+func (m *middleware) KillClient(username string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	sessionID := session.SessionID(username)
+	for clientID, client := range m.clients {
+		if client.SessionID != sessionID {
+			continue
+		}
+		if m.connection == nil {
+			return fmt.Errorf("management interface is not connected")
+		}
+
+		_, err := m.connection.Write([]byte("client-kill " + strconv.Itoa(clientID) + "\n"))
+		if err != nil {
+			return err
+		}
+		delete(m.clients, clientID)
+
+		stats := m.finished[sessionID]
+		stats.SessionID = sessionID
+		stats.BytesSent += client.BytesSent
+		stats.BytesReceived += client.BytesReceived
+		m.finished[sessionID] = stats
+	}
+	if _, found := m.finished[sessionID]; found {
+		m.killed[sessionID] = true
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package clients
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/openvpn"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/utils"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+	"time"
+)
+
+var connectedTime = time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+
+type fakeConnection struct {
+	lastDataWritten []byte
+	net.Conn
+}
+
+func (conn *fakeConnection) Write(b []byte) (n int, err error) {
+	conn.lastDataWritten = b
+	return len(b), nil
+}
+
+func resolveConsumer(sessionID session.SessionID) (identity.Identity, bool) {
+	if sessionID == "session-1" {
+		return identity.FromAddress("consumer-1"), true
+	}
+	return identity.Identity{}, false
+}
+
+func newStartedMiddleware() (*middleware, *fakeConnection) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(connectedTime)
+
+	middleware := NewMiddleware(resolveConsumer, 5*time.Second)
+	middleware.timeGetter = clock.GetTime
+	connection := &fakeConnection{}
+	middleware.Start(connection)
+	connection.lastDataWritten = nil
+	return middleware, connection
+}
+
+func consumeLines(t *testing.T, middleware openvpn.ManagementMiddleware, lines ...string) {
+	for _, line := range lines {
+		_, err := middleware.ConsumeLine(line)
+		assert.NoError(t, err, line)
+	}
+}
+
+func establishClient(t *testing.T, middleware openvpn.ManagementMiddleware, clientID, sessionID string) {
+	consumeLines(
+		t,
+		middleware,
+		">CLIENT:ESTABLISHED,"+clientID,
+		">CLIENT:ENV,common_name=client",
+		">CLIENT:ENV,username="+sessionID,
+		">CLIENT:ENV,ifconfig_pool_remote_ip=10.8.0.6",
+		">CLIENT:ENV,END",
+	)
+}
+
+func TestMiddlewareEnablesBytecountOnStart(t *testing.T) {
+	var _ openvpn.ManagementMiddleware = NewMiddleware(resolveConsumer, time.Second)
+	middleware := NewMiddleware(resolveConsumer, 5*time.Second)
+	connection := &fakeConnection{}
+
+	assert.NoError(t, middleware.Start(connection))
+	assert.Equal(t, "bytecount 5\n", string(connection.lastDataWritten))
+
+	assert.NoError(t, middleware.Stop())
+	assert.Equal(t, "bytecount 0\n", string(connection.lastDataWritten))
+}
+
+func TestMiddlewareRegistersEstablishedClient(t *testing.T) {
+	middleware, _ := newStartedMiddleware()
+
+	establishClient(t, middleware, "12", "session-1")
+
+	assert.Equal(
+		t,
+		[]Client{
+			{
+				ID:         12,
+				SessionID:  "session-1",
+				ConsumerID: identity.FromAddress("consumer-1"),
+				VirtualIP:  "10.8.0.6",
+				Connected:  connectedTime,
+			},
+		},
+		middleware.Clients(),
+	)
+}
+
+func TestMiddlewareCountsTrafficOfClients(t *testing.T) {
+	middleware, _ := newStartedMiddleware()
+	establishClient(t, middleware, "12", "session-1")
+	establishClient(t, middleware, "13", "session-2")
+
+	consumeLines(
+		t,
+		middleware,
+		">BYTECOUNT_CLI:12,100,2000",
+		">BYTECOUNT_CLI:13,5,50",
+		">BYTECOUNT_CLI:12,150,3000",
+		">BYTECOUNT_CLI:99,1,1",
+	)
+
+	assert.Equal(
+		t,
+		[]SessionStats{
+			{SessionID: "session-1", BytesSent: 150, BytesReceived: 3000},
+			{SessionID: "session-2", BytesSent: 5, BytesReceived: 50},
+		},
+		middleware.SessionStats(),
+	)
+}
+
+func TestMiddlewareKeepsTrafficOfSessionAfterClientReconnects(t *testing.T) {
+	middleware, _ := newStartedMiddleware()
+	establishClient(t, middleware, "12", "session-1")
+
+	consumeLines(
+		t,
+		middleware,
+		">BYTECOUNT_CLI:12,100,2000",
+		">CLIENT:DISCONNECT,12",
+		">CLIENT:ENV,username=session-1",
+		">CLIENT:ENV,bytes_received=120",
+		">CLIENT:ENV,bytes_sent=2500",
+		">CLIENT:ENV,END",
+	)
+	assert.Empty(t, middleware.Clients())
+
+	establishClient(t, middleware, "13", "session-1")
+	consumeLines(t, middleware, ">BYTECOUNT_CLI:13,10,20")
+
+	assert.Equal(
+		t,
+		[]SessionStats{
+			{SessionID: "session-1", BytesSent: 130, BytesReceived: 2520},
+		},
+		middleware.SessionStats(),
+	)
+}
+
+func TestMiddlewareKillsEstablishedClient(t *testing.T) {
+	middleware, connection := newStartedMiddleware()
+	establishClient(t, middleware, "12", "session-1")
+	consumeLines(t, middleware, ">BYTECOUNT_CLI:12,100,2000")
+
+	assert.NoError(t, middleware.KillClient("session-1"))
+	assert.Equal(t, "client-kill 12\n", string(connection.lastDataWritten))
+	assert.Empty(t, middleware.Clients())
+	assert.Equal(t, []SessionStats{{SessionID: "session-1", BytesSent: 100, BytesReceived: 2000}}, middleware.SessionStats())
+}
+
+func TestMiddlewareKeepsTrafficOfKilledSessionUntilItIsReported(t *testing.T) {
+	middleware, _ := newStartedMiddleware()
+	establishClient(t, middleware, "12", "session-1")
+	consumeLines(t, middleware, ">BYTECOUNT_CLI:12,100,2000")
+
+	middleware.AcknowledgeSessionStats(middleware.SessionStats())
+	assert.NotEmpty(t, middleware.SessionStats(), "traffic of live session is not forgotten")
+
+	assert.NoError(t, middleware.KillClient("session-1"))
+	middleware.AcknowledgeSessionStats([]SessionStats{{SessionID: "session-1", BytesSent: 50, BytesReceived: 1000}})
+	assert.NotEmpty(t, middleware.SessionStats(), "traffic is forgotten only when final traffic is reported")
+
+	middleware.AcknowledgeSessionStats(middleware.SessionStats())
+	assert.Empty(t, middleware.SessionStats())
+}
+
+func TestMiddlewareStopsWithoutConnection(t *testing.T) {
+	middleware := NewMiddleware(resolveConsumer, time.Second)
+	assert.NoError(t, middleware.Stop())
+}
+
+func TestMiddlewareIgnoresUnknownClient(t *testing.T) {
+	middleware, connection := newStartedMiddleware()
+
+	consumeLines(
+		t,
+		middleware,
+		">CLIENT:CONNECT,3,0",
+		">CLIENT:ENV,username=session-1",
+		">CLIENT:ENV,END",
+	)
+
+	assert.NoError(t, middleware.KillClient("session-1"))
+	assert.Nil(t, connection.lastDataWritten)
+}
+
+func TestMiddlewareForgetsDisconnectedClient(t *testing.T) {
+	middleware, connection := newStartedMiddleware()
+
+	establishClient(t, middleware, "12", "session-1")
+	consumeLines(
+		t,
+		middleware,
+		">CLIENT:DISCONNECT,12",
+		">CLIENT:ENV,username=session-1",
+		">CLIENT:ENV,END",
+	)
+
+	assert.NoError(t, middleware.KillClient("session-1"))
+	assert.Nil(t, connection.lastDataWritten)
+}
+
+func TestMiddlewareConsumesOnlyClientNotifications(t *testing.T) {
+	middleware, _ := newStartedMiddleware()
+
+	consumed, err := middleware.ConsumeLine(">STATE:1495493709,CONNECTED,SUCCESS,,")
+	assert.NoError(t, err)
+	assert.False(t, consumed)
+
+	consumed, err = middleware.ConsumeLine(">CLIENT:ENV,username=session-1")
+	assert.NoError(t, err)
+	assert.False(t, consumed)
+
+	consumed, err = middleware.ConsumeLine(">CLIENT:ESTABLISHED,1")
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+
+	consumed, err = middleware.ConsumeLine(">BYTECOUNT_CLI:1,1,1")
+	assert.NoError(t, err)
+	assert.True(t, consumed)
+}
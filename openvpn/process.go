@@ -12,7 +12,7 @@ func NewProcess(logPrefix string) *Process {
 	return &Process{
 		logPrefix: logPrefix,
 
-		cmdExitError:       make(chan error),
+		cmdExited:          make(chan bool),
 		cmdShutdownStarted: make(chan bool),
 		cmdShutdownWaiter:  sync.WaitGroup{},
 	}
@@ -21,7 +21,8 @@ func NewProcess(logPrefix string) *Process {
 type Process struct {
 	logPrefix string
 
-	cmdExitError       chan error
+	cmdExitError       error
+	cmdExited          chan bool
 	cmdShutdownStarted chan bool
 	cmdShutdownWaiter  sync.WaitGroup
 }
@@ -48,8 +49,10 @@ func (process *Process) Start(arguments []string) (err error) {
 	return
 }
 
+// Wait blocks until process exits and returns its exit error
 func (process *Process) Wait() error {
-	return <-process.cmdExitError
+	<-process.cmdExited
+	return process.cmdExitError
 }
 
 func (process *Process) Stop() {
@@ -92,7 +95,8 @@ func (process *Process) stderrMonitor(cmd *exec.Cmd) {
 }
 
 func (process *Process) waitForExit(cmd *exec.Cmd) {
-	process.cmdExitError <- cmd.Wait()
+	process.cmdExitError = cmd.Wait()
+	close(process.cmdExited)
 }
 
 func (process *Process) waitForShutdown(cmd *exec.Cmd) {
@@ -108,12 +112,12 @@ func (process *Process) waitForShutdown(cmd *exec.Cmd) {
 		}
 
 		// Allow goroutine to exit
-		err := <-process.cmdExitError
-		log.Error(process.logPrefix, "Process killed with error = ", err)
+		<-process.cmdExited
+		log.Error(process.logPrefix, "Process killed with error = ", process.cmdExitError)
 
 	// Wait for exit
-	case err := <-process.cmdExitError:
-		log.Error(process.logPrefix, "Process cmdExitError with error = ", err)
+	case <-process.cmdExited:
+		log.Error(process.logPrefix, "Process cmdExitError with error = ", process.cmdExitError)
 		return
 	}
 }
@@ -0,0 +1,23 @@
+package service
+
+import (
+	"github.com/mysterium/node/firewall"
+	"github.com/mysterium/node/openvpn"
+)
+
+// tunnelInterface matches network interfaces created by openvpn client
+const tunnelInterface = "tun+"
+
+// connection is openvpn client, which also tells what kill switch should let through
+type connection struct {
+	openvpn.Client
+	remote string
+}
+
+// KillSwitchRules allows tunnel interfaces and traffic to openvpn server
+func (c *connection) KillSwitchRules() []firewall.RuleAllowed {
+	return []firewall.RuleAllowed{
+		{Interface: tunnelInterface},
+		{RemoteAddress: c.remote},
+	}
+}
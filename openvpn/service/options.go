@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"github.com/mysterium/node/money"
+	"strconv"
+	"strings"
+)
+
+// Options describes how consumers connect to openvpn service and how much they pay for it
+type Options struct {
+	Protocol string
+	Port     int
+	// PricePerHour is price in MYST, zero means default price
+	PricePerHour money.Money
+}
+
+// DefaultOptions serves openvpn on its standard port for default price
+func DefaultOptions() Options {
+	return Options{
+		Protocol: "udp",
+		Port:     1194,
+	}
+}
+
+// ParseOptions parses options of format protocol:port[:price] e.g. "tcp:443:0.2", empty value means default options
+func ParseOptions(value string) (options Options, err error) {
+	if value == "" {
+		return DefaultOptions(), nil
+	}
+
+	fields := strings.Split(value, ":")
+	if len(fields) < 2 || len(fields) > 3 {
+		return options, fmt.Errorf("invalid openvpn service %q, expected protocol:port[:price]", value)
+	}
+
+	options.Protocol = fields[0]
+	if options.Protocol != "udp" && options.Protocol != "tcp" {
+		return options, fmt.Errorf("invalid protocol of openvpn service %q, expected udp or tcp", value)
+	}
+
+	options.Port, err = strconv.Atoi(fields[1])
+	if err != nil || options.Port <= 0 || options.Port > 65535 {
+		return options, fmt.Errorf("invalid port of openvpn service %q", value)
+	}
+
+	if len(fields) == 3 {
+		amount, err := money.ParseAmount(fields[2])
+		if err != nil {
+			return options, fmt.Errorf("invalid price of openvpn service %q", value)
+		}
+		options.PricePerHour = money.Money{Amount: amount, Currency: money.CURRENCY_MYST}
+	}
+	return options, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/mysterium/node/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	options, err := ParseOptions("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultOptions(), options)
+
+	options, err = ParseOptions("udp:1195")
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Protocol: "udp", Port: 1195}, options)
+
+	options, err = ParseOptions("tcp:443:0.2")
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Protocol: "tcp", Port: 443, PricePerHour: money.NewMoney(0.2, money.CURRENCY_MYST)}, options)
+}
+
+func TestParseOptionsWithInvalidValue(t *testing.T) {
+	_, err := ParseOptions("tcp")
+	assert.EqualError(t, err, `invalid openvpn service "tcp", expected protocol:port[:price]`)
+
+	_, err = ParseOptions("icmp:1194")
+	assert.EqualError(t, err, `invalid protocol of openvpn service "icmp:1194", expected udp or tcp`)
+
+	_, err = ParseOptions("udp:70000")
+	assert.EqualError(t, err, `invalid port of openvpn service "udp:70000"`)
+
+	_, err = ParseOptions("udp:1194:free")
+	assert.EqualError(t, err, `invalid price of openvpn service "udp:1194:free"`)
+}
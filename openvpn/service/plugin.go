@@ -0,0 +1,182 @@
+package service
+
+import (
+	"fmt"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/nat"
+	"github.com/mysterium/node/openvpn"
+	"github.com/mysterium/node/openvpn/discovery"
+	client_auth "github.com/mysterium/node/openvpn/middlewares/client/auth"
+	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	client_dns "github.com/mysterium/node/openvpn/middlewares/client/dns"
+	"github.com/mysterium/node/openvpn/middlewares/client/state"
+	server_auth "github.com/mysterium/node/openvpn/middlewares/server/auth"
+	"github.com/mysterium/node/openvpn/middlewares/server/clients"
+	openvpn_session "github.com/mysterium/node/openvpn/session"
+	"github.com/mysterium/node/service"
+	"github.com/mysterium/node/session"
+	"path/filepath"
+	"time"
+)
+
+// ServiceType is the type of openvpn proposals
+const ServiceType = "openvpn"
+
+// maxProposalID is the largest proposal id which gets a subnet, services get subnets 10.8.0.0/24 to 10.8.255.0/24
+const maxProposalID = 256
+
+const (
+	// clientsBytecountInterval is how often openvpn server reports traffic of each client
+	clientsBytecountInterval = 5 * time.Second
+	// statsReportInterval is how often openvpn client reports transferred bytes, short enough to compute current rates
+	statsReportInterval = 1 * time.Second
+)
+
+// Bootstrap registers openvpn proposal unserializers and openvpn plugin
+func Bootstrap() {
+	openvpn.Bootstrap()
+	service.RegisterPlugin(NewPlugin())
+}
+
+type plugin struct{}
+
+// NewPlugin creates plugin which runs openvpn servers on provider and openvpn clients on consumer
+func NewPlugin() *plugin {
+	return &plugin{}
+}
+
+func (p *plugin) ServiceType() string {
+	return ServiceType
+}
+
+func (p *plugin) ParseOptions(value string) (service.Options, error) {
+	return ParseOptions(value)
+}
+
+// NewService creates service with its own openvpn server, every service gets its own subnet by proposal id
+func (p *plugin) NewService(params service.ServiceParams, serviceOptions service.Options) (service.Service, error) {
+	options, ok := serviceOptions.(Options)
+	if !ok {
+		return nil, fmt.Errorf("invalid options of openvpn service: %v", serviceOptions)
+	}
+	if params.ProposalID < 1 || params.ProposalID > maxProposalID {
+		return nil, fmt.Errorf("openvpn service of proposal %d has no subnet, at most %d services are supported", params.ProposalID, maxProposalID)
+	}
+
+	pricePerHour := discovery.DefaultPricePerHour
+	if options.PricePerHour.Amount > 0 {
+		pricePerHour = options.PricePerHour
+	}
+	proposal := discovery.NewServiceProposalWithLocation(
+		params.ProposalID,
+		params.ProviderID,
+		params.ProviderContact,
+		params.Location,
+		pricePerHour,
+	)
+
+	network := fmt.Sprintf("10.8.%d.0", params.ProposalID-1)
+	params.NATService.Add(nat.RuleForwarding{
+		SourceAddress: network + "/24",
+		TargetIP:      params.ServerIP,
+	})
+
+	clientConfig := openvpn.NewClientConfig(
+		params.ServerIP,
+		options.Protocol, options.Port,
+		filepath.Join(params.DirectoryConfig, "ca.crt"),
+		filepath.Join(params.DirectoryConfig, "ta.key"),
+	)
+	generateConfig := func() (string, error) {
+		return openvpn.ConfigToString(*clientConfig.Config)
+	}
+
+	// clients get resolved only once server runs, which is after session manager is created
+	var sessionManager session.Manager
+	vpnClients := clients.NewMiddleware(
+		func(sessionID session.SessionID) (identity.Identity, bool) {
+			sessionInstance, found := sessionManager.Lookup(sessionID)
+			return sessionInstance.ConsumerID, found
+		},
+		clientsBytecountInterval,
+	)
+	killVpnClient := func(sessionID session.SessionID) error {
+		return vpnClients.KillClient(string(sessionID))
+	}
+
+	expiringManager := params.NewSessionManager(generateConfig, killVpnClient)
+	sessionManager = expiringManager
+
+	vpnServerConfig := openvpn.NewServerConfig(
+		network, "255.255.255.0",
+		options.Protocol, options.Port,
+		filepath.Join(params.DirectoryConfig, "ca.crt"),
+		filepath.Join(params.DirectoryConfig, "server.crt"),
+		filepath.Join(params.DirectoryConfig, "server.key"),
+		filepath.Join(params.DirectoryConfig, "dh.pem"),
+		filepath.Join(params.DirectoryConfig, "crl.pem"),
+		filepath.Join(params.DirectoryConfig, "ta.key"),
+	)
+	sessionValidator := openvpn_session.NewSessionValidator(
+		expiringManager.FindSession,
+		identity.NewExtractor(),
+	)
+	vpnMiddlewares := []openvpn.ManagementMiddleware{
+		server_auth.NewMiddleware(sessionValidator),
+		vpnClients,
+	}
+	vpnServer := openvpn.NewServer(vpnServerConfig, params.DirectoryRuntime, vpnMiddlewares...)
+
+	return NewService(proposal, expiringManager, vpnServer, vpnClients), nil
+}
+
+// NewConnection creates openvpn client, which authenticates with session id signed by consumer
+func (p *plugin) NewConnection(params service.ConnectionParams) (service.Connection, error) {
+	vpnConfig, err := openvpn.NewClientConfigFromString(
+		params.Session.Config,
+		filepath.Join(params.DirectoryRuntime, "client.ovpn"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := openvpn.RemoteFromConfigString(params.Session.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialsProvider := openvpn_session.SignatureCredentialsProvider(params.Session.ID, params.Signer)
+	statsHandler := func(stats bytescount.SessionStats) error {
+		return params.StatsHandler(service.ConnectionStats{
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+		})
+	}
+	stateCallback := func(vpnState openvpn.State) error {
+		return params.StateCallback(connectionState(vpnState))
+	}
+	vpnMiddlewares := []openvpn.ManagementMiddleware{
+		bytescount.NewMiddleware(statsHandler, statsReportInterval),
+		client_auth.NewMiddleware(credentialsProvider),
+		state.NewMiddleware(stateCallback),
+		client_dns.NewMiddleware(params.DNSProtector.Protect, params.DNSProtector.Release),
+	}
+	vpnClient := openvpn.NewClient(
+		vpnConfig,
+		params.DirectoryRuntime,
+		vpnMiddlewares...,
+	)
+	return &connection{vpnClient, remote}, nil
+}
+
+// connectionState maps state of openvpn client to state of connection, steps of establishing tunnel are all connecting
+func connectionState(vpnState openvpn.State) service.ConnectionState {
+	switch vpnState {
+	case openvpn.STATE_CONNECTED:
+		return service.ConnectionConnected
+	case openvpn.STATE_RECONNECTING:
+		return service.ConnectionReconnecting
+	case openvpn.STATE_EXITING:
+		return service.ConnectionExiting
+	}
+	return service.ConnectionConnecting
+}
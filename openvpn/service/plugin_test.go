@@ -0,0 +1,116 @@
+package service
+
+import (
+	"github.com/mysterium/node/dns"
+	"github.com/mysterium/node/firewall"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	"github.com/mysterium/node/nat"
+	"github.com/mysterium/node/openvpn"
+	openvpn_session "github.com/mysterium/node/openvpn/session"
+	"github.com/mysterium/node/service"
+	"github.com/mysterium/node/session"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newServiceParams(t *testing.T, directory string, configGenerator *session.ConfigGenerator) service.ServiceParams {
+	for _, file := range []string{"ca.crt", "ta.key"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(directory, file), []byte(file), 0600))
+	}
+
+	return service.ServiceParams{
+		ProposalID:       2,
+		ProviderID:       identity.FromAddress("provider"),
+		ServerIP:         "1.2.3.4",
+		DirectoryConfig:  directory,
+		DirectoryRuntime: directory,
+		NATService:       nat.NewServiceFake(),
+		NewSessionManager: func(generateConfig session.ConfigGenerator, killClient session.ClientKiller) session.ExpiringManager {
+			*configGenerator = generateConfig
+			return openvpn_session.NewManager(
+				generateConfig,
+				&session.UUIDGenerator{},
+				session.NewStorageMemory(),
+				openvpn_session.DefaultExpiryPolicy(),
+				openvpn_session.DefaultLimitPolicy(),
+				killClient,
+			)
+		},
+	}
+}
+
+func TestPluginCreatesServiceOfProposal(t *testing.T) {
+	directory, err := ioutil.TempDir("", "openvpn-service-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+
+	var generateConfig session.ConfigGenerator
+	params := newServiceParams(t, directory, &generateConfig)
+
+	vpnService, err := NewPlugin().NewService(params, Options{Protocol: "tcp", Port: 443, PricePerHour: money.NewMoney(0.2, money.CURRENCY_MYST)})
+	assert.NoError(t, err)
+
+	proposal := vpnService.Proposal()
+	assert.Equal(t, 2, proposal.ID)
+	assert.Equal(t, ServiceType, proposal.ServiceType)
+	assert.Equal(t, "provider", proposal.ProviderID)
+	assert.Equal(t, money.NewMoney(0.2, money.CURRENCY_MYST), proposal.PaymentMethod.GetPrice())
+
+	config, err := generateConfig()
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(config, "remote 1.2.3.4\n"), config)
+	assert.True(t, strings.Contains(config, "port 443\n"), config)
+	assert.True(t, strings.Contains(config, "proto tcp\n"), config)
+}
+
+func TestPluginRejectsOptionsOfOtherService(t *testing.T) {
+	_, err := NewPlugin().NewService(service.ServiceParams{}, "udp:1194")
+
+	assert.EqualError(t, err, "invalid options of openvpn service: udp:1194")
+}
+
+func TestPluginRejectsServiceWithoutSubnet(t *testing.T) {
+	directory, err := ioutil.TempDir("", "openvpn-service-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+
+	var generateConfig session.ConfigGenerator
+	params := newServiceParams(t, directory, &generateConfig)
+	params.ProposalID = 257
+
+	_, err = NewPlugin().NewService(params, Options{Protocol: "udp", Port: 1194})
+	assert.EqualError(t, err, "openvpn service of proposal 257 has no subnet, at most 256 services are supported")
+}
+
+func TestConnectionAllowsTunnelAndServerInKillSwitch(t *testing.T) {
+	directory, err := ioutil.TempDir("", "openvpn-service-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+
+	connection, err := NewPlugin().NewConnection(service.ConnectionParams{
+		Session:          session.SessionDto{ID: "session", Config: "client\nremote 1.2.3.4\nport 443\n"},
+		Signer:           &identity.SignerFake{},
+		DirectoryRuntime: directory,
+		DNSProtector:     dns.NewProtector(dns.NewConfiguratorFake()),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]firewall.RuleAllowed{{Interface: "tun+"}, {RemoteAddress: "1.2.3.4"}},
+		connection.KillSwitchRules(),
+	)
+}
+
+func TestConnectionStateOfOpenvpnState(t *testing.T) {
+	assert.Equal(t, service.ConnectionConnecting, connectionState(openvpn.STATE_CONNECTING))
+	assert.Equal(t, service.ConnectionConnecting, connectionState(openvpn.STATE_GET_CONFIG))
+	assert.Equal(t, service.ConnectionConnected, connectionState(openvpn.STATE_CONNECTED))
+	assert.Equal(t, service.ConnectionReconnecting, connectionState(openvpn.STATE_RECONNECTING))
+	assert.Equal(t, service.ConnectionExiting, connectionState(openvpn.STATE_EXITING))
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"github.com/mysterium/node/openvpn"
+	"github.com/mysterium/node/openvpn/middlewares/server/clients"
+	server_dto "github.com/mysterium/node/server/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+)
+
+type openvpnService struct {
+	proposal       dto_discovery.ServiceProposal
+	sessionManager session.ExpiringManager
+	server         *openvpn.Server
+	clients        clients.Registry
+}
+
+// NewService creates service which serves sessions of given manager with its own openvpn server
+func NewService(
+	proposal dto_discovery.ServiceProposal,
+	sessionManager session.ExpiringManager,
+	server *openvpn.Server,
+	clients clients.Registry,
+) *openvpnService {
+	return &openvpnService{
+		proposal:       proposal,
+		sessionManager: sessionManager,
+		server:         server,
+		clients:        clients,
+	}
+}
+
+func (service *openvpnService) Proposal() dto_discovery.ServiceProposal {
+	return service.proposal
+}
+
+func (service *openvpnService) SessionManager() session.Manager {
+	return service.sessionManager
+}
+
+// SessionStats returns traffic of sessions counted by openvpn server
+func (service *openvpnService) SessionStats() []server_dto.SessionStats {
+	sessionStats := make([]server_dto.SessionStats, 0)
+	for _, stats := range service.clients.SessionStats() {
+		sessionStats = append(sessionStats, server_dto.SessionStats{
+			SessionID:     stats.SessionID,
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+		})
+	}
+	return sessionStats
+}
+
+// AcknowledgeSessionStats lets openvpn server forget traffic of killed sessions, which is reported
+func (service *openvpnService) AcknowledgeSessionStats(reported []server_dto.SessionStats) {
+	clientStats := make([]clients.SessionStats, 0, len(reported))
+	for _, stats := range reported {
+		clientStats = append(clientStats, clients.SessionStats{
+			SessionID:     stats.SessionID,
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+		})
+	}
+	service.clients.AcknowledgeSessionStats(clientStats)
+}
+
+func (service *openvpnService) Start() error {
+	service.sessionManager.Start()
+	if err := service.server.Start(); err != nil {
+		service.sessionManager.Stop()
+		return err
+	}
+	return nil
+}
+
+func (service *openvpnService) Wait() error {
+	return service.server.Wait()
+}
+
+func (service *openvpnService) Stop() {
+	service.server.Stop()
+	service.sessionManager.Stop()
+}
@@ -1,44 +1,322 @@
 package session
 
 import (
+	log "github.com/cihub/seelog"
 	"github.com/mysterium/node/identity"
-	"github.com/mysterium/node/openvpn"
 	"github.com/mysterium/node/session"
+	"sort"
 	"sync"
+	"time"
 )
 
-// NewManager returns session manager which maintans a map of session id -> session
-func NewManager(clientConfig *openvpn.ClientConfig, idGenerator session.Generator) *manager {
+const managerLogPrefix = "[session-manager] "
+
+// lastSeenPrecision is how much session use time moves before it is saved, so that every use does not rewrite storage
+const lastSeenPrecision = time.Minute
+
+// ExpiryPolicy describes how long sessions stay valid
+type ExpiryPolicy struct {
+	// UnusedTTL is how long session stays valid after creation, when it is never used
+	UnusedTTL time.Duration
+	// IdleTTL is how long session stays valid after it was used last time
+	IdleTTL time.Duration
+	// ReapInterval is how often expired sessions are removed
+	ReapInterval time.Duration
+}
+
+// DefaultExpiryPolicy keeps idle sessions long enough to survive several openvpn renegotiations, which mark them as used
+func DefaultExpiryPolicy() ExpiryPolicy {
+	return ExpiryPolicy{
+		UnusedTTL:    5 * time.Minute,
+		IdleTTL:      10 * time.Minute,
+		ReapInterval: 1 * time.Minute,
+	}
+}
+
+// LimitPolicy describes how many sessions provider serves, zero values mean no limit
+type LimitPolicy struct {
+	// MaxSessions is how many sessions node serves at the same time
+	MaxSessions int
+	// MaxSessionsPerConsumer is how many sessions one consumer may have at the same time
+	MaxSessionsPerConsumer int
+	// CreateRate is how many sessions one consumer may create during CreateRatePeriod
+	CreateRate       int
+	CreateRatePeriod time.Duration
+}
+
+// DefaultLimitPolicy leaves room for consumer reconnects, while old sessions are not expired yet
+func DefaultLimitPolicy() LimitPolicy {
+	return LimitPolicy{
+		MaxSessions:            0,
+		MaxSessionsPerConsumer: 5,
+		CreateRate:             10,
+		CreateRatePeriod:       time.Minute,
+	}
+}
+
+// NewManager returns session manager which keeps sessions in given storage and generates their configs with given generator
+func NewManager(generateConfig session.ConfigGenerator, idGenerator session.Generator, storage session.Storage,
+	expiryPolicy ExpiryPolicy, limitPolicy LimitPolicy, killClient session.ClientKiller) *manager {
 	return &manager{
-		idGenerator:  idGenerator,
-		clientConfig: clientConfig,
-		sessionMap:   make(map[session.SessionID]session.Session),
-		creationLock: sync.Mutex{},
+		idGenerator:    idGenerator,
+		generateConfig: generateConfig,
+		storage:        storage,
+		expiryPolicy:   expiryPolicy,
+		limitPolicy:    limitPolicy,
+		killClient:     killClient,
+		timeGetter:     time.Now,
+		creations:      make(map[identity.Identity][]time.Time),
+		lock:           sync.Mutex{},
 	}
 }
 
 type manager struct {
-	idGenerator  session.Generator
-	clientConfig *openvpn.ClientConfig
-	sessionMap   map[session.SessionID]session.Session
-	creationLock sync.Mutex
+	idGenerator    session.Generator
+	generateConfig session.ConfigGenerator
+	storage        session.Storage
+	expiryPolicy   ExpiryPolicy
+	limitPolicy    LimitPolicy
+	killClient     session.ClientKiller
+	timeGetter     func() time.Time
+	// creations holds times when consumers created their sessions during last rate period
+	creations  map[identity.Identity][]time.Time
+	lock       sync.Mutex
+	reaperStop chan struct{}
 }
 
+// Create returns new session for consumer, unless it is denied by limit policy
 func (manager *manager) Create(peerID identity.Identity) (sessionInstance session.Session, err error) {
-	manager.creationLock.Lock()
-	defer manager.creationLock.Unlock()
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	now := manager.timeGetter()
+	sessions, err := manager.storage.List()
+	if err != nil {
+		return
+	}
+	if err = manager.checkLimits(sessions, peerID, now); err != nil {
+		log.Warn(managerLogPrefix, "Session denied for ", peerID.Address, ": ", err)
+		return
+	}
+
 	sessionInstance.ID = manager.idGenerator.Generate()
 	sessionInstance.ConsumerID = peerID
-	sessionInstance.Config, err = openvpn.ConfigToString(*manager.clientConfig.Config)
+	sessionInstance.Created = now
+	sessionInstance.Config, err = manager.generateConfig()
 	if err != nil {
 		return
 	}
 
-	manager.sessionMap[sessionInstance.ID] = sessionInstance
+	if err = manager.storage.Save(sessionInstance); err != nil {
+		return
+	}
+	manager.creations[peerID] = append(manager.creations[peerID], now)
 	return sessionInstance, nil
 }
 
+// FindSession returns session which is not expired yet and marks it as used, with precision of lastSeenPrecision.
+// Expired sessions are left for reaper, which disconnects their clients.
 func (manager *manager) FindSession(id session.SessionID) (session.Session, bool) {
-	sessionInstance, found := manager.sessionMap[id]
-	return sessionInstance, found
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	sessionInstance, found, err := manager.storage.Find(id)
+	if err != nil {
+		log.Error(managerLogPrefix, "Failed to find session ", id, ": ", err)
+		return session.Session{}, false
+	}
+	if !found {
+		return session.Session{}, false
+	}
+
+	now := manager.timeGetter()
+	if manager.isExpired(sessionInstance, now) {
+		return session.Session{}, false
+	}
+
+	if !sessionInstance.LastSeen.IsZero() && now.Sub(sessionInstance.LastSeen) < lastSeenPrecision {
+		return sessionInstance, true
+	}
+
+	sessionInstance.LastSeen = now
+	if err := manager.storage.Save(sessionInstance); err != nil {
+		log.Error(managerLogPrefix, "Failed to mark session ", id, " as used: ", err)
+	}
+	return sessionInstance, true
+}
+
+// Lookup returns session which is not expired yet, without marking it as used
+func (manager *manager) Lookup(id session.SessionID) (session.Session, bool) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	sessionInstance, found, err := manager.storage.Find(id)
+	if err != nil {
+		log.Error(managerLogPrefix, "Failed to find session ", id, ": ", err)
+		return session.Session{}, false
+	}
+	if !found || manager.isExpired(sessionInstance, manager.timeGetter()) {
+		return session.Session{}, false
+	}
+	return sessionInstance, true
+}
+
+// List returns sessions which are not expired yet, oldest first
+func (manager *manager) List() []session.Session {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+
+	stored, err := manager.storage.List()
+	if err != nil {
+		log.Error(managerLogPrefix, "Failed to list sessions: ", err)
+	}
+
+	now := manager.timeGetter()
+	sessions := make([]session.Session, 0, len(stored))
+	for _, sessionInstance := range stored {
+		if !manager.isExpired(sessionInstance, now) {
+			sessions = append(sessions, sessionInstance)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].Created.Equal(sessions[j].Created) {
+			return sessions[i].ID < sessions[j].ID
+		}
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+	return sessions
+}
+
+// Destroy forgets session and disconnects openvpn client which uses it, so client can not re-authenticate
+func (manager *manager) Destroy(id session.SessionID) error {
+	manager.lock.Lock()
+	_, found, err := manager.storage.Find(id)
+	if err == nil && found {
+		err = manager.storage.Delete(id)
+	}
+	manager.lock.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if !found {
+		return session.ErrSessionNotFound
+	}
+
+	log.Info(managerLogPrefix, "Session destroyed: ", id)
+	return manager.killClient(id)
+}
+
+// Start runs background removal of expired sessions
+func (manager *manager) Start() {
+	manager.reaperStop = make(chan struct{})
+	go manager.reapPeriodically(manager.reaperStop)
+}
+
+// Stop finishes background removal of expired sessions
+func (manager *manager) Stop() {
+	if manager.reaperStop != nil {
+		close(manager.reaperStop)
+		manager.reaperStop = nil
+	}
+}
+
+func (manager *manager) reapPeriodically(stop chan struct{}) {
+	ticker := time.NewTicker(manager.expiryPolicy.ReapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			manager.reapExpired()
+		}
+	}
+}
+
+// reapExpired removes all sessions which are expired at the moment and disconnects their clients
+func (manager *manager) reapExpired() {
+	manager.lock.Lock()
+	sessions, err := manager.storage.List()
+	if err != nil {
+		log.Error(managerLogPrefix, "Failed to list sessions for expiry: ", err)
+	}
+
+	now := manager.timeGetter()
+	expired := make([]session.SessionID, 0)
+	for _, sessionInstance := range sessions {
+		if !manager.isExpired(sessionInstance, now) {
+			continue
+		}
+		if err := manager.storage.Delete(sessionInstance.ID); err != nil {
+			log.Error(managerLogPrefix, "Failed to remove expired session ", sessionInstance.ID, ": ", err)
+			continue
+		}
+		expired = append(expired, sessionInstance.ID)
+		log.Info(managerLogPrefix, "Session expired: ", sessionInstance.ID)
+	}
+	for peerID := range manager.creations {
+		manager.recentCreations(peerID, now)
+	}
+	manager.lock.Unlock()
+
+	for _, id := range expired {
+		if err := manager.killClient(id); err != nil {
+			log.Warn(managerLogPrefix, "Failed to disconnect client of expired session ", id, ": ", err)
+		}
+	}
+}
+
+// checkLimits tells if consumer may create one more session, expired sessions are not counted
+func (manager *manager) checkLimits(sessions []session.Session, peerID identity.Identity, now time.Time) error {
+	policy := manager.limitPolicy
+	if policy.CreateRate > 0 && manager.recentCreations(peerID, now) >= policy.CreateRate {
+		return session.ErrRateLimited
+	}
+
+	total, consumerTotal := 0, 0
+	for _, sessionInstance := range sessions {
+		if manager.isExpired(sessionInstance, now) {
+			continue
+		}
+		total++
+		if sessionInstance.ConsumerID == peerID {
+			consumerTotal++
+		}
+	}
+
+	if policy.MaxSessionsPerConsumer > 0 && consumerTotal >= policy.MaxSessionsPerConsumer {
+		return session.ErrConsumerQuotaExceeded
+	}
+	if policy.MaxSessions > 0 && total >= policy.MaxSessions {
+		return session.ErrTooManySessions
+	}
+	return nil
+}
+
+// recentCreations forgets creations older than rate period and returns how many are left for consumer
+func (manager *manager) recentCreations(peerID identity.Identity, now time.Time) int {
+	creations := manager.creations[peerID]
+	recent := creations[:0]
+	for _, created := range creations {
+		if now.Sub(created) < manager.limitPolicy.CreateRatePeriod {
+			recent = append(recent, created)
+		}
+	}
+
+	if len(recent) == 0 {
+		delete(manager.creations, peerID)
+	} else {
+		manager.creations[peerID] = recent
+	}
+	return len(recent)
+}
+
+func (manager *manager) isExpired(sessionInstance session.Session, now time.Time) bool {
+	if sessionInstance.LastSeen.IsZero() {
+		return now.Sub(sessionInstance.Created) > manager.expiryPolicy.UnusedTTL
+	}
+	return now.Sub(sessionInstance.LastSeen) > manager.expiryPolicy.IdleTTL
 }
@@ -1,29 +1,89 @@
 package session
 
 import (
+	"fmt"
 	"github.com/mysterium/node/identity"
-	"github.com/mysterium/node/openvpn"
 	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/utils"
 	"github.com/stretchr/testify/assert"
+	"sort"
 	"testing"
+	"time"
 )
 
-func TestManagerCreatesNewSession(t *testing.T) {
-	expectedSession := session.Session{
-		ID:         session.SessionID("mocked-id"),
-		Config:     "port 1000\n",
-		ConsumerID: identity.FromAddress("deadbeef"),
+var (
+	sessionCreated = time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+	expiryPolicy   = ExpiryPolicy{
+		UnusedTTL:    time.Minute,
+		IdleTTL:      5 * time.Minute,
+		ReapInterval: time.Millisecond,
 	}
+)
+
+type clientKillerRecorder struct {
+	killedSessions []session.SessionID
+}
 
-	clientConfig := &openvpn.ClientConfig{&openvpn.Config{}}
-	clientConfig.SetPort(1000)
+func (recorder *clientKillerRecorder) killClient(sessionID session.SessionID) error {
+	recorder.killedSessions = append(recorder.killedSessions, sessionID)
+	return nil
+}
+
+type sequenceGenerator struct {
+	last int
+}
+
+func (generator *sequenceGenerator) Generate() session.SessionID {
+	generator.last++
+	return session.SessionID(fmt.Sprintf("session-%d", generator.last))
+}
 
+func newManagerWithClock(clock *utils.SettableClock) *manager {
 	manager := NewManager(
-		clientConfig,
+		func() (string, error) {
+			return "port 1000\n", nil
+		},
 		&session.GeneratorFake{
 			SessionIdMock: session.SessionID("mocked-id"),
 		},
+		session.NewStorageMemory(),
+		expiryPolicy,
+		LimitPolicy{},
+		(&clientKillerRecorder{}).killClient,
 	)
+	manager.timeGetter = clock.GetTime
+	return manager
+}
+
+func storedSessions(t *testing.T, manager *manager) map[session.SessionID]session.Session {
+	sessions, err := manager.storage.List()
+	assert.NoError(t, err)
+
+	sessionMap := make(map[session.SessionID]session.Session)
+	for _, sessionInstance := range sessions {
+		sessionMap[sessionInstance.ID] = sessionInstance
+	}
+	return sessionMap
+}
+
+func newLimitedManager(clock *utils.SettableClock, limitPolicy LimitPolicy) *manager {
+	manager := newManagerWithClock(clock)
+	manager.idGenerator = &sequenceGenerator{}
+	manager.limitPolicy = limitPolicy
+	return manager
+}
+
+func TestManagerCreatesNewSession(t *testing.T) {
+	expectedSession := session.Session{
+		ID:         session.SessionID("mocked-id"),
+		Config:     "port 1000\n",
+		ConsumerID: identity.FromAddress("deadbeef"),
+		Created:    sessionCreated,
+	}
+
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
 
 	sessionInstance, err := manager.Create(identity.FromAddress("deadbeef"))
 	assert.NoError(t, err)
@@ -34,24 +94,276 @@ func TestManagerCreatesNewSession(t *testing.T) {
 	assert.Exactly(
 		t,
 		expectedSessionMap,
-		manager.sessionMap,
+		storedSessions(t, manager),
 	)
 }
 
 func TestManagerLookupsExistingSession(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+
 	expectedSession := session.Session{
-		ID:     session.SessionID("mocked-id"),
-		Config: "port 1000\n",
+		ID:      session.SessionID("mocked-id"),
+		Config:  "port 1000\n",
+		Created: sessionCreated,
 	}
+	assert.NoError(t, manager.storage.Save(expectedSession))
 
-	sessionMap := make(map[session.SessionID]session.Session)
-	sessionMap[expectedSession.ID] = expectedSession
-
-	manager := manager{
-		sessionMap: sessionMap,
-	}
+	clock.SetTime(sessionCreated.Add(time.Second))
+	expectedSession.LastSeen = sessionCreated.Add(time.Second)
 
 	session, found := manager.FindSession(session.SessionID("mocked-id"))
 	assert.True(t, found)
 	assert.Exactly(t, expectedSession, session)
 }
+
+func TestManagerDeniesUnusedSessionAfterTTL(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	_, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+
+	clock.SetTime(sessionCreated.Add(expiryPolicy.UnusedTTL + time.Second))
+	_, found := manager.FindSession("mocked-id")
+	assert.False(t, found)
+}
+
+func TestManagerSavesSessionUseOncePerPrecision(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	_, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+
+	firstUse := sessionCreated.Add(time.Second)
+	clock.SetTime(firstUse)
+	sessionInstance, found := manager.FindSession("mocked-id")
+	assert.True(t, found)
+	assert.Equal(t, firstUse, sessionInstance.LastSeen)
+
+	clock.SetTime(firstUse.Add(lastSeenPrecision - time.Second))
+	sessionInstance, found = manager.FindSession("mocked-id")
+	assert.True(t, found)
+	assert.Equal(t, firstUse, sessionInstance.LastSeen)
+
+	laterUse := firstUse.Add(lastSeenPrecision)
+	clock.SetTime(laterUse)
+	manager.FindSession("mocked-id")
+	stored, _, err := manager.storage.Find("mocked-id")
+	assert.NoError(t, err)
+	assert.Equal(t, laterUse, stored.LastSeen)
+}
+
+func TestManagerLookupDoesNotMarkSessionAsUsed(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	_, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+
+	clock.SetTime(sessionCreated.Add(time.Second))
+	sessionInstance, found := manager.Lookup("mocked-id")
+	assert.True(t, found)
+	assert.True(t, sessionInstance.LastSeen.IsZero())
+
+	clock.SetTime(sessionCreated.Add(expiryPolicy.UnusedTTL + time.Second))
+	_, found = manager.Lookup("mocked-id")
+	assert.False(t, found)
+}
+
+func TestManagerKeepsUsedSessionUntilIdleTTL(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	_, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+
+	_, found := manager.FindSession("mocked-id")
+	assert.True(t, found)
+
+	clock.SetTime(sessionCreated.Add(expiryPolicy.IdleTTL))
+	_, found = manager.FindSession("mocked-id")
+	assert.True(t, found)
+
+	clock.SetTime(sessionCreated.Add(2*expiryPolicy.IdleTTL + time.Second))
+	_, found = manager.FindSession("mocked-id")
+	assert.False(t, found)
+}
+
+func TestManagerReapsExpiredSessions(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	killer := &clientKillerRecorder{}
+	manager.killClient = killer.killClient
+	manager.storage.Save(session.Session{ID: "unused", Created: sessionCreated})
+	manager.storage.Save(session.Session{ID: "idle", Created: sessionCreated, LastSeen: sessionCreated})
+	manager.storage.Save(session.Session{ID: "active", Created: sessionCreated, LastSeen: sessionCreated.Add(3 * time.Minute)})
+
+	clock.SetTime(sessionCreated.Add(6 * time.Minute))
+	manager.reapExpired()
+
+	assert.Equal(
+		t,
+		map[session.SessionID]session.Session{
+			"active": {ID: "active", Created: sessionCreated, LastSeen: sessionCreated.Add(3 * time.Minute)},
+		},
+		storedSessions(t, manager),
+	)
+	sort.Slice(killer.killedSessions, func(i, j int) bool {
+		return killer.killedSessions[i] < killer.killedSessions[j]
+	})
+	assert.Equal(t, []session.SessionID{"idle", "unused"}, killer.killedSessions)
+}
+
+func TestManagerReapsExpiredSessionsInBackground(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	_, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+	clock.SetTime(sessionCreated.Add(expiryPolicy.UnusedTTL + time.Second))
+
+	manager.Start()
+	defer manager.Stop()
+
+	for i := 0; i < 100; i++ {
+		if len(storedSessions(t, manager)) == 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("expired session was not reaped")
+}
+
+func TestManagerDestroysSessionAndKillsItsClient(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	killer := &clientKillerRecorder{}
+	manager.killClient = killer.killClient
+	_, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, manager.Destroy("mocked-id"))
+	assert.Equal(t, []session.SessionID{"mocked-id"}, killer.killedSessions)
+
+	_, found := manager.FindSession("mocked-id")
+	assert.False(t, found)
+}
+
+func TestManagerFailsToDestroyUnknownSession(t *testing.T) {
+	manager := newManagerWithClock(&utils.SettableClock{})
+	killer := &clientKillerRecorder{}
+	manager.killClient = killer.killClient
+
+	assert.Equal(t, session.ErrSessionNotFound, manager.Destroy("unknown-id"))
+	assert.Empty(t, killer.killedSessions)
+}
+
+func TestManagerLimitsSessionsOfNode(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newLimitedManager(clock, LimitPolicy{MaxSessions: 2})
+
+	_, err := manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+	_, err = manager.Create(identity.FromAddress("consumer-2"))
+	assert.NoError(t, err)
+
+	_, err = manager.Create(identity.FromAddress("consumer-3"))
+	assert.Equal(t, session.ErrTooManySessions, err)
+	assert.Len(t, storedSessions(t, manager), 2)
+}
+
+func TestManagerLimitsSessionsOfConsumer(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newLimitedManager(clock, LimitPolicy{MaxSessionsPerConsumer: 1})
+
+	_, err := manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+
+	_, err = manager.Create(identity.FromAddress("consumer-1"))
+	assert.Equal(t, session.ErrConsumerQuotaExceeded, err)
+
+	_, err = manager.Create(identity.FromAddress("consumer-2"))
+	assert.NoError(t, err)
+}
+
+func TestManagerDoesNotCountExpiredAndDestroyedSessionsToLimits(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newLimitedManager(clock, LimitPolicy{MaxSessions: 1, MaxSessionsPerConsumer: 1})
+
+	sessionInstance, err := manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+	assert.NoError(t, manager.Destroy(sessionInstance.ID))
+
+	_, err = manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+
+	clock.SetTime(sessionCreated.Add(expiryPolicy.UnusedTTL + time.Second))
+	_, err = manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+}
+
+func TestManagerLimitsSessionCreationRateOfConsumer(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newLimitedManager(clock, LimitPolicy{CreateRate: 2, CreateRatePeriod: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		sessionInstance, err := manager.Create(identity.FromAddress("consumer-1"))
+		assert.NoError(t, err)
+		assert.NoError(t, manager.Destroy(sessionInstance.ID))
+	}
+
+	_, err := manager.Create(identity.FromAddress("consumer-1"))
+	assert.Equal(t, session.ErrRateLimited, err)
+	_, err = manager.Create(identity.FromAddress("consumer-2"))
+	assert.NoError(t, err)
+
+	clock.SetTime(sessionCreated.Add(time.Minute))
+	_, err = manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+}
+
+func TestManagerListsActiveSessionsOldestFirst(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newLimitedManager(clock, LimitPolicy{})
+
+	_, err := manager.Create(identity.FromAddress("consumer-1"))
+	assert.NoError(t, err)
+	clock.SetTime(sessionCreated.Add(expiryPolicy.UnusedTTL))
+	_, err = manager.Create(identity.FromAddress("consumer-2"))
+	assert.NoError(t, err)
+	_, err = manager.Create(identity.FromAddress("consumer-3"))
+	assert.NoError(t, err)
+
+	clock.SetTime(sessionCreated.Add(expiryPolicy.UnusedTTL + time.Second))
+	sessions := manager.List()
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, session.SessionID("session-2"), sessions[0].ID)
+	assert.Equal(t, session.SessionID("session-3"), sessions[1].ID)
+}
+
+func TestManagerFindsSessionsOfPreviousManagerInStorage(t *testing.T) {
+	clock := &utils.SettableClock{}
+	clock.SetTime(sessionCreated)
+	manager := newManagerWithClock(clock)
+	sessionInstance, err := manager.Create(identity.FromAddress("deadbeef"))
+	assert.NoError(t, err)
+
+	restartedManager := newManagerWithClock(clock)
+	restartedManager.storage = manager.storage
+
+	clock.SetTime(sessionCreated.Add(time.Second))
+	restoredSession, found := restartedManager.FindSession(sessionInstance.ID)
+	assert.True(t, found)
+	assert.Equal(t, sessionInstance.ID, restoredSession.ID)
+	assert.Equal(t, identity.FromAddress("deadbeef"), restoredSession.ConsumerID)
+}
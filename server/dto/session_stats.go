@@ -1,6 +1,10 @@
 package dto
 
+import "github.com/mysterium/node/session"
+
 type SessionStats struct {
-	BytesSent     int `json:"bytes_sent"`
-	BytesReceived int `json:"bytes_received"`
+	// SessionID identifies session on provider's side, it is not reported
+	SessionID     session.SessionID `json:"-"`
+	BytesSent     int               `json:"bytes_sent"`
+	BytesReceived int               `json:"bytes_received"`
 }
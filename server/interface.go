@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"github.com/mysterium/node/identity"
 	"github.com/mysterium/node/server/dto"
 	dto_discovery "github.com/mysterium/node/service_discovery/dto"
@@ -8,10 +9,16 @@ import (
 
 //Client interface for mysterium centralized api - will be removed in the future
 type Client interface {
-	FindProposals(nodeKey string) (proposals []dto_discovery.ServiceProposal, err error)
+	FindProposals(ctx context.Context, nodeKey string) (proposals []dto_discovery.ServiceProposal, err error)
 	//these functions are signed because they require authorization
 	RegisterIdentity(identity identity.Identity, signer identity.Signer) (err error)
 	RegisterProposal(proposal dto_discovery.ServiceProposal, signer identity.Signer) (err error)
-	NodeSendStats(nodeKey string, signer identity.Signer) (err error)
+	NodeSendStats(nodeKey string, sessions []dto.SessionStats, signer identity.Signer) (err error)
 	SendSessionStats(sessionId string, sessionStats dto.SessionStats, signer identity.Signer) (err error)
 }
+
+// StatsReporter sends statistics of node's sessions in background, once started
+type StatsReporter interface {
+	Start()
+	Stop()
+}
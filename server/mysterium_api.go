@@ -1,12 +1,14 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	log "github.com/cihub/seelog"
 	"github.com/mysterium/node/identity"
 	"github.com/mysterium/node/server/dto"
+	"github.com/mysterium/node/service_discovery"
 	dto_discovery "github.com/mysterium/node/service_discovery/dto"
 	"net/url"
 )
@@ -49,6 +51,11 @@ func (mApi *mysteriumAPI) RegisterIdentity(identity identity.Identity, signer id
 }
 
 func (mApi *mysteriumAPI) RegisterProposal(proposal dto_discovery.ServiceProposal, signer identity.Signer) error {
+	proposal, err := service_discovery.SignProposal(proposal, signer)
+	if err != nil {
+		return err
+	}
+
 	req, err := newSignedPostRequest("node_register", dto.NodeRegisterRequest{
 		ServiceProposal: proposal,
 	}, signer)
@@ -64,11 +71,10 @@ func (mApi *mysteriumAPI) RegisterProposal(proposal dto_discovery.ServiceProposa
 	return err
 }
 
-func (mApi *mysteriumAPI) NodeSendStats(nodeKey string, signer identity.Signer) error {
+func (mApi *mysteriumAPI) NodeSendStats(nodeKey string, sessions []dto.SessionStats, signer identity.Signer) error {
 	req, err := newSignedPostRequest("node_send_stats", dto.NodeStatsRequest{
-		NodeKey: nodeKey,
-		// TODO Refactor Node statistics with new `SessionStats` DTO
-		Sessions: []dto.SessionStats{},
+		NodeKey:  nodeKey,
+		Sessions: sessions,
 	}, signer)
 	if err != nil {
 		return err
@@ -81,13 +87,14 @@ func (mApi *mysteriumAPI) NodeSendStats(nodeKey string, signer identity.Signer)
 	return err
 }
 
-func (mApi *mysteriumAPI) FindProposals(nodeKey string) ([]dto_discovery.ServiceProposal, error) {
+func (mApi *mysteriumAPI) FindProposals(ctx context.Context, nodeKey string) ([]dto_discovery.ServiceProposal, error) {
 	values := url.Values{}
 	values.Set("node_key", nodeKey)
 	req, err := newGetRequest("proposals", values)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(ctx)
 
 	var proposalsResponse dto.ProposalsResponse
 	err = mApi.doRequestAndParseResponse(req, &proposalsResponse)
@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"github.com/mysterium/node/server/dto"
+	"github.com/mysterium/node/service_discovery"
 
 	log "github.com/cihub/seelog"
 	"github.com/mysterium/node/identity"
@@ -20,6 +22,11 @@ type ClientFake struct {
 }
 
 func (client *ClientFake) RegisterProposal(proposal dto_discovery.ServiceProposal, signer identity.Signer) (err error) {
+	if signer != nil {
+		if proposal, err = service_discovery.SignProposal(proposal, signer); err != nil {
+			return err
+		}
+	}
 	client.proposalsMock = append(client.proposalsMock, proposal)
 	log.Info(mysteriumAPILogPrefix, "Fake node registered: ", proposal)
 
@@ -33,14 +40,17 @@ func (client *ClientFake) RegisterIdentity(newIdentity identity.Identity, signer
 	return nil
 }
 
-func (client *ClientFake) NodeSendStats(nodeKey string, signer identity.Signer) (err error) {
+func (client *ClientFake) NodeSendStats(nodeKey string, sessions []dto.SessionStats, signer identity.Signer) (err error) {
 	log.Info(mysteriumAPILogPrefix, "Node stats sent: ", nodeKey)
 
 	return nil
 }
 
-func (client *ClientFake) FindProposals(nodeKey string) (proposals []dto_discovery.ServiceProposal, err error) {
+func (client *ClientFake) FindProposals(ctx context.Context, nodeKey string) (proposals []dto_discovery.ServiceProposal, err error) {
 	log.Info(mysteriumAPILogPrefix, "Fake proposals requested for node_key: ", nodeKey)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	for _, proposal := range client.proposalsMock {
 		var filterMatched = true
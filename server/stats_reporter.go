@@ -0,0 +1,121 @@
+package server
+
+import (
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/server/dto"
+	"sync"
+	"time"
+)
+
+const statsReporterLogPrefix = "[stats-reporter] "
+
+// SessionStatsGetter returns current statistics of node's sessions
+type SessionStatsGetter func() []dto.SessionStats
+
+// SessionStatsAcknowledger is told which statistics of node's sessions are reported
+type SessionStatsAcknowledger func(reported []dto.SessionStats)
+
+// ReportPolicy describes how often node statistics are sent and how failed sending is retried
+type ReportPolicy struct {
+	Interval      time.Duration
+	RetryInterval time.Duration
+	MaxRetries    int
+}
+
+// DefaultReportPolicy retries few times, but gives up before next report is due
+func DefaultReportPolicy() ReportPolicy {
+	return ReportPolicy{
+		Interval:      1 * time.Minute,
+		RetryInterval: 10 * time.Second,
+		MaxRetries:    3,
+	}
+}
+
+// NewStatsReporter creates reporter which sends statistics of all node's sessions in single request
+func NewStatsReporter(
+	client Client,
+	nodeKey string,
+	signer identity.Signer,
+	getStats SessionStatsGetter,
+	acknowledgeStats SessionStatsAcknowledger,
+	policy ReportPolicy,
+) *statsReporter {
+	return &statsReporter{
+		client:           client,
+		nodeKey:          nodeKey,
+		signer:           signer,
+		getStats:         getStats,
+		acknowledgeStats: acknowledgeStats,
+		policy:           policy,
+	}
+}
+
+type statsReporter struct {
+	client           Client
+	nodeKey          string
+	signer           identity.Signer
+	getStats         SessionStatsGetter
+	acknowledgeStats SessionStatsAcknowledger
+	policy           ReportPolicy
+
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// Start sends statistics periodically in background
+func (reporter *statsReporter) Start() {
+	reporter.stop = make(chan struct{})
+	reporter.stopped.Add(1)
+	go reporter.reportPeriodically(reporter.stop)
+}
+
+// Stop finishes sending statistics and waits until report being sent is done
+func (reporter *statsReporter) Stop() {
+	if reporter.stop == nil {
+		return
+	}
+	close(reporter.stop)
+	reporter.stop = nil
+	reporter.stopped.Wait()
+}
+
+func (reporter *statsReporter) reportPeriodically(stop chan struct{}) {
+	defer reporter.stopped.Done()
+
+	ticker := time.NewTicker(reporter.policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			reporter.report(stop)
+		}
+	}
+}
+
+// report sends current statistics, retrying until it succeeds, retries are exhausted or reporter is stopped.
+// Sent statistics are acknowledged, so that traffic of killed sessions is not reported again.
+func (reporter *statsReporter) report(stop chan struct{}) {
+	sessions := reporter.getStats()
+	for attempt := 0; ; attempt++ {
+		err := reporter.client.NodeSendStats(reporter.nodeKey, sessions, reporter.signer)
+		if err == nil {
+			reporter.acknowledgeStats(sessions)
+			return
+		}
+		if attempt >= reporter.policy.MaxRetries {
+			log.Error(statsReporterLogPrefix, "Giving up sending node stats: ", err)
+			return
+		}
+		log.Warn(statsReporterLogPrefix, "Failed to send node stats, retrying: ", err)
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(reporter.policy.RetryInterval):
+		}
+	}
+}
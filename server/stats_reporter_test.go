@@ -0,0 +1,119 @@
+package server
+
+import (
+	"errors"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/server/dto"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	reportPolicy = ReportPolicy{
+		Interval:      time.Millisecond,
+		RetryInterval: time.Millisecond,
+		MaxRetries:    2,
+	}
+	sessionStats = []dto.SessionStats{
+		{BytesSent: 10, BytesReceived: 100},
+		{BytesSent: 20, BytesReceived: 200},
+	}
+)
+
+type statsClientRecorder struct {
+	*ClientFake
+	mutex    sync.Mutex
+	failures int
+	attempts int
+	sent     [][]dto.SessionStats
+	// acknowledged are statistics which reporter has acknowledged as reported
+	acknowledged [][]dto.SessionStats
+}
+
+func (client *statsClientRecorder) NodeSendStats(nodeKey string, sessions []dto.SessionStats, signer identity.Signer) error {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	client.attempts++
+	if client.attempts <= client.failures {
+		return errors.New("api is down")
+	}
+	client.sent = append(client.sent, sessions)
+	return nil
+}
+
+func (client *statsClientRecorder) getAttempts() int {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	return client.attempts
+}
+
+func (client *statsClientRecorder) acknowledge(reported []dto.SessionStats) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+	client.acknowledged = append(client.acknowledged, reported)
+}
+
+func newStatsReporter(client *statsClientRecorder) *statsReporter {
+	getStats := func() []dto.SessionStats {
+		return sessionStats
+	}
+	return NewStatsReporter(client, "node-key", &identity.SignerFake{}, getStats, client.acknowledge, reportPolicy)
+}
+
+func TestStatsReporterSendsAllSessionsInSingleRequest(t *testing.T) {
+	client := &statsClientRecorder{ClientFake: NewClientFake()}
+
+	newStatsReporter(client).report(make(chan struct{}))
+
+	assert.Equal(t, 1, client.attempts)
+	assert.Equal(t, [][]dto.SessionStats{sessionStats}, client.sent)
+	assert.Equal(t, [][]dto.SessionStats{sessionStats}, client.acknowledged)
+}
+
+func TestStatsReporterRetriesFailedReport(t *testing.T) {
+	client := &statsClientRecorder{ClientFake: NewClientFake(), failures: 2}
+
+	newStatsReporter(client).report(make(chan struct{}))
+
+	assert.Equal(t, 3, client.attempts)
+	assert.Equal(t, [][]dto.SessionStats{sessionStats}, client.sent)
+}
+
+func TestStatsReporterGivesUpAfterMaxRetries(t *testing.T) {
+	client := &statsClientRecorder{ClientFake: NewClientFake(), failures: 5}
+
+	newStatsReporter(client).report(make(chan struct{}))
+
+	assert.Equal(t, 3, client.attempts)
+	assert.Empty(t, client.sent)
+	assert.Empty(t, client.acknowledged)
+}
+
+func TestStatsReporterStopsRetryingWhenStopped(t *testing.T) {
+	client := &statsClientRecorder{ClientFake: NewClientFake(), failures: 5}
+	stop := make(chan struct{})
+	close(stop)
+
+	newStatsReporter(client).report(stop)
+
+	assert.Equal(t, 1, client.attempts)
+}
+
+func TestStatsReporterReportsPeriodicallyUntilStopped(t *testing.T) {
+	client := &statsClientRecorder{ClientFake: NewClientFake()}
+	reporter := newStatsReporter(client)
+
+	reporter.Start()
+	for i := 0; i < 100 && client.getAttempts() < 2; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	reporter.Stop()
+
+	attempts := client.getAttempts()
+	assert.True(t, attempts >= 2)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, attempts, client.getAttempts())
+}
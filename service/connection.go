@@ -0,0 +1,27 @@
+package service
+
+// ConnectionState is state of consumer's connection, common to connections of all service types
+type ConnectionState string
+
+const (
+	// ConnectionConnecting is reported while connection is being established
+	ConnectionConnecting = ConnectionState("CONNECTING")
+	// ConnectionConnected is reported when traffic can go through connection
+	ConnectionConnected = ConnectionState("CONNECTED")
+	// ConnectionReconnecting is reported when connection is lost and is being established again
+	ConnectionReconnecting = ConnectionState("RECONNECTING")
+	// ConnectionExiting is reported when connection is closing and will not be established again
+	ConnectionExiting = ConnectionState("EXITING")
+)
+
+// ConnectionStateCallback is invoked when connection changes its state
+type ConnectionStateCallback func(state ConnectionState) error
+
+// ConnectionStats is traffic which went through connection since it was started
+type ConnectionStats struct {
+	BytesSent     int
+	BytesReceived int
+}
+
+// ConnectionStatsHandler is invoked periodically with current traffic of connection
+type ConnectionStatsHandler func(stats ConnectionStats) error
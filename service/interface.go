@@ -0,0 +1,85 @@
+package service
+
+import (
+	"github.com/mysterium/node/dns"
+	"github.com/mysterium/node/firewall"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/nat"
+	server_dto "github.com/mysterium/node/server/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+)
+
+// Service is one of services which provider runs, consumers create its sessions by its proposal
+type Service interface {
+	Proposal() dto_discovery.ServiceProposal
+	SessionManager() session.Manager
+	SessionStats() []server_dto.SessionStats
+	// AcknowledgeSessionStats tells that given statistics are reported, so that traffic of killed sessions can be forgotten
+	AcknowledgeSessionStats(reported []server_dto.SessionStats)
+	Start() error
+	Wait() error
+	Stop()
+}
+
+// Registry keeps services of provider and manages their lifecycle together
+type Registry interface {
+	Add(Service) error
+	Services() []Service
+	SessionManagers() session.Managers
+	SessionStats() []server_dto.SessionStats
+	AcknowledgeSessionStats(reported []server_dto.SessionStats)
+	Start() error
+	Wait() error
+	Stop()
+}
+
+// Plugin plugs type of service into node: provider runs services of the type and consumer connects to them
+type Plugin interface {
+	// ServiceType is the type of proposals which services of plugin serve
+	ServiceType() string
+	// ParseOptions parses options of one service given in command line
+	ParseOptions(value string) (Options, error)
+	// NewService creates provider's service, which serves proposal described by given params
+	NewService(params ServiceParams, options Options) (Service, error)
+	// NewConnection creates consumer's connection to service, using session which provider has created
+	NewConnection(params ConnectionParams) (Connection, error)
+}
+
+// Options are options of one service, specific to its type
+type Options interface{}
+
+// SessionManagerFactory creates manager, which keeps and limits sessions of one service
+type SessionManagerFactory func(generateConfig session.ConfigGenerator, killClient session.ClientKiller) session.ExpiringManager
+
+// ServiceParams describe proposal which service serves and provider's environment it runs in
+type ServiceParams struct {
+	ProposalID        int
+	ProviderID        identity.Identity
+	ProviderContact   dto_discovery.Contact
+	Location          dto_discovery.Location
+	ServerIP          string
+	DirectoryConfig   string
+	DirectoryRuntime  string
+	NATService        nat.NATService
+	NewSessionManager SessionManagerFactory
+}
+
+// ConnectionParams describe session which consumer connects with and consumer's environment
+type ConnectionParams struct {
+	Session          session.SessionDto
+	Signer           identity.Signer
+	DirectoryRuntime string
+	DNSProtector     dns.Protector
+	StateCallback    ConnectionStateCallback
+	StatsHandler     ConnectionStatsHandler
+}
+
+// Connection is consumer's connection to service
+type Connection interface {
+	Start() error
+	Wait() error
+	Stop() error
+	// KillSwitchRules lists traffic, which connection needs while kill switch blocks everything else
+	KillSwitchRules() []firewall.RuleAllowed
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"sync"
+)
+
+var (
+	pluginsMutex sync.RWMutex
+	plugins      = make(map[string]Plugin)
+)
+
+// RegisterPlugin makes services of plugin's type available to provider and consumer
+func RegisterPlugin(plugin Plugin) {
+	pluginsMutex.Lock()
+	defer pluginsMutex.Unlock()
+
+	plugins[plugin.ServiceType()] = plugin
+}
+
+// FindPlugin returns plugin which was registered for given service type
+func FindPlugin(serviceType string) (Plugin, bool) {
+	pluginsMutex.RLock()
+	defer pluginsMutex.RUnlock()
+
+	plugin, found := plugins[serviceType]
+	return plugin, found
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type pluginFake struct {
+	serviceType string
+}
+
+func (plugin *pluginFake) ServiceType() string {
+	return plugin.serviceType
+}
+
+func (plugin *pluginFake) ParseOptions(value string) (Options, error) {
+	return value, nil
+}
+
+func (plugin *pluginFake) NewService(params ServiceParams, options Options) (Service, error) {
+	return NewServiceFake(params.ProposalID), nil
+}
+
+func (plugin *pluginFake) NewConnection(params ConnectionParams) (Connection, error) {
+	return nil, nil
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	plugin := &pluginFake{serviceType: "fake"}
+	RegisterPlugin(plugin)
+
+	registered, found := FindPlugin("fake")
+	assert.True(t, found)
+	assert.Exactly(t, plugin, registered)
+
+	_, found = FindPlugin("unknown")
+	assert.False(t, found)
+}
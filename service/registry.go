@@ -0,0 +1,114 @@
+package service
+
+import (
+	"fmt"
+	server_dto "github.com/mysterium/node/server/dto"
+	"github.com/mysterium/node/session"
+	"sync"
+)
+
+type registry struct {
+	mutex    sync.Mutex
+	services []Service
+	started  []Service
+}
+
+// NewRegistry creates empty registry of services
+func NewRegistry() *registry {
+	return &registry{
+		services: make([]Service, 0),
+	}
+}
+
+// Add registers service, proposals of services must have different ids
+func (r *registry) Add(service Service) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	proposalID := service.Proposal().ID
+	for _, existing := range r.services {
+		if existing.Proposal().ID == proposalID {
+			return fmt.Errorf("service of proposal %d is already registered", proposalID)
+		}
+	}
+	r.services = append(r.services, service)
+	return nil
+}
+
+// Services returns registered services in order they were added
+func (r *registry) Services() []Service {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	services := make([]Service, len(r.services))
+	copy(services, r.services)
+	return services
+}
+
+// SessionManagers returns session managers of services keyed by ids of their proposals
+func (r *registry) SessionManagers() session.Managers {
+	managers := make(session.Managers)
+	for _, service := range r.Services() {
+		managers[service.Proposal().ID] = service.SessionManager()
+	}
+	return managers
+}
+
+// SessionStats returns traffic of sessions of all services
+func (r *registry) SessionStats() []server_dto.SessionStats {
+	sessionStats := make([]server_dto.SessionStats, 0)
+	for _, service := range r.Services() {
+		sessionStats = append(sessionStats, service.SessionStats()...)
+	}
+	return sessionStats
+}
+
+// AcknowledgeSessionStats passes reported statistics to all services
+func (r *registry) AcknowledgeSessionStats(reported []server_dto.SessionStats) {
+	for _, service := range r.Services() {
+		service.AcknowledgeSessionStats(reported)
+	}
+}
+
+// Start starts all services, already started ones are stopped when one of them fails to start
+func (r *registry) Start() error {
+	for _, service := range r.Services() {
+		if err := service.Start(); err != nil {
+			r.Stop()
+			return err
+		}
+
+		r.mutex.Lock()
+		r.started = append(r.started, service)
+		r.mutex.Unlock()
+	}
+	return nil
+}
+
+// Wait blocks until any of services stops and returns its error
+func (r *registry) Wait() error {
+	services := r.Services()
+	if len(services) == 0 {
+		return nil
+	}
+
+	errs := make(chan error, len(services))
+	for _, service := range services {
+		go func(service Service) {
+			errs <- service.Wait()
+		}(service)
+	}
+	return <-errs
+}
+
+// Stop stops started services in reverse order
+func (r *registry) Stop() {
+	r.mutex.Lock()
+	started := r.started
+	r.started = nil
+	r.mutex.Unlock()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		started[i].Stop()
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	server_dto "github.com/mysterium/node/server/dto"
+	"github.com/mysterium/node/session"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegistry_AddRejectsDuplicateProposal(t *testing.T) {
+	var _ Registry = NewRegistry()
+	registry := NewRegistry()
+
+	assert.NoError(t, registry.Add(NewServiceFake(1)))
+	assert.NoError(t, registry.Add(NewServiceFake(2)))
+	assert.EqualError(t, registry.Add(NewServiceFake(1)), "service of proposal 1 is already registered")
+	assert.Len(t, registry.Services(), 2)
+}
+
+func TestRegistry_SessionManagersAreKeyedByProposal(t *testing.T) {
+	udpService := NewServiceFake(1)
+	tcpService := NewServiceFake(2)
+	registry := NewRegistry()
+	registry.Add(udpService)
+	registry.Add(tcpService)
+
+	assert.Equal(
+		t,
+		session.Managers{1: udpService.Manager, 2: tcpService.Manager},
+		registry.SessionManagers(),
+	)
+}
+
+func TestRegistry_SessionStatsOfAllServices(t *testing.T) {
+	udpService := NewServiceFake(1)
+	udpService.Stats = []server_dto.SessionStats{{BytesSent: 1, BytesReceived: 2}}
+	tcpService := NewServiceFake(2)
+	tcpService.Stats = []server_dto.SessionStats{{BytesSent: 3, BytesReceived: 4}}
+	registry := NewRegistry()
+	registry.Add(udpService)
+	registry.Add(tcpService)
+
+	assert.Equal(
+		t,
+		[]server_dto.SessionStats{
+			{BytesSent: 1, BytesReceived: 2},
+			{BytesSent: 3, BytesReceived: 4},
+		},
+		registry.SessionStats(),
+	)
+}
+
+func TestRegistry_AcknowledgeSessionStatsOfAllServices(t *testing.T) {
+	udpService := NewServiceFake(1)
+	tcpService := NewServiceFake(2)
+	registry := NewRegistry()
+	registry.Add(udpService)
+	registry.Add(tcpService)
+
+	reported := []server_dto.SessionStats{{SessionID: "session-1", BytesSent: 1, BytesReceived: 2}}
+	registry.AcknowledgeSessionStats(reported)
+
+	assert.Equal(t, reported, udpService.Acknowledged)
+	assert.Equal(t, reported, tcpService.Acknowledged)
+}
+
+func TestRegistry_StartAndStopAllServices(t *testing.T) {
+	udpService := NewServiceFake(1)
+	tcpService := NewServiceFake(2)
+	registry := NewRegistry()
+	registry.Add(udpService)
+	registry.Add(tcpService)
+
+	assert.NoError(t, registry.Start())
+	assert.True(t, udpService.Started)
+	assert.True(t, tcpService.Started)
+
+	registry.Stop()
+	assert.True(t, udpService.Stopped)
+	assert.True(t, tcpService.Stopped)
+}
+
+func TestRegistry_StartStopsStartedServicesWhenOneFails(t *testing.T) {
+	udpService := NewServiceFake(1)
+	tcpService := NewServiceFake(2)
+	tcpService.StartError = errors.New("port is in use")
+	otherService := NewServiceFake(3)
+	registry := NewRegistry()
+	registry.Add(udpService)
+	registry.Add(tcpService)
+	registry.Add(otherService)
+
+	assert.EqualError(t, registry.Start(), "port is in use")
+	assert.True(t, udpService.Stopped)
+	assert.False(t, tcpService.Stopped)
+	assert.False(t, otherService.Started)
+}
+
+func TestRegistry_WaitReturnsWhenAnyServiceExits(t *testing.T) {
+	udpService := NewServiceFake(1)
+	tcpService := NewServiceFake(2)
+	registry := NewRegistry()
+	registry.Add(udpService)
+	registry.Add(tcpService)
+
+	tcpService.Exit(errors.New("openvpn exited"))
+
+	assert.EqualError(t, registry.Wait(), "openvpn exited")
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	server_dto "github.com/mysterium/node/server/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+)
+
+// ServiceFake represents fake service usually useful in tests
+type ServiceFake struct {
+	ServiceProposal dto_discovery.ServiceProposal
+	Manager         session.Manager
+	Stats           []server_dto.SessionStats
+	Acknowledged    []server_dto.SessionStats
+	// StartError is returned by Start instead of starting the service, when set
+	StartError error
+	Started    bool
+	Stopped    bool
+
+	exited chan error
+}
+
+// NewServiceFake creates fake service serving proposal with given id
+func NewServiceFake(proposalID int) *ServiceFake {
+	return &ServiceFake{
+		ServiceProposal: dto_discovery.ServiceProposal{ID: proposalID},
+		Manager:         &session.ManagerFake{},
+		exited:          make(chan error, 1),
+	}
+}
+
+// Proposal returns preset proposal
+func (service *ServiceFake) Proposal() dto_discovery.ServiceProposal {
+	return service.ServiceProposal
+}
+
+// SessionManager returns preset session manager
+func (service *ServiceFake) SessionManager() session.Manager {
+	return service.Manager
+}
+
+// SessionStats returns preset statistics
+func (service *ServiceFake) SessionStats() []server_dto.SessionStats {
+	return service.Stats
+}
+
+// AcknowledgeSessionStats remembers statistics which were reported last
+func (service *ServiceFake) AcknowledgeSessionStats(reported []server_dto.SessionStats) {
+	service.Acknowledged = reported
+}
+
+// Start marks service as started, unless start error is set
+func (service *ServiceFake) Start() error {
+	if service.StartError != nil {
+		return service.StartError
+	}
+	service.Started = true
+	return nil
+}
+
+// Wait blocks until service exits
+func (service *ServiceFake) Wait() error {
+	return <-service.exited
+}
+
+// Exit makes service exit with given error
+func (service *ServiceFake) Exit(err error) {
+	service.exited <- err
+}
+
+// Stop marks service as stopped
+func (service *ServiceFake) Stop() {
+	service.Stopped = true
+}
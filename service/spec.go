@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// keyUnsafeRune matches characters of options which are not safe to appear in file names
+var keyUnsafeRune = regexp.MustCompile(`[^A-Za-z0-9.]`)
+
+// Spec describes one service in command line: its type followed by options e.g. "openvpn:udp:1194"
+type Spec struct {
+	ServiceType string
+	Options     string
+}
+
+// Key identifies service by its type and options, so that state of service e.g. its sessions can be kept
+// in files which stay with the same service when services are reordered, e.g. "openvpn-udp-1194"
+func (spec Spec) Key() string {
+	if spec.Options == "" {
+		return spec.ServiceType
+	}
+	return spec.ServiceType + "-" + keyUnsafeRune.ReplaceAllString(spec.Options, "-")
+}
+
+// ParseSpecs parses comma separated services e.g. "openvpn:udp:1194,openvpn:tcp:443"
+func ParseSpecs(value string) ([]Spec, error) {
+	specs := make([]Spec, 0)
+	keys := make(map[string]bool)
+	for _, specValue := range strings.Split(value, ",") {
+		specValue = strings.TrimSpace(specValue)
+		if specValue == "" {
+			return nil, fmt.Errorf("invalid services %q, service type is missing", value)
+		}
+
+		fields := strings.SplitN(specValue, ":", 2)
+		spec := Spec{ServiceType: fields[0]}
+		if len(fields) == 2 {
+			spec.Options = fields[1]
+		}
+		if keys[spec.Key()] {
+			return nil, fmt.Errorf("invalid services %q, service %q is given twice", value, specValue)
+		}
+		keys[spec.Key()] = true
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs("openvpn:udp:1194, openvpn:tcp:443:0.2,socks5")
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]Spec{
+			{ServiceType: "openvpn", Options: "udp:1194"},
+			{ServiceType: "openvpn", Options: "tcp:443:0.2"},
+			{ServiceType: "socks5"},
+		},
+		specs,
+	)
+}
+
+func TestParseSpecsWithMissingType(t *testing.T) {
+	_, err := ParseSpecs("openvpn:udp:1194,")
+
+	assert.EqualError(t, err, `invalid services "openvpn:udp:1194,", service type is missing`)
+}
+
+func TestParseSpecsWithRepeatedService(t *testing.T) {
+	_, err := ParseSpecs("openvpn:udp:1194,openvpn:udp:1194")
+
+	assert.EqualError(t, err, `invalid services "openvpn:udp:1194,openvpn:udp:1194", service "openvpn:udp:1194" is given twice`)
+}
+
+func TestSpecKey(t *testing.T) {
+	assert.Equal(t, "openvpn-tcp-443-0.2", Spec{ServiceType: "openvpn", Options: "tcp:443:0.2"}.Key())
+	assert.Equal(t, "openvpn-..-..-etc", Spec{ServiceType: "openvpn", Options: "../../etc"}.Key())
+	assert.Equal(t, "socks5", Spec{ServiceType: "socks5"}.Key())
+}
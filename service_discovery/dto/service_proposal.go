@@ -29,6 +29,12 @@ type ServiceProposal struct {
 
 	// Communication methods possible
 	ProviderContacts []Contact `json:"provider_contacts"`
+
+	// Base64 encoded signature of provider over the rest of proposal
+	Signature string `json:"signature,omitempty"`
+
+	// JSON of proposal as it was received, signature is verified over it instead of decoded fields
+	Raw json.RawMessage `json:"-"`
 }
 
 /**
@@ -126,6 +132,7 @@ func (genericProposal *ServiceProposal) UnmarshalJSON(data []byte) (err error) {
 		ServiceDefinition *json.RawMessage `json:"service_definition"`
 		PaymentMethod     *json.RawMessage `json:"payment_method"`
 		ProviderContacts  *json.RawMessage `json:"provider_contacts"`
+		Signature         string           `json:"signature"`
 	}
 	if err = json.Unmarshal(data, &jsonData); err != nil {
 		return
@@ -136,6 +143,8 @@ func (genericProposal *ServiceProposal) UnmarshalJSON(data []byte) (err error) {
 	genericProposal.ServiceType = jsonData.ServiceType
 	genericProposal.ProviderID = jsonData.ProviderID
 	genericProposal.PaymentMethodType = jsonData.PaymentMethodType
+	genericProposal.Signature = jsonData.Signature
+	genericProposal.Raw = append(json.RawMessage(nil), data...)
 
 	// run the service definition implementation from our registry
 	genericProposal.ServiceDefinition, err = unserializeServiceDefinition(
@@ -0,0 +1,73 @@
+package service_discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/service_discovery/dto"
+)
+
+// SignProposal signs canonical serialization of proposal with provider's identity
+func SignProposal(proposal dto.ServiceProposal, signer identity.Signer) (dto.ServiceProposal, error) {
+	proposal.Raw = nil
+	message, err := serializeProposal(proposal)
+	if err != nil {
+		return proposal, err
+	}
+
+	signature, err := signer.Sign(message)
+	if err != nil {
+		return proposal, err
+	}
+
+	proposal.Signature = signature.Base64()
+	return proposal, nil
+}
+
+// VerifyProposal checks that proposal is signed by its provider
+func VerifyProposal(proposal dto.ServiceProposal, extractor identity.Extractor) error {
+	if proposal.Signature == "" {
+		return errors.New("proposal is not signed")
+	}
+
+	message, err := serializeProposal(proposal)
+	if err != nil {
+		return err
+	}
+	signerID, err := extractor.Extract(message, identity.SignatureBase64(proposal.Signature))
+	if err != nil {
+		return fmt.Errorf("proposal signature is invalid: %s", err)
+	}
+	if signerID != identity.FromAddress(proposal.ProviderID) {
+		return fmt.Errorf("proposal is signed by %s instead of provider", signerID.Address)
+	}
+	return nil
+}
+
+// serializeProposal returns bytes of proposal which signature is made of. Received proposal is serialized from JSON
+// as it was received, so that fields which are unknown to consumer or lost by decoding are signed too
+func serializeProposal(proposal dto.ServiceProposal) ([]byte, error) {
+	data := []byte(proposal.Raw)
+	if data == nil {
+		var err error
+		if data, err = json.Marshal(proposal); err != nil {
+			return nil, err
+		}
+	}
+	return canonicalizeProposal(data)
+}
+
+// canonicalizeProposal removes signature from JSON of proposal and sorts its keys, so that serialization does not
+// depend on field order of service definitions and payment methods. Numbers are kept as they are written
+func canonicalizeProposal(data []byte) ([]byte, error) {
+	var canonical map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&canonical); err != nil {
+		return nil, err
+	}
+	delete(canonical, "signature")
+	return json.Marshal(canonical)
+}
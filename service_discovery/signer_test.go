@@ -0,0 +1,148 @@
+package service_discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	"github.com/mysterium/node/service_discovery/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var providerID = identity.FromAddress("0x0000000000000000000000000000000000000001")
+
+// extractorFake recovers signer of messages signed by identity.SignerFake
+type extractorFake struct {
+	signerID identity.Identity
+}
+
+func (extractor *extractorFake) Extract(message []byte, signature identity.Signature) (identity.Identity, error) {
+	if !bytes.Equal(append([]byte("signed"), message...), signature.Bytes()) {
+		return identity.Identity{}, errors.New("signature does not match message")
+	}
+	return extractor.signerID, nil
+}
+
+type testServiceDefinition struct {
+	Protocol string `json:"protocol"`
+}
+
+func (service testServiceDefinition) GetLocation() dto.Location {
+	return dto.Location{}
+}
+
+type testPaymentMethod struct {
+	Price money.Money `json:"price"`
+}
+
+func (method testPaymentMethod) GetPrice() money.Money {
+	return method.Price
+}
+
+func init() {
+	dto.RegisterServiceDefinitionUnserializer("test", func(data *json.RawMessage) (dto.ServiceDefinition, error) {
+		var definition testServiceDefinition
+		err := json.Unmarshal(*data, &definition)
+		return definition, err
+	})
+	dto.RegisterPaymentMethodUnserializer("TEST", func(data *json.RawMessage) (dto.PaymentMethod, error) {
+		var method testPaymentMethod
+		err := json.Unmarshal(*data, &method)
+		return method, err
+	})
+}
+
+func newProposal() dto.ServiceProposal {
+	return dto.ServiceProposal{
+		ID:                1,
+		Format:            "service-proposal/v1",
+		ServiceType:       "test",
+		ServiceDefinition: testServiceDefinition{Protocol: "udp"},
+		PaymentMethodType: "TEST",
+		PaymentMethod:     testPaymentMethod{Price: money.NewMoney(0.125, money.CURRENCY_MYST)},
+		ProviderID:        providerID.Address,
+		ProviderContacts:  []dto.Contact{},
+	}
+}
+
+func TestSignedProposalIsVerifiedAfterTransfer(t *testing.T) {
+	proposal, err := SignProposal(newProposal(), &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, proposal.Signature)
+
+	data, err := json.Marshal(proposal)
+	assert.NoError(t, err)
+	var received dto.ServiceProposal
+	assert.NoError(t, json.Unmarshal(data, &received))
+
+	assert.NoError(t, VerifyProposal(received, &extractorFake{providerID}))
+}
+
+func TestVerifyProposalCoversFieldsUnknownToConsumer(t *testing.T) {
+	proposal, err := SignProposal(newProposal(), &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	var fields map[string]interface{}
+	data, err := json.Marshal(proposal)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	fields["access_policy"] = "whitelist"
+	data, err = json.Marshal(fields)
+	assert.NoError(t, err)
+
+	var received dto.ServiceProposal
+	assert.NoError(t, json.Unmarshal(data, &received))
+
+	err = VerifyProposal(received, &extractorFake{providerID})
+	assert.EqualError(t, err, "proposal signature is invalid: signature does not match message")
+}
+
+func TestSignProposalFailsWhenSignerFails(t *testing.T) {
+	_, err := SignProposal(newProposal(), &identity.SignerFake{ErrorMock: errors.New("keystore is locked")})
+	assert.EqualError(t, err, "keystore is locked")
+}
+
+func TestVerifyProposalRejectsUnsignedProposal(t *testing.T) {
+	err := VerifyProposal(newProposal(), &extractorFake{providerID})
+	assert.EqualError(t, err, "proposal is not signed")
+}
+
+func TestVerifyProposalRejectsChangedProposal(t *testing.T) {
+	proposal, err := SignProposal(newProposal(), &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	proposal.PaymentMethod = testPaymentMethod{Price: money.NewMoney(0.001, money.CURRENCY_MYST)}
+	data, err := json.Marshal(proposal)
+	assert.NoError(t, err)
+	proposal = dto.ServiceProposal{}
+	assert.NoError(t, json.Unmarshal(data, &proposal))
+
+	err = VerifyProposal(proposal, &extractorFake{providerID})
+	assert.EqualError(t, err, "proposal signature is invalid: signature does not match message")
+}
+
+func TestVerifyProposalRejectsProposalSignedByOtherIdentity(t *testing.T) {
+	proposal, err := SignProposal(newProposal(), &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	otherID := identity.FromAddress("0x0000000000000000000000000000000000000002")
+	err = VerifyProposal(proposal, &extractorFake{otherID})
+	assert.EqualError(t, err, "proposal is signed by "+otherID.Address+" instead of provider")
+}
+
+func TestSerializeProposalSortsKeysAndSkipsSignature(t *testing.T) {
+	proposal := newProposal()
+	proposal.Signature = "c2lnbmF0dXJl"
+
+	message, err := serializeProposal(proposal)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		`{"format":"service-proposal/v1","id":1,"payment_method":{"price":{"amount":12500000,"currency":"MYST"}},`+
+			`"payment_method_type":"TEST","provider_contacts":[],"provider_id":"`+providerID.Address+`",`+
+			`"service_definition":{"protocol":"udp"},"service_type":"test"}`,
+		string(message),
+	)
+}
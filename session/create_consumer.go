@@ -2,14 +2,15 @@ package session
 
 import (
 	"fmt"
+	"github.com/mysterium/node/access"
 	"github.com/mysterium/node/communication"
 	"github.com/mysterium/node/identity"
 )
 
 type SessionCreateConsumer struct {
-	CurrentProposalID int
-	SessionManager    Manager
-	PeerID            identity.Identity
+	SessionManagers Managers
+	AccessPolicy    access.Policy
+	PeerID          identity.Identity
 }
 
 func (consumer *SessionCreateConsumer) GetRequestEndpoint() communication.RequestEndpoint {
@@ -23,19 +24,36 @@ func (consumer *SessionCreateConsumer) NewRequest() (requestPtr interface{}) {
 
 func (consumer *SessionCreateConsumer) Consume(requestPtr interface{}) (response interface{}, err error) {
 	request := requestPtr.(*SessionCreateRequest)
-	if consumer.CurrentProposalID != request.ProposalId {
+	if !consumer.AccessPolicy.Allows(consumer.PeerID) {
 		response = &SessionCreateResponse{
-			Success: false,
-			Message: fmt.Sprintf("Proposal doesn't exist: %d", request.ProposalId),
+			Success:   false,
+			ErrorCode: ErrorAccessDenied,
+			Message:   "Access denied.",
 		}
 		return
 	}
 
-	clientSession, err := consumer.SessionManager.Create(consumer.PeerID)
-	if err != nil {
+	sessionManager, found := consumer.SessionManagers.Find(request.ProposalId)
+	if !found {
 		response = &SessionCreateResponse{
-			Success: false,
-			Message: "Failed to create session.",
+			Success:   false,
+			ErrorCode: ErrorProposalNotFound,
+			Message:   fmt.Sprintf("Proposal doesn't exist: %d", request.ProposalId),
+		}
+		return
+	}
+
+	clientSession, createErr := sessionManager.Create(consumer.PeerID)
+	if createErr != nil {
+		code := errorCode(createErr)
+		message := "Failed to create session."
+		if code != ErrorInternal {
+			message = createErr.Error()
+		}
+		response = &SessionCreateResponse{
+			Success:   false,
+			ErrorCode: code,
+			Message:   message,
 		}
 		return
 	}
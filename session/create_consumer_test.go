@@ -1,13 +1,16 @@
 package session
 
 import (
+	"errors"
+	"github.com/mysterium/node/access"
+	"github.com/mysterium/node/identity"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
 var consumer = SessionCreateConsumer{
-	CurrentProposalID: 101,
-	SessionManager:    &ManagerFake{},
+	SessionManagers: Managers{101: &ManagerFake{}},
+	AccessPolicy:    access.NewPolicyFake(),
 }
 
 func TestConsumer_UnknownProposal(t *testing.T) {
@@ -19,8 +22,9 @@ func TestConsumer_UnknownProposal(t *testing.T) {
 	assert.Exactly(
 		t,
 		&SessionCreateResponse{
-			Success: false,
-			Message: "Proposal doesn't exist: 100",
+			Success:   false,
+			ErrorCode: ErrorProposalNotFound,
+			Message:   "Proposal doesn't exist: 100",
 		},
 		sessionResponse,
 	)
@@ -44,3 +48,97 @@ func TestConsumer_Success(t *testing.T) {
 		sessionResponse,
 	)
 }
+
+func TestConsumer_RoutesToServiceOfProposal(t *testing.T) {
+	consumer := SessionCreateConsumer{
+		SessionManagers: Managers{
+			101: &ManagerFake{CreateError: errors.New("wrong service")},
+			102: &ManagerFake{},
+		},
+		AccessPolicy: access.NewPolicyFake(),
+	}
+
+	request := consumer.NewRequest().(*SessionCreateRequest)
+	request.ProposalId = 102
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		&SessionCreateResponse{
+			Success: true,
+			Session: SessionDto{
+				ID:     "new-id",
+				Config: "new-config",
+			},
+		},
+		sessionResponse,
+	)
+}
+
+func TestConsumer_LimitExceeded(t *testing.T) {
+	consumer := SessionCreateConsumer{
+		SessionManagers: Managers{101: &ManagerFake{CreateError: ErrConsumerQuotaExceeded}},
+		AccessPolicy:    access.NewPolicyFake(),
+	}
+
+	request := consumer.NewRequest().(*SessionCreateRequest)
+	request.ProposalId = 101
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		&SessionCreateResponse{
+			Success:   false,
+			ErrorCode: ErrorConsumerQuotaExceeded,
+			Message:   "consumer session quota exceeded",
+		},
+		sessionResponse,
+	)
+}
+
+func TestConsumer_CreateFailed(t *testing.T) {
+	consumer := SessionCreateConsumer{
+		SessionManagers: Managers{101: &ManagerFake{CreateError: errors.New("config is broken")}},
+		AccessPolicy:    access.NewPolicyFake(),
+	}
+
+	request := consumer.NewRequest().(*SessionCreateRequest)
+	request.ProposalId = 101
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		&SessionCreateResponse{
+			Success:   false,
+			ErrorCode: ErrorInternal,
+			Message:   "Failed to create session.",
+		},
+		sessionResponse,
+	)
+}
+
+func TestConsumer_AccessDenied(t *testing.T) {
+	consumer := SessionCreateConsumer{
+		SessionManagers: Managers{101: &ManagerFake{}},
+		AccessPolicy:    access.NewPolicyFake(identity.FromAddress("0x1")),
+		PeerID:          identity.FromAddress("0x1"),
+	}
+
+	request := consumer.NewRequest().(*SessionCreateRequest)
+	request.ProposalId = 101
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		&SessionCreateResponse{
+			Success:   false,
+			ErrorCode: ErrorAccessDenied,
+			Message:   "Access denied.",
+		},
+		sessionResponse,
+	)
+}
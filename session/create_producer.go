@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"github.com/mysterium/node/communication"
 	"github.com/pkg/errors"
 )
@@ -24,14 +25,20 @@ func (producer *SessionCreateProducer) Produce() (requestPtr interface{}) {
 	}
 }
 
-func RequestSessionCreate(sender communication.Sender, proposalId int) (*SessionDto, error) {
-	responsePtr, err := sender.Request(&SessionCreateProducer{
+func RequestSessionCreate(ctx context.Context, sender communication.Sender, proposalId int) (*SessionDto, error) {
+	responsePtr, err := sender.Request(ctx, &SessionCreateProducer{
 		ProposalId: proposalId,
 	})
-	response := responsePtr.(*SessionCreateResponse)
+	if err != nil {
+		return nil, errors.Wrap(err, "SessionDto create failed")
+	}
 
-	if err != nil || !response.Success {
-		return nil, errors.New("SessionDto create failed. " + response.Message)
+	response := responsePtr.(*SessionCreateResponse)
+	if !response.Success {
+		if response.ErrorCode == "" {
+			return nil, errors.New("SessionDto create failed. " + response.Message)
+		}
+		return nil, &CreateError{Code: response.ErrorCode, Message: response.Message}
 	}
 
 	return &response.Session, nil
@@ -0,0 +1,48 @@
+package session
+
+import (
+	"fmt"
+	"github.com/mysterium/node/communication"
+	"github.com/mysterium/node/identity"
+)
+
+type SessionDestroyConsumer struct {
+	SessionManager Registry
+	PeerID         identity.Identity
+}
+
+func (consumer *SessionDestroyConsumer) GetRequestEndpoint() communication.RequestEndpoint {
+	return endpointSessionDestroy
+}
+
+func (consumer *SessionDestroyConsumer) NewRequest() (requestPtr interface{}) {
+	var request SessionDestroyRequest
+	return &request
+}
+
+// Consume destroys session, but only the one which belongs to the peer
+func (consumer *SessionDestroyConsumer) Consume(requestPtr interface{}) (response interface{}, err error) {
+	request := requestPtr.(*SessionDestroyRequest)
+
+	clientSession, found := consumer.SessionManager.Lookup(request.SessionID)
+	if !found || clientSession.ConsumerID != consumer.PeerID {
+		response = &SessionDestroyResponse{
+			Success: false,
+			Message: fmt.Sprintf("Session doesn't exist: %s", request.SessionID),
+		}
+		return
+	}
+
+	if destroyErr := consumer.SessionManager.Destroy(request.SessionID); destroyErr != nil {
+		response = &SessionDestroyResponse{
+			Success: false,
+			Message: "Failed to destroy session.",
+		}
+		return
+	}
+
+	response = &SessionDestroyResponse{
+		Success: true,
+	}
+	return
+}
@@ -0,0 +1,66 @@
+package session
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func newDestroyConsumer() *SessionDestroyConsumer {
+	return &SessionDestroyConsumer{
+		SessionManager: &ManagerFake{
+			Sessions: map[SessionID]Session{
+				"session-1": {ID: "session-1", ConsumerID: identity.FromAddress("consumer-1")},
+			},
+		},
+		PeerID: identity.FromAddress("consumer-1"),
+	}
+}
+
+func TestDestroyConsumer_UnknownSession(t *testing.T) {
+	consumer := newDestroyConsumer()
+	request := consumer.NewRequest().(*SessionDestroyRequest)
+	request.SessionID = "session-2"
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		&SessionDestroyResponse{
+			Success: false,
+			Message: "Session doesn't exist: session-2",
+		},
+		sessionResponse,
+	)
+	assert.Equal(t, SessionID(""), consumer.SessionManager.(*ManagerFake).DestroyedSession)
+}
+
+func TestDestroyConsumer_SessionOfOtherPeer(t *testing.T) {
+	consumer := newDestroyConsumer()
+	consumer.PeerID = identity.FromAddress("consumer-2")
+	request := consumer.NewRequest().(*SessionDestroyRequest)
+	request.SessionID = "session-1"
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(
+		t,
+		&SessionDestroyResponse{
+			Success: false,
+			Message: "Session doesn't exist: session-1",
+		},
+		sessionResponse,
+	)
+	assert.Equal(t, SessionID(""), consumer.SessionManager.(*ManagerFake).DestroyedSession)
+}
+
+func TestDestroyConsumer_Success(t *testing.T) {
+	consumer := newDestroyConsumer()
+	request := consumer.NewRequest().(*SessionDestroyRequest)
+	request.SessionID = "session-1"
+	sessionResponse, err := consumer.Consume(request)
+
+	assert.NoError(t, err)
+	assert.Exactly(t, &SessionDestroyResponse{Success: true}, sessionResponse)
+	assert.Equal(t, SessionID("session-1"), consumer.SessionManager.(*ManagerFake).DestroyedSession)
+}
@@ -0,0 +1,43 @@
+package session
+
+import (
+	"context"
+	"github.com/mysterium/node/communication"
+	"github.com/pkg/errors"
+)
+
+type SessionDestroyProducer struct {
+	SessionID SessionID
+}
+
+func (producer *SessionDestroyProducer) GetRequestEndpoint() communication.RequestEndpoint {
+	return endpointSessionDestroy
+}
+
+func (producer *SessionDestroyProducer) NewResponse() (responsePtr interface{}) {
+	var response SessionDestroyResponse
+	return &response
+}
+
+func (producer *SessionDestroyProducer) Produce() (requestPtr interface{}) {
+	return &SessionDestroyRequest{
+		SessionID: producer.SessionID,
+	}
+}
+
+// RequestSessionDestroy tells provider that session is not needed anymore
+func RequestSessionDestroy(ctx context.Context, sender communication.Sender, sessionID SessionID) error {
+	responsePtr, err := sender.Request(ctx, &SessionDestroyProducer{
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Session destroy failed")
+	}
+
+	response := responsePtr.(*SessionDestroyResponse)
+	if !response.Success {
+		return errors.New("Session destroy failed. " + response.Message)
+	}
+
+	return nil
+}
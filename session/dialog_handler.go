@@ -1,29 +1,41 @@
 package session
 
 import (
+	"github.com/mysterium/node/access"
 	"github.com/mysterium/node/communication"
 )
 
-// NewDialogHandler constructs handler which gets all incoming dialogs and starts handling them
-func NewDialogHandler(proposalId int, sessionManager Manager) *handler {
+// NewDialogHandler constructs handler which gets all incoming dialogs and starts handling them,
+// sessions are created by manager of service which serves requested proposal
+func NewDialogHandler(sessionManagers Managers, accessPolicy access.Policy) *handler {
 	return &handler{
-		CurrentProposalID: proposalId,
-		SessionManager:    sessionManager,
+		SessionManagers: sessionManagers,
+		AccessPolicy:    accessPolicy,
 	}
 }
 
 type handler struct {
-	CurrentProposalID int
-	SessionManager    Manager
+	SessionManagers Managers
+	AccessPolicy    access.Policy
 }
 
 // Handle starts serving services in given Dialog instance
 func (handler *handler) Handle(dialog communication.Dialog) error {
 	subscribeError := dialog.Respond(
 		&SessionCreateConsumer{
-			CurrentProposalID: handler.CurrentProposalID,
-			SessionManager:    handler.SessionManager,
-			PeerID:            dialog.PeerID(),
+			SessionManagers: handler.SessionManagers,
+			AccessPolicy:    handler.AccessPolicy,
+			PeerID:          dialog.PeerID(),
+		},
+	)
+	if subscribeError != nil {
+		return subscribeError
+	}
+
+	subscribeError = dialog.Respond(
+		&SessionDestroyConsumer{
+			SessionManager: handler.SessionManagers,
+			PeerID:         dialog.PeerID(),
 		},
 	)
 	if subscribeError != nil {
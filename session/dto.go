@@ -5,18 +5,47 @@ import (
 )
 
 const endpointSessionCreate = communication.RequestEndpoint("session-create")
+const endpointSessionDestroy = communication.RequestEndpoint("session-destroy")
 
 type SessionCreateRequest struct {
 	ProposalId int `json:"proposal_id"`
 }
 
+// ErrorCode tells consumer why session request was rejected
+type ErrorCode string
+
+const (
+	// ErrorProposalNotFound means that requested proposal is not served by provider
+	ErrorProposalNotFound = ErrorCode("proposal_not_found")
+	// ErrorTooManySessions means that provider serves maximum number of sessions already
+	ErrorTooManySessions = ErrorCode("too_many_sessions")
+	// ErrorConsumerQuotaExceeded means that consumer has maximum number of sessions already
+	ErrorConsumerQuotaExceeded = ErrorCode("consumer_quota_exceeded")
+	// ErrorRateLimited means that consumer creates sessions too often
+	ErrorRateLimited = ErrorCode("rate_limited")
+	// ErrorAccessDenied means that provider does not serve this consumer
+	ErrorAccessDenied = ErrorCode("access_denied")
+	// ErrorInternal means that provider failed to create session
+	ErrorInternal = ErrorCode("internal_error")
+)
+
 type SessionCreateResponse struct {
-	Success bool       `json:"success"`
-	Message string     `json:"message"`
-	Session SessionDto `json:"session"`
+	Success   bool       `json:"success"`
+	ErrorCode ErrorCode  `json:"error_code,omitempty"`
+	Message   string     `json:"message"`
+	Session   SessionDto `json:"session"`
 }
 
 type SessionDto struct {
 	ID     SessionID `json:"id"`
 	Config string    `json:"config"`
 }
+
+type SessionDestroyRequest struct {
+	SessionID SessionID `json:"session_id"`
+}
+
+type SessionDestroyResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
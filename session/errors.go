@@ -0,0 +1,50 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrSessionNotFound is returned by Manager when session does not exist or is expired already
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrTooManySessions is returned by Manager when node serves maximum number of sessions already
+	ErrTooManySessions = errors.New("too many sessions")
+	// ErrConsumerQuotaExceeded is returned by Manager when consumer has maximum number of sessions already
+	ErrConsumerQuotaExceeded = errors.New("consumer session quota exceeded")
+	// ErrRateLimited is returned by Manager when consumer creates sessions too often
+	ErrRateLimited = errors.New("session creation rate limit exceeded")
+)
+
+// CreateError is returned to consumer when provider rejects session creation
+type CreateError struct {
+	Code    ErrorCode
+	Message string
+}
+
+func (err *CreateError) Error() string {
+	return fmt.Sprintf("session create rejected (%s): %s", err.Code, err.Message)
+}
+
+// AppliesToProvider tells if session was rejected by provider's limits or access policy,
+// so other provider's endpoints will reject it too
+func (err *CreateError) AppliesToProvider() bool {
+	switch err.Code {
+	case ErrorTooManySessions, ErrorConsumerQuotaExceeded, ErrorRateLimited, ErrorAccessDenied:
+		return true
+	}
+	return false
+}
+
+// errorCode maps Manager errors to codes which are sent to consumer
+func errorCode(err error) ErrorCode {
+	switch err {
+	case ErrTooManySessions:
+		return ErrorTooManySessions
+	case ErrConsumerQuotaExceeded:
+		return ErrorConsumerQuotaExceeded
+	case ErrRateLimited:
+		return ErrorRateLimited
+	}
+	return ErrorInternal
+}
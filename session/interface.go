@@ -1,15 +1,21 @@
 package session
 
-import "github.com/mysterium/node/identity"
+import (
+	"github.com/mysterium/node/identity"
+	"time"
+)
 
 // SessionID represents session id type
 type SessionID string
 
 // Session structure holds all required information about current session between service consumer and provider
 type Session struct {
-	ID         SessionID
-	Config     string
-	ConsumerID identity.Identity
+	ID         SessionID         `json:"id"`
+	Config     string            `json:"config"`
+	ConsumerID identity.Identity `json:"consumer_id"`
+	Created    time.Time         `json:"created"`
+	// LastSeen is the last time session was used, zero if it was never used
+	LastSeen time.Time `json:"last_seen"`
 }
 
 // Generator defines method for session id generation
@@ -20,5 +26,39 @@ type Generator interface {
 // Manager defines methods for session management
 type Manager interface {
 	Create(identity.Identity) (Session, error)
+	// FindSession returns session which consumer uses and marks it as used
 	FindSession(SessionID) (Session, bool)
+	// Lookup returns session without marking it as used
+	Lookup(SessionID) (Session, bool)
+	List() []Session
+	Destroy(SessionID) error
+}
+
+// ExpiringManager is session manager which removes expired sessions in background, once started
+type ExpiringManager interface {
+	Manager
+	Start()
+	Stop()
+}
+
+// ConfigGenerator generates config of new session, which consumer connects to service with
+type ConfigGenerator func() (string, error)
+
+// ClientKiller disconnects consumer's clients which have connected to service with given session
+type ClientKiller func(sessionID SessionID) error
+
+// Registry gives access to existing sessions, regardless of which service they were created for
+type Registry interface {
+	FindSession(SessionID) (Session, bool)
+	Lookup(SessionID) (Session, bool)
+	List() []Session
+	Destroy(SessionID) error
+}
+
+// Storage keeps sessions of provider, implementations are safe for concurrent use
+type Storage interface {
+	Save(Session) error
+	Find(SessionID) (Session, bool, error)
+	List() ([]Session, error)
+	Delete(SessionID) error
 }
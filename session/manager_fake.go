@@ -1,16 +1,54 @@
 package session
 
-import "github.com/mysterium/node/identity"
+import (
+	"github.com/mysterium/node/identity"
+	"sort"
+)
 
 // ManagerFake represents fake manager usually useful in tests
-type ManagerFake struct{}
+type ManagerFake struct {
+	// Sessions are returned by FindSession, Lookup and List, they are not affected by Create
+	Sessions         map[SessionID]Session
+	DestroyedSession SessionID
+	// CreateError is returned by Create instead of session, when set
+	CreateError error
+}
 
 // Create function creates and returns fake session
 func (manager *ManagerFake) Create(peerID identity.Identity) (Session, error) {
-	return Session{"new-id", "new-config", peerID}, nil
+	if manager.CreateError != nil {
+		return Session{}, manager.CreateError
+	}
+	return Session{ID: "new-id", Config: "new-config", ConsumerID: peerID}, nil
 }
 
-// FindSession always returns empty session and signals that session is not found
+// FindSession returns one of preset sessions
 func (manager *ManagerFake) FindSession(id SessionID) (Session, bool) {
-	return Session{}, false
+	session, found := manager.Sessions[id]
+	return session, found
+}
+
+// Lookup returns one of preset sessions
+func (manager *ManagerFake) Lookup(id SessionID) (Session, bool) {
+	session, found := manager.Sessions[id]
+	return session, found
+}
+
+// List returns preset sessions ordered by id
+func (manager *ManagerFake) List() []Session {
+	sessions := make([]Session, 0, len(manager.Sessions))
+	for _, session := range manager.Sessions {
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// Destroy removes preset session and remembers which session was destroyed last
+func (manager *ManagerFake) Destroy(id SessionID) error {
+	delete(manager.Sessions, id)
+	manager.DestroyedSession = id
+	return nil
 }
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"sort"
+)
+
+// Managers are session managers of services which provider runs, keyed by ids of proposals they serve
+type Managers map[int]Manager
+
+// Find returns session manager of service which serves given proposal
+func (managers Managers) Find(proposalID int) (Manager, bool) {
+	manager, found := managers[proposalID]
+	return manager, found
+}
+
+// FindSession looks for session in all services
+func (managers Managers) FindSession(id SessionID) (Session, bool) {
+	for _, manager := range managers {
+		if session, found := manager.FindSession(id); found {
+			return session, true
+		}
+	}
+	return Session{}, false
+}
+
+// Lookup looks for session in all services, without marking it as used
+func (managers Managers) Lookup(id SessionID) (Session, bool) {
+	for _, manager := range managers {
+		if session, found := manager.Lookup(id); found {
+			return session, true
+		}
+	}
+	return Session{}, false
+}
+
+// List returns sessions of all services ordered by creation time
+func (managers Managers) List() []Session {
+	sessions := make([]Session, 0)
+	for _, manager := range managers {
+		sessions = append(sessions, manager.List()...)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if sessions[i].Created.Equal(sessions[j].Created) {
+			return sessions[i].ID < sessions[j].ID
+		}
+		return sessions[i].Created.Before(sessions[j].Created)
+	})
+	return sessions
+}
+
+// Destroy destroys session in the service it was created for
+func (managers Managers) Destroy(id SessionID) error {
+	for _, manager := range managers {
+		if _, found := manager.Lookup(id); found {
+			return manager.Destroy(id)
+		}
+	}
+	return ErrSessionNotFound
+}
@@ -0,0 +1,82 @@
+package session
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var (
+	createdTime = time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+	consumerID  = identity.FromAddress("consumer-1")
+)
+
+func newManagers() (Managers, *ManagerFake, *ManagerFake) {
+	udpManager := &ManagerFake{
+		Sessions: map[SessionID]Session{
+			"session-2": {ID: "session-2", ConsumerID: consumerID, Created: createdTime},
+		},
+	}
+	tcpManager := &ManagerFake{
+		Sessions: map[SessionID]Session{
+			"session-1": {ID: "session-1", ConsumerID: consumerID, Created: createdTime.Add(time.Minute)},
+			"session-3": {ID: "session-3", ConsumerID: consumerID, Created: createdTime},
+		},
+	}
+	return Managers{1: udpManager, 2: tcpManager}, udpManager, tcpManager
+}
+
+func TestManagers_Find(t *testing.T) {
+	managers, _, tcpManager := newManagers()
+
+	manager, found := managers.Find(2)
+	assert.True(t, found)
+	assert.Exactly(t, tcpManager, manager)
+
+	_, found = managers.Find(3)
+	assert.False(t, found)
+}
+
+func TestManagers_FindSession(t *testing.T) {
+	managers, _, _ := newManagers()
+
+	session, found := managers.FindSession("session-3")
+	assert.True(t, found)
+	assert.Equal(t, SessionID("session-3"), session.ID)
+
+	_, found = managers.FindSession("session-4")
+	assert.False(t, found)
+}
+
+func TestManagers_Lookup(t *testing.T) {
+	managers, _, _ := newManagers()
+
+	session, found := managers.Lookup("session-3")
+	assert.True(t, found)
+	assert.Equal(t, SessionID("session-3"), session.ID)
+
+	_, found = managers.Lookup("session-4")
+	assert.False(t, found)
+}
+
+func TestManagers_ListSessionsOfAllServices(t *testing.T) {
+	managers, _, _ := newManagers()
+
+	sessions := managers.List()
+
+	assert.Len(t, sessions, 3)
+	assert.Equal(t, SessionID("session-2"), sessions[0].ID)
+	assert.Equal(t, SessionID("session-3"), sessions[1].ID)
+	assert.Equal(t, SessionID("session-1"), sessions[2].ID)
+}
+
+func TestManagers_DestroyInServiceOfSession(t *testing.T) {
+	managers, udpManager, tcpManager := newManagers()
+
+	assert.NoError(t, managers.Destroy("session-3"))
+	assert.Equal(t, SessionID(""), udpManager.DestroyedSession)
+	assert.Equal(t, SessionID("session-3"), tcpManager.DestroyedSession)
+
+	assert.Equal(t, ErrSessionNotFound, managers.Destroy("session-3"))
+}
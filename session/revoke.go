@@ -0,0 +1,26 @@
+package session
+
+import (
+	"github.com/mysterium/node/identity"
+)
+
+// RevokeConsumer destroys all sessions of given consumer, so its clients get disconnected and can not re-authenticate
+func RevokeConsumer(manager Registry, consumerID identity.Identity) (destroyed []SessionID, err error) {
+	destroyed = make([]SessionID, 0)
+	for _, session := range manager.List() {
+		if session.ConsumerID != consumerID {
+			continue
+		}
+
+		err = manager.Destroy(session.ID)
+		if err == ErrSessionNotFound {
+			// session expired or was destroyed meanwhile
+			continue
+		}
+		if err != nil {
+			return
+		}
+		destroyed = append(destroyed, session.ID)
+	}
+	return destroyed, nil
+}
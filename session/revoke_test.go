@@ -0,0 +1,39 @@
+package session
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRevokeConsumer_DestroysAllSessionsOfConsumer(t *testing.T) {
+	manager := &ManagerFake{
+		Sessions: map[SessionID]Session{
+			"session-1": {ID: "session-1", ConsumerID: identity.FromAddress("consumer-1")},
+			"session-2": {ID: "session-2", ConsumerID: identity.FromAddress("consumer-2")},
+			"session-3": {ID: "session-3", ConsumerID: identity.FromAddress("consumer-1")},
+		},
+	}
+
+	destroyed, err := RevokeConsumer(manager, identity.FromAddress("consumer-1"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []SessionID{"session-1", "session-3"}, destroyed)
+	assert.Equal(
+		t,
+		map[SessionID]Session{
+			"session-2": {ID: "session-2", ConsumerID: identity.FromAddress("consumer-2")},
+		},
+		manager.Sessions,
+	)
+}
+
+func TestRevokeConsumer_WithoutSessions(t *testing.T) {
+	manager := &ManagerFake{}
+
+	destroyed, err := RevokeConsumer(manager, identity.FromAddress("consumer-1"))
+
+	assert.NoError(t, err)
+	assert.Empty(t, destroyed)
+	assert.Equal(t, SessionID(""), manager.DestroyedSession)
+}
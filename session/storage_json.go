@@ -0,0 +1,101 @@
+package session
+
+import (
+	"github.com/mysterium/node/utils/file"
+	"sync"
+)
+
+// NewStorageJSON creates storage which keeps sessions in given JSON file, so that they survive restarts
+func NewStorageJSON(path string) Storage {
+	return &storageJSON{
+		path: path,
+	}
+}
+
+type storageJSON struct {
+	path string
+
+	mutex    sync.Mutex
+	sessions map[SessionID]Session
+}
+
+func (storage *storageJSON) Save(session Session) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if err := storage.load(); err != nil {
+		return err
+	}
+
+	storage.sessions[session.ID] = session
+	return storage.save()
+}
+
+func (storage *storageJSON) Find(id SessionID) (Session, bool, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if err := storage.load(); err != nil {
+		return Session{}, false, err
+	}
+
+	session, found := storage.sessions[id]
+	return session, found, nil
+}
+
+func (storage *storageJSON) List() ([]Session, error) {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if err := storage.load(); err != nil {
+		return nil, err
+	}
+
+	sessions := make([]Session, 0, len(storage.sessions))
+	for _, session := range storage.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (storage *storageJSON) Delete(id SessionID) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	if err := storage.load(); err != nil {
+		return err
+	}
+	if _, found := storage.sessions[id]; !found {
+		return nil
+	}
+
+	delete(storage.sessions, id)
+	return storage.save()
+}
+
+// load reads sessions from the file once, missing file means there are no sessions
+func (storage *storageJSON) load() error {
+	if storage.sessions != nil {
+		return nil
+	}
+
+	sessions := make([]Session, 0)
+	if err := file.ReadJSON(storage.path, &sessions); err != nil {
+		return err
+	}
+
+	storage.sessions = make(map[SessionID]Session, len(sessions))
+	for _, session := range sessions {
+		storage.sessions[session.ID] = session
+	}
+	return nil
+}
+
+// save replaces the file with all sessions
+func (storage *storageJSON) save() error {
+	sessions := make([]Session, 0, len(storage.sessions))
+	for _, session := range storage.sessions {
+		sessions = append(sessions, session)
+	}
+	return file.WriteJSON(storage.path, sessions)
+}
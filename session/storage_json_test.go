@@ -0,0 +1,68 @@
+package session
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createSessionsFile(t *testing.T) (string, func()) {
+	directory, err := ioutil.TempDir("", "sessions-test-")
+	assert.NoError(t, err)
+
+	return filepath.Join(directory, "run", "sessions.json"), func() { os.RemoveAll(directory) }
+}
+
+func TestStorageJSON_SessionsArePersisted(t *testing.T) {
+	path, cleanup := createSessionsFile(t)
+	defer cleanup()
+
+	session := Session{
+		ID:         "session-1",
+		Config:     "remote 1.2.3.4",
+		ConsumerID: identity.FromAddress("0x1"),
+		Created:    time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	storage := NewStorageJSON(path)
+	assert.NoError(t, storage.Save(session))
+	assert.NoError(t, storage.Save(Session{ID: "session-2"}))
+	assert.NoError(t, storage.Delete("session-2"))
+
+	restored, found, err := NewStorageJSON(path).Find("session-1")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, session, restored)
+
+	sessions, err := NewStorageJSON(path).List()
+	assert.NoError(t, err)
+	assert.Equal(t, []Session{session}, sessions)
+}
+
+func TestStorageJSON_IsEmptyWithoutFile(t *testing.T) {
+	path, cleanup := createSessionsFile(t)
+	defer cleanup()
+
+	storage := NewStorageJSON(path)
+	_, found, err := storage.Find("session-1")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	sessions, err := storage.List()
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.NoError(t, storage.Delete("session-1"))
+}
+
+func TestStorageJSON_FailsWithMalformedFile(t *testing.T) {
+	path, cleanup := createSessionsFile(t)
+	defer cleanup()
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	assert.NoError(t, ioutil.WriteFile(path, []byte("[{"), 0600))
+
+	_, _, err := NewStorageJSON(path).Find("session-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package session
+
+import (
+	"sync"
+)
+
+// NewStorageMemory creates storage which keeps sessions until process exits
+func NewStorageMemory() Storage {
+	return &storageMemory{
+		sessions: make(map[SessionID]Session),
+	}
+}
+
+type storageMemory struct {
+	mutex    sync.RWMutex
+	sessions map[SessionID]Session
+}
+
+func (storage *storageMemory) Save(session Session) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	storage.sessions[session.ID] = session
+	return nil
+}
+
+func (storage *storageMemory) Find(id SessionID) (Session, bool, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	session, found := storage.sessions[id]
+	return session, found, nil
+}
+
+func (storage *storageMemory) List() ([]Session, error) {
+	storage.mutex.RLock()
+	defer storage.mutex.RUnlock()
+
+	sessions := make([]Session, 0, len(storage.sessions))
+	for _, session := range storage.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (storage *storageMemory) Delete(id SessionID) error {
+	storage.mutex.Lock()
+	defer storage.mutex.Unlock()
+
+	delete(storage.sessions, id)
+	return nil
+}
@@ -0,0 +1,50 @@
+package socks5
+
+import (
+	"encoding/hex"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+)
+
+// challengeSize is number of random bytes which server challenges consumer to sign on each connection
+const challengeSize = 32
+
+// challengeSignaturePrefix keeps signatures of challenges apart from other messages which consumer signs
+const challengeSignaturePrefix = "MystSocks5Challenge:"
+
+// ChallengeSigner signs challenge which server sent on connection, returning session id and the signature
+type ChallengeSigner func(challenge []byte) (sessionID string, signature string, err error)
+
+// ChallengeChecker tells if challenge of connection is signed by consumer of the session
+type ChallengeChecker func(sessionID string, challenge []byte, signature string) (bool, error)
+
+type sessionFinder func(sessionID session.SessionID) (session.Session, bool)
+
+// NewChallengeSigner creates signer which signs challenges of server with consumer's identity. Signature is valid
+// only for the connection which challenge was sent on, so that it can not be replayed by whoever sees the traffic
+func NewChallengeSigner(sessionID session.SessionID, signer identity.Signer) ChallengeSigner {
+	return func(challenge []byte) (string, string, error) {
+		signature, err := signer.Sign(challengeMessage(string(sessionID), challenge))
+		return string(sessionID), signature.Base64(), err
+	}
+}
+
+// NewChallengeChecker creates checker which accepts challenges signed by consumers of existing sessions
+func NewChallengeChecker(findSession sessionFinder, extractor identity.Extractor) ChallengeChecker {
+	return func(sessionID string, challenge []byte, signature string) (bool, error) {
+		currentSession, found := findSession(session.SessionID(sessionID))
+		if !found {
+			return false, nil
+		}
+
+		signerID, err := extractor.Extract(challengeMessage(sessionID, challenge), identity.SignatureBase64(signature))
+		if err != nil {
+			return false, err
+		}
+		return currentSession.ConsumerID == signerID, nil
+	}
+}
+
+func challengeMessage(sessionID string, challenge []byte) []byte {
+	return []byte(challengeSignaturePrefix + sessionID + ":" + hex.EncodeToString(challenge))
+}
@@ -0,0 +1,23 @@
+package socks5
+
+import (
+	"encoding/json"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	dto_socks5 "github.com/mysterium/node/socks5/discovery/dto"
+)
+
+// ServiceType is the type of socks5 proposals
+const ServiceType = "socks5"
+
+// Bootstrap registers unserializer of socks5 service definition, payment methods are shared with openvpn
+func Bootstrap() {
+	dto_discovery.RegisterServiceDefinitionUnserializer(
+		ServiceType,
+		func(rawDefinition *json.RawMessage) (dto_discovery.ServiceDefinition, error) {
+			var definition dto_socks5.ServiceDefinition
+			err := json.Unmarshal(*rawDefinition, &definition)
+
+			return definition, err
+		},
+	)
+}
@@ -0,0 +1,323 @@
+package socks5
+
+import (
+	"errors"
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/firewall"
+	"github.com/mysterium/node/service"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const clientLogPrefix = "[socks5-client] "
+
+// probeInterval is how often client checks that provider still accepts the session, while applications are idle
+const probeInterval = 30 * time.Second
+
+// maxAuthFailures is number of consecutive rejections, after which session is considered to be killed by provider
+const maxAuthFailures = 3
+
+var errSessionRejected = errors.New("session is rejected by provider")
+
+// Client is local SOCKS5 proxy without authentication, which passes connections of applications
+// to provider's server authenticating them with consumer's session
+type Client struct {
+	localAddress  string
+	remoteAddress string
+	signChallenge ChallengeSigner
+	stateCallback service.ConnectionStateCallback
+	statsHandler  service.ConnectionStatsHandler
+	statsInterval time.Duration
+	probeInterval time.Duration
+
+	bytesSent     int64
+	bytesReceived int64
+
+	mutex        sync.Mutex
+	listener     net.Listener
+	connections  map[net.Conn]bool
+	authFailures int
+	err          error
+	stopped      chan struct{}
+	serving      sync.WaitGroup
+}
+
+// NewClient creates client listening on local address, which connects to provider's server on remote address
+func NewClient(
+	localAddress, remoteAddress string,
+	signChallenge ChallengeSigner,
+	stateCallback service.ConnectionStateCallback,
+	statsHandler service.ConnectionStatsHandler,
+	statsInterval time.Duration,
+) *Client {
+	return &Client{
+		localAddress:  localAddress,
+		remoteAddress: remoteAddress,
+		signChallenge: signChallenge,
+		stateCallback: stateCallback,
+		statsHandler:  statsHandler,
+		statsInterval: statsInterval,
+		probeInterval: probeInterval,
+		connections:   make(map[net.Conn]bool),
+	}
+}
+
+// Start checks that provider accepts session and starts listening for local applications
+func (client *Client) Start() error {
+	client.stateCallback(service.ConnectionConnecting)
+
+	probe, err := client.dialRemote()
+	if err != nil {
+		client.stateCallback(service.ConnectionExiting)
+		return err
+	}
+	probe.Close()
+
+	listener, err := net.Listen("tcp", client.localAddress)
+	if err != nil {
+		client.stateCallback(service.ConnectionExiting)
+		return err
+	}
+
+	client.mutex.Lock()
+	client.listener = listener
+	client.stopped = make(chan struct{})
+	client.mutex.Unlock()
+
+	client.serving.Add(3)
+	go client.serve(listener)
+	go client.reportStats()
+	go client.probeRemote()
+
+	log.Info(clientLogPrefix, "Listening for applications on ", listener.Addr())
+	client.stateCallback(service.ConnectionConnected)
+	return nil
+}
+
+// LocalAddress returns address which applications connect to
+func (client *Client) LocalAddress() net.Addr {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	return client.listener.Addr()
+}
+
+// KillSwitchRules allows traffic to provider's server only, applications reach everything else through it
+func (client *Client) KillSwitchRules() []firewall.RuleAllowed {
+	host, _, err := net.SplitHostPort(client.remoteAddress)
+	if err != nil {
+		host = client.remoteAddress
+	}
+
+	return []firewall.RuleAllowed{{RemoteAddress: host}}
+}
+
+// Wait blocks until client is stopped, it fails when provider stopped accepting the session
+func (client *Client) Wait() error {
+	client.mutex.Lock()
+	stopped := client.stopped
+	client.mutex.Unlock()
+
+	if stopped != nil {
+		<-stopped
+	}
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	return client.err
+}
+
+// Stop stops listening and disconnects all applications
+func (client *Client) Stop() error {
+	client.mutex.Lock()
+	if client.listener == nil {
+		client.mutex.Unlock()
+		return nil
+	}
+	client.listener.Close()
+	client.listener = nil
+	for connection := range client.connections {
+		connection.Close()
+	}
+	stopped := client.stopped
+	client.mutex.Unlock()
+
+	close(stopped)
+	client.serving.Wait()
+	client.stateCallback(service.ConnectionExiting)
+	return nil
+}
+
+// dialRemote connects to provider's server and authenticates with session
+func (client *Client) dialRemote() (net.Conn, error) {
+	remote, err := net.DialTimeout("tcp", client.remoteAddress, dialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := authenticate(remote, client.signChallenge); err != nil {
+		remote.Close()
+		return nil, err
+	}
+	return remote, nil
+}
+
+// dialRemoteChecked dials provider's server as dialRemote does and stops client,
+// once provider rejects the session repeatedly e.g. because it has killed the session
+func (client *Client) dialRemoteChecked() (net.Conn, error) {
+	remote, err := client.dialRemote()
+
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	switch err {
+	case nil:
+		client.authFailures = 0
+	case errAuthenticationFailed:
+		client.authFailures++
+		if client.authFailures == maxAuthFailures {
+			log.Warn(clientLogPrefix, "Stopping, provider rejected session ", client.authFailures, " times")
+			client.err = errSessionRejected
+			// stopping waits for connections, which may include the current one
+			go client.Stop()
+		}
+	}
+	return remote, err
+}
+
+func (client *Client) serve(listener net.Listener) {
+	defer client.serving.Done()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		client.serving.Add(1)
+		go func() {
+			defer client.serving.Done()
+			defer connection.Close()
+
+			if err := client.handle(connection); err != nil {
+				log.Debug(clientLogPrefix, "Connection of ", connection.RemoteAddr(), " finished: ", err)
+			}
+		}()
+	}
+}
+
+func (client *Client) handle(connection net.Conn) error {
+	if !client.track(connection) {
+		return errors.New("client is stopped")
+	}
+	defer client.untrack(connection)
+
+	methods, err := readMethods(connection)
+	if err != nil {
+		return err
+	}
+	if !hasMethod(methods, methodNoAuth) {
+		writeMethod(connection, methodNoAcceptable)
+		return errors.New("application requires authentication")
+	}
+	if err := writeMethod(connection, methodNoAuth); err != nil {
+		return err
+	}
+
+	req, err := readRequest(connection)
+	if err != nil {
+		return err
+	}
+
+	remote, err := client.dialRemoteChecked()
+	if err != nil {
+		writeReply(connection, replyGeneralFailure, nil)
+		return err
+	}
+	defer remote.Close()
+
+	if _, err := remote.Write(req.raw); err != nil {
+		writeReply(connection, replyGeneralFailure, nil)
+		return err
+	}
+	status, reply, err := readReply(remote)
+	if err != nil {
+		writeReply(connection, replyGeneralFailure, nil)
+		return err
+	}
+	if _, err := connection.Write(reply); err != nil || status != replySucceeded {
+		return err
+	}
+
+	relay(connection, remote, &client.bytesSent, &client.bytesReceived)
+	return nil
+}
+
+func (client *Client) track(connection net.Conn) bool {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	if client.listener == nil {
+		return false
+	}
+	client.connections[connection] = true
+	return true
+}
+
+func (client *Client) untrack(connection net.Conn) {
+	client.mutex.Lock()
+	defer client.mutex.Unlock()
+
+	delete(client.connections, connection)
+}
+
+// reportStats reports traffic of applications periodically, until client is stopped
+func (client *Client) reportStats() {
+	defer client.serving.Done()
+
+	client.mutex.Lock()
+	stopped := client.stopped
+	client.mutex.Unlock()
+
+	for {
+		select {
+		case <-stopped:
+			return
+		case <-time.After(client.statsInterval):
+		}
+
+		err := client.statsHandler(service.ConnectionStats{
+			BytesSent:     int(atomic.LoadInt64(&client.bytesSent)),
+			BytesReceived: int(atomic.LoadInt64(&client.bytesReceived)),
+		})
+		if err != nil {
+			log.Warn(clientLogPrefix, "Failed to handle statistics: ", err)
+		}
+	}
+}
+
+// probeRemote checks periodically that provider still accepts the session, until client is stopped
+func (client *Client) probeRemote() {
+	defer client.serving.Done()
+
+	client.mutex.Lock()
+	stopped := client.stopped
+	client.mutex.Unlock()
+
+	for {
+		select {
+		case <-stopped:
+			return
+		case <-time.After(client.probeInterval):
+		}
+
+		probe, err := client.dialRemoteChecked()
+		if err != nil {
+			log.Warn(clientLogPrefix, "Failed to probe provider: ", err)
+			continue
+		}
+		probe.Close()
+	}
+}
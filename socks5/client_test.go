@@ -0,0 +1,156 @@
+package socks5
+
+import (
+	"github.com/mysterium/node/firewall"
+	"github.com/mysterium/node/service"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type clientRecorder struct {
+	mutex  sync.Mutex
+	states []service.ConnectionState
+	stats  service.ConnectionStats
+}
+
+func (recorder *clientRecorder) stateCallback(state service.ConnectionState) error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.states = append(recorder.states, state)
+	return nil
+}
+
+func (recorder *clientRecorder) statsHandler(stats service.ConnectionStats) error {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	recorder.stats = stats
+	return nil
+}
+
+func (recorder *clientRecorder) lastStats() service.ConnectionStats {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.stats
+}
+
+func (recorder *clientRecorder) lastStates() []service.ConnectionState {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+
+	return recorder.states
+}
+
+func newClient(server *Server, sign ChallengeSigner, recorder *clientRecorder) *Client {
+	return NewClient(
+		"127.0.0.1:0",
+		server.Address().String(),
+		sign,
+		recorder.stateCallback,
+		recorder.statsHandler,
+		10*time.Millisecond,
+	)
+}
+
+func TestClientProxiesApplicationThroughServer(t *testing.T) {
+	target := startEchoServer(t)
+	defer target.Close()
+	server := startServer(t)
+	defer server.Stop()
+
+	recorder := &clientRecorder{}
+	client := newClient(server, signChallenge, recorder)
+	assert.NoError(t, client.Start())
+	defer client.Stop()
+
+	connection, status := connectThrough(t, client.LocalAddress(), target.Addr(), nil)
+	defer connection.Close()
+
+	assert.Equal(t, byte(replySucceeded), status)
+	assertEchoed(t, connection, "ping")
+	waitForSessionStats(t, server, []SessionStats{{SessionID: "session-1", BytesSent: 4, BytesReceived: 4}})
+
+	expectedStats := service.ConnectionStats{BytesSent: 4, BytesReceived: 4}
+	for i := 0; i < 100 && recorder.lastStats() != expectedStats; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, expectedStats, recorder.lastStats())
+}
+
+func TestClientReportsStates(t *testing.T) {
+	server := startServer(t)
+	defer server.Stop()
+
+	recorder := &clientRecorder{}
+	client := newClient(server, signChallenge, recorder)
+	assert.NoError(t, client.Start())
+	assert.NoError(t, client.Stop())
+	assert.NoError(t, client.Wait())
+
+	assert.Equal(
+		t,
+		[]service.ConnectionState{service.ConnectionConnecting, service.ConnectionConnected, service.ConnectionExiting},
+		recorder.states,
+	)
+}
+
+func TestClientAllowsServerInKillSwitch(t *testing.T) {
+	client := NewClient("127.0.0.1:0", "1.2.3.4:1080", signChallenge, nil, nil, time.Second)
+
+	assert.Equal(t, []firewall.RuleAllowed{{RemoteAddress: "1.2.3.4"}}, client.KillSwitchRules())
+}
+
+func TestClientFailsToStartWhenSessionIsRejected(t *testing.T) {
+	server := startServer(t)
+	defer server.Stop()
+
+	recorder := &clientRecorder{}
+	client := newClient(server, signChallengeInvalid, recorder)
+
+	assert.Equal(t, errAuthenticationFailed, client.Start())
+	assert.Equal(t, []service.ConnectionState{service.ConnectionConnecting, service.ConnectionExiting}, recorder.states)
+}
+
+func TestClientStopsWhenProviderKillsSession(t *testing.T) {
+	accepted := true
+	var mutex sync.Mutex
+	server := NewServer("127.0.0.1:0", func(sessionID string, challenge []byte, signature string) (bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return accepted, nil
+	})
+	assert.NoError(t, server.Start())
+	defer server.Stop()
+
+	recorder := &clientRecorder{}
+	client := newClient(server, signChallenge, recorder)
+	client.probeInterval = 10 * time.Millisecond
+	assert.NoError(t, client.Start())
+	defer client.Stop()
+
+	mutex.Lock()
+	accepted = false
+	mutex.Unlock()
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- client.Wait()
+	}()
+	select {
+	case err := <-stopped:
+		assert.Equal(t, errSessionRejected, err)
+	case <-time.After(time.Second):
+		t.Fatal("client did not stop")
+	}
+
+	expectedStates := []service.ConnectionState{service.ConnectionConnecting, service.ConnectionConnected, service.ConnectionExiting}
+	for i := 0; i < 100 && len(recorder.lastStates()) < len(expectedStates); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, expectedStates, recorder.lastStates())
+}
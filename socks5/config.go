@@ -0,0 +1,46 @@
+package socks5
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// ClientConfig is config of session, which tells consumer where provider's server listens
+type ClientConfig struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// NewClientConfig generates serialized config of session with given server
+func NewClientConfig(host string, port int) (string, error) {
+	configJSON, err := json.Marshal(ClientConfig{Host: host, Port: port})
+	if err != nil {
+		return "", err
+	}
+
+	return string(configJSON), nil
+}
+
+// ParseClientConfig unserializes config of session and checks that it defines server
+func ParseClientConfig(configString string) (ClientConfig, error) {
+	var config ClientConfig
+	if err := json.Unmarshal([]byte(configString), &config); err != nil {
+		return ClientConfig{}, fmt.Errorf("invalid client configuration: %s", err)
+	}
+	if config.Host == "" {
+		return ClientConfig{}, errors.New("host is not defined in client configuration")
+	}
+	if config.Port <= 0 || config.Port > 65535 {
+		return ClientConfig{}, fmt.Errorf("invalid port in client configuration: %d", config.Port)
+	}
+
+	return config, nil
+}
+
+// Address returns address of server which client connects to
+func (config ClientConfig) Address() string {
+	return net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+}
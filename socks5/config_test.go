@@ -0,0 +1,28 @@
+package socks5
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClientConfigIsSerialized(t *testing.T) {
+	configString, err := NewClientConfig("1.2.3.4", 1080)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"host":"1.2.3.4","port":1080}`, configString)
+
+	config, err := ParseClientConfig(configString)
+	assert.NoError(t, err)
+	assert.Equal(t, ClientConfig{Host: "1.2.3.4", Port: 1080}, config)
+	assert.Equal(t, "1.2.3.4:1080", config.Address())
+}
+
+func TestParseClientConfigWithInvalidServer(t *testing.T) {
+	_, err := ParseClientConfig("remote 1.2.3.4 1080\n")
+	assert.EqualError(t, err, "invalid client configuration: invalid character 'r' looking for beginning of value")
+
+	_, err = ParseClientConfig(`{"port":1080}`)
+	assert.EqualError(t, err, "host is not defined in client configuration")
+
+	_, err = ParseClientConfig(`{"host":"1.2.3.4","port":70000}`)
+	assert.EqualError(t, err, "invalid port in client configuration: 70000")
+}
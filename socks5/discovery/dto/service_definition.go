@@ -0,0 +1,17 @@
+package dto
+
+import (
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+)
+
+type ServiceDefinition struct {
+	// Approximate information on location where the service is provided from
+	Location dto_discovery.Location `json:"location"`
+
+	// Approximate information on location where the proxied traffic will originate from
+	LocationOriginate dto_discovery.Location `json:"location_originate"`
+}
+
+func (service ServiceDefinition) GetLocation() dto_discovery.Location {
+	return service.Location
+}
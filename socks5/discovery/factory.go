@@ -0,0 +1,38 @@
+package discovery
+
+import (
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	dto_openvpn "github.com/mysterium/node/openvpn/discovery/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	dto_socks5 "github.com/mysterium/node/socks5/discovery/dto"
+	"time"
+)
+
+// DefaultPricePerHour is the price of proxy, which is cheaper than price of openvpn
+var DefaultPricePerHour = money.NewMoney(0.05, money.CURRENCY_MYST)
+
+func NewServiceProposalWithLocation(
+	proposalID int,
+	providerID identity.Identity,
+	providerContact dto_discovery.Contact,
+	serviceLocation dto_discovery.Location,
+	pricePerHour money.Money,
+) dto_discovery.ServiceProposal {
+	return dto_discovery.ServiceProposal{
+		ID:          proposalID,
+		Format:      "service-proposal/v1",
+		ServiceType: "socks5",
+		ServiceDefinition: dto_socks5.ServiceDefinition{
+			Location:          serviceLocation,
+			LocationOriginate: serviceLocation,
+		},
+		PaymentMethodType: dto_openvpn.PAYMENT_METHOD_PER_TIME,
+		PaymentMethod: dto_openvpn.PaymentMethodPerTime{
+			Price:    pricePerHour,
+			Duration: 1 * time.Hour,
+		},
+		ProviderID:       providerID.Address,
+		ProviderContacts: []dto_discovery.Contact{providerContact},
+	}
+}
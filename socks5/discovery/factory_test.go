@@ -0,0 +1,43 @@
+package discovery
+
+import (
+	"encoding/json"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/openvpn"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/socks5"
+	dto_socks5 "github.com/mysterium/node/socks5/discovery/dto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func init() {
+	openvpn.Bootstrap()
+	socks5.Bootstrap()
+}
+
+var locationLT = dto_discovery.Location{Country: "LT"}
+
+func TestServiceProposalIsUnserializedWithSocks5Definition(t *testing.T) {
+	proposal := NewServiceProposalWithLocation(
+		3,
+		identity.FromAddress("123456"),
+		dto_discovery.Contact{Type: "type1"},
+		locationLT,
+		DefaultPricePerHour,
+	)
+
+	jsonData, err := json.Marshal(proposal)
+	assert.NoError(t, err)
+
+	var actual dto_discovery.ServiceProposal
+	assert.NoError(t, json.Unmarshal(jsonData, &actual))
+	assert.Equal(t, 3, actual.ID)
+	assert.Equal(t, "socks5", actual.ServiceType)
+	assert.Equal(
+		t,
+		dto_socks5.ServiceDefinition{Location: locationLT, LocationOriginate: locationLT},
+		actual.ServiceDefinition,
+	)
+	assert.Equal(t, DefaultPricePerHour, actual.PaymentMethod.GetPrice())
+}
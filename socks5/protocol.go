@@ -0,0 +1,273 @@
+package socks5
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// SOCKS protocol version 5 (RFC 1928). Consumers authenticate with private challenge method, which frames
+// challenge and its signature like username/password authentication (RFC 1929) does
+const (
+	socksVersion = 0x05
+	authVersion  = 0x01
+
+	methodNoAuth = 0x00
+	// methodSessionChallenge is private method, server sends random challenge and consumer replies with session id
+	// and signature of the challenge
+	methodSessionChallenge = 0x80
+	methodNoAcceptable     = 0xff
+
+	authSuccess = 0x00
+	authFailure = 0x01
+
+	commandConnect = 0x01
+
+	addressIPv4   = 0x01
+	addressDomain = 0x03
+	addressIPv6   = 0x04
+
+	replySucceeded            = 0x00
+	replyGeneralFailure       = 0x01
+	replyConnectionNotAllowed = 0x02
+	replyHostUnreachable      = 0x04
+	replyCommandNotSupported  = 0x07
+	replyAddressNotSupported  = 0x08
+)
+
+var errAuthenticationFailed = errors.New("socks5 authentication failed")
+
+// request is the request of client, which connection to target it wants
+type request struct {
+	command byte
+	// raw is the request as it was received, so that it can be passed on unchanged
+	raw     []byte
+	address string
+}
+
+// readMethods reads greeting of client and returns authentication methods it supports
+func readMethods(reader io.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if header[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+
+	methods := make([]byte, header[1])
+	_, err := io.ReadFull(reader, methods)
+	return methods, err
+}
+
+func writeMethod(writer io.Writer, method byte) error {
+	_, err := writer.Write([]byte{socksVersion, method})
+	return err
+}
+
+func hasMethod(methods []byte, method byte) bool {
+	for _, supported := range methods {
+		if supported == method {
+			return true
+		}
+	}
+	return false
+}
+
+// writeChallenge sends challenge which client has to sign
+func writeChallenge(writer io.Writer, challenge []byte) error {
+	_, err := writer.Write(append([]byte{authVersion, byte(len(challenge))}, challenge...))
+	return err
+}
+
+// readChallenge reads challenge of server
+func readChallenge(reader io.Reader) ([]byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if header[0] != authVersion {
+		return nil, fmt.Errorf("unsupported authentication version: %d", header[0])
+	}
+
+	challenge := make([]byte, header[1])
+	_, err := io.ReadFull(reader, challenge)
+	return challenge, err
+}
+
+// readCredentials reads username and password of client
+func readCredentials(reader io.Reader) (username, password string, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return
+	}
+	if header[0] != authVersion {
+		return "", "", fmt.Errorf("unsupported authentication version: %d", header[0])
+	}
+
+	if username, err = readString(reader, int(header[1])); err != nil {
+		return
+	}
+
+	length := make([]byte, 1)
+	if _, err = io.ReadFull(reader, length); err != nil {
+		return
+	}
+	password, err = readString(reader, int(length[0]))
+	return
+}
+
+func writeAuthStatus(writer io.Writer, status byte) error {
+	_, err := writer.Write([]byte{authVersion, status})
+	return err
+}
+
+// authenticate authenticates to server with session id and signature of the challenge server sends
+func authenticate(connection io.ReadWriter, sign ChallengeSigner) error {
+	if _, err := connection.Write([]byte{socksVersion, 1, methodSessionChallenge}); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(connection, reply); err != nil {
+		return err
+	}
+	if reply[0] != socksVersion || reply[1] != methodSessionChallenge {
+		return errAuthenticationFailed
+	}
+
+	challenge, err := readChallenge(connection)
+	if err != nil {
+		return err
+	}
+	username, password, err := sign(challenge)
+	if err != nil {
+		return err
+	}
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("socks5 credentials are too long")
+	}
+
+	credentials := []byte{authVersion, byte(len(username))}
+	credentials = append(credentials, username...)
+	credentials = append(credentials, byte(len(password)))
+	credentials = append(credentials, password...)
+	if _, err := connection.Write(credentials); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(connection, reply); err != nil {
+		return err
+	}
+	if reply[1] != authSuccess {
+		return errAuthenticationFailed
+	}
+	return nil
+}
+
+// readRequest reads request of client, address of unsupported type is left empty
+func readRequest(reader io.Reader) (*request, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, err
+	}
+	if header[0] != socksVersion {
+		return nil, fmt.Errorf("unsupported socks version: %d", header[0])
+	}
+
+	req := &request{command: header[1], raw: header}
+	var host []byte
+	switch header[3] {
+	case addressIPv4:
+		host = make([]byte, net.IPv4len)
+	case addressIPv6:
+		host = make([]byte, net.IPv6len)
+	case addressDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(reader, length); err != nil {
+			return nil, err
+		}
+		req.raw = append(req.raw, length...)
+		host = make([]byte, length[0])
+	default:
+		return req, nil
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, host); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return nil, err
+	}
+	req.raw = append(req.raw, host...)
+	req.raw = append(req.raw, port...)
+
+	hostname := string(host)
+	if header[3] != addressDomain {
+		hostname = net.IP(host).String()
+	}
+	req.address = net.JoinHostPort(hostname, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+	return req, nil
+}
+
+// writeReply replies to request of client with given status and address which server has bound to
+func writeReply(writer io.Writer, status byte, boundAddress net.Addr) error {
+	reply := []byte{socksVersion, status, 0x00}
+
+	ip := net.IPv4zero
+	port := 0
+	if tcpAddress, ok := boundAddress.(*net.TCPAddr); ok {
+		ip = tcpAddress.IP
+		port = tcpAddress.Port
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		reply = append(reply, addressIPv4)
+		reply = append(reply, ip4...)
+	} else {
+		reply = append(reply, addressIPv6)
+		reply = append(reply, ip.To16()...)
+	}
+	reply = append(reply, byte(port>>8), byte(port))
+
+	_, err := writer.Write(reply)
+	return err
+}
+
+// readReply reads reply of server and returns it as it was received
+func readReply(reader io.Reader) (status byte, raw []byte, err error) {
+	header := make([]byte, 4)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return
+	}
+
+	var addressLength int
+	switch header[3] {
+	case addressIPv4:
+		addressLength = net.IPv4len
+	case addressIPv6:
+		addressLength = net.IPv6len
+	case addressDomain:
+		length := make([]byte, 1)
+		if _, err = io.ReadFull(reader, length); err != nil {
+			return
+		}
+		header = append(header, length...)
+		addressLength = int(length[0])
+	default:
+		return 0, nil, fmt.Errorf("unsupported address type: %d", header[3])
+	}
+
+	address := make([]byte, addressLength+2)
+	if _, err = io.ReadFull(reader, address); err != nil {
+		return
+	}
+	return header[1], append(header, address...), nil
+}
+
+func readString(reader io.Reader, length int) (string, error) {
+	value := make([]byte, length)
+	_, err := io.ReadFull(reader, value)
+	return string(value), err
+}
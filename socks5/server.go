@@ -0,0 +1,333 @@
+package socks5
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	log "github.com/cihub/seelog"
+	"github.com/mysterium/node/session"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const serverLogPrefix = "[socks5-server] "
+
+// dialTimeout limits how long server tries to resolve and connect to target requested by consumer
+const dialTimeout = 10 * time.Second
+
+// SessionStats is traffic of all connections which have used the session, counted from consumer's side
+type SessionStats struct {
+	SessionID     session.SessionID
+	BytesSent     int
+	BytesReceived int
+}
+
+type sessionTraffic struct {
+	sent, received int64
+}
+
+func (traffic *sessionTraffic) stats(sessionID session.SessionID) SessionStats {
+	return SessionStats{
+		SessionID:     sessionID,
+		BytesSent:     int(atomic.LoadInt64(&traffic.sent)),
+		BytesReceived: int(atomic.LoadInt64(&traffic.received)),
+	}
+}
+
+// Server is SOCKS5 proxy which lets through consumers who authenticate with valid session
+type Server struct {
+	address        string
+	checkChallenge ChallengeChecker
+	// isTargetAllowed tells if consumers may connect to given IP
+	isTargetAllowed func(net.IP) bool
+
+	mutex       sync.Mutex
+	listener    net.Listener
+	connections map[net.Conn]session.SessionID
+	traffic     map[session.SessionID]*sessionTraffic
+	// killed are sessions which traffic is kept only until it is reported
+	killed  map[session.SessionID]bool
+	stopped chan struct{}
+	serving sync.WaitGroup
+}
+
+// NewServer creates server listening on given address, signed challenges of consumers are checked by given checker
+func NewServer(address string, checkChallenge ChallengeChecker) *Server {
+	return &Server{
+		address:         address,
+		checkChallenge:  checkChallenge,
+		isTargetAllowed: isPublicIP,
+		connections:     make(map[net.Conn]session.SessionID),
+		traffic:         make(map[session.SessionID]*sessionTraffic),
+		killed:          make(map[session.SessionID]bool),
+	}
+}
+
+// Start starts listening, consumers are served in background
+func (server *Server) Start() error {
+	listener, err := net.Listen("tcp", server.address)
+	if err != nil {
+		return err
+	}
+
+	server.mutex.Lock()
+	server.listener = listener
+	server.stopped = make(chan struct{})
+	server.mutex.Unlock()
+
+	server.serving.Add(1)
+	go server.serve(listener)
+	return nil
+}
+
+// Address returns address which server listens on
+func (server *Server) Address() net.Addr {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	return server.listener.Addr()
+}
+
+// Wait blocks until server is stopped
+func (server *Server) Wait() error {
+	server.mutex.Lock()
+	stopped := server.stopped
+	server.mutex.Unlock()
+
+	if stopped != nil {
+		<-stopped
+	}
+	return nil
+}
+
+// Stop stops listening and disconnects all consumers
+func (server *Server) Stop() {
+	server.mutex.Lock()
+	if server.listener == nil {
+		server.mutex.Unlock()
+		return
+	}
+	server.listener.Close()
+	server.listener = nil
+	for connection := range server.connections {
+		connection.Close()
+	}
+	server.mutex.Unlock()
+
+	server.serving.Wait()
+	close(server.stopped)
+}
+
+// SessionStats returns traffic of sessions ordered by session id.
+// Killed sessions are included until their traffic is acknowledged as reported.
+func (server *Server) SessionStats() []SessionStats {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	stats := make([]SessionStats, 0, len(server.traffic))
+	for sessionID, traffic := range server.traffic {
+		stats = append(stats, traffic.stats(sessionID))
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].SessionID < stats[j].SessionID
+	})
+	return stats
+}
+
+// AcknowledgeSessionStats forgets traffic of killed sessions, once their final traffic is reported
+func (server *Server) AcknowledgeSessionStats(reported []SessionStats) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	for _, stats := range reported {
+		traffic, found := server.traffic[stats.SessionID]
+		if found && server.killed[stats.SessionID] && traffic.stats(stats.SessionID) == stats {
+			delete(server.killed, stats.SessionID)
+			delete(server.traffic, stats.SessionID)
+		}
+	}
+}
+
+// KillSession disconnects consumer's connections which use given session, traffic of the session is kept until it is reported
+func (server *Server) KillSession(sessionID session.SessionID) error {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if _, found := server.traffic[sessionID]; found {
+		server.killed[sessionID] = true
+	}
+	for connection, connectionSession := range server.connections {
+		if connectionSession == sessionID {
+			connection.Close()
+		}
+	}
+	return nil
+}
+
+func (server *Server) serve(listener net.Listener) {
+	defer server.serving.Done()
+
+	for {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		server.serving.Add(1)
+		go func() {
+			defer server.serving.Done()
+			defer connection.Close()
+
+			if err := server.handle(connection); err != nil {
+				log.Debug(serverLogPrefix, "Connection of ", connection.RemoteAddr(), " finished: ", err)
+			}
+		}()
+	}
+}
+
+func (server *Server) handle(connection net.Conn) error {
+	sessionID, err := server.authenticate(connection)
+	if err != nil {
+		return err
+	}
+
+	traffic, err := server.track(connection, sessionID)
+	if err != nil {
+		return err
+	}
+	defer server.untrack(connection)
+
+	req, err := readRequest(connection)
+	if err != nil {
+		return err
+	}
+	if req.command != commandConnect {
+		writeReply(connection, replyCommandNotSupported, nil)
+		return errors.New("unsupported command")
+	}
+	if req.address == "" {
+		writeReply(connection, replyAddressNotSupported, nil)
+		return errors.New("unsupported address type")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	targetAddress, err := resolveTarget(ctx, req.address, server.isTargetAllowed)
+	if err == errTargetNotAllowed {
+		writeReply(connection, replyConnectionNotAllowed, nil)
+		return err
+	}
+	if err != nil {
+		writeReply(connection, replyHostUnreachable, nil)
+		return err
+	}
+
+	var dialer net.Dialer
+	target, err := dialer.DialContext(ctx, "tcp", targetAddress)
+	if err != nil {
+		writeReply(connection, replyHostUnreachable, nil)
+		return err
+	}
+	defer target.Close()
+
+	if err := writeReply(connection, replySucceeded, target.LocalAddr()); err != nil {
+		return err
+	}
+
+	relay(connection, target, &traffic.sent, &traffic.received)
+	return nil
+}
+
+// authenticate accepts only consumers which sign random challenge of the connection with their session
+func (server *Server) authenticate(connection net.Conn) (session.SessionID, error) {
+	methods, err := readMethods(connection)
+	if err != nil {
+		return "", err
+	}
+	if !hasMethod(methods, methodSessionChallenge) {
+		writeMethod(connection, methodNoAcceptable)
+		return "", errors.New("consumer does not support session challenge authentication")
+	}
+	if err := writeMethod(connection, methodSessionChallenge); err != nil {
+		return "", err
+	}
+
+	challenge := make([]byte, challengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+	if err := writeChallenge(connection, challenge); err != nil {
+		return "", err
+	}
+
+	username, password, err := readCredentials(connection)
+	if err != nil {
+		return "", err
+	}
+	valid, err := server.checkChallenge(username, challenge, password)
+	if err != nil || !valid {
+		writeAuthStatus(connection, authFailure)
+		if err == nil {
+			err = errAuthenticationFailed
+		}
+		return "", err
+	}
+
+	return session.SessionID(username), writeAuthStatus(connection, authSuccess)
+}
+
+func (server *Server) track(connection net.Conn, sessionID session.SessionID) (*sessionTraffic, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	if server.listener == nil {
+		return nil, errors.New("server is stopped")
+	}
+
+	server.connections[connection] = sessionID
+	traffic, found := server.traffic[sessionID]
+	if !found {
+		traffic = &sessionTraffic{}
+		server.traffic[sessionID] = traffic
+	}
+	return traffic, nil
+}
+
+func (server *Server) untrack(connection net.Conn) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	delete(server.connections, connection)
+}
+
+// relay copies data both ways until one of connections is closed, copied bytes are added to given counters
+func relay(consumer, target net.Conn, sent, received *int64) {
+	done := make(chan struct{}, 2)
+	copyCounted := func(destination, source net.Conn, counter *int64) {
+		io.Copy(&countingWriter{destination, counter}, source)
+		// unblock the other direction
+		consumer.Close()
+		target.Close()
+		done <- struct{}{}
+	}
+
+	go copyCounted(target, consumer, sent)
+	go copyCounted(consumer, target, received)
+	<-done
+	<-done
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	counter *int64
+}
+
+func (w *countingWriter) Write(data []byte) (int, error) {
+	written, err := w.writer.Write(data)
+	atomic.AddInt64(w.counter, int64(written))
+	return written, err
+}
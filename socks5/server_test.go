@@ -0,0 +1,267 @@
+package socks5
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/mysterium/node/session"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// signChallenge signs challenges of connections as consumer of session-1 does
+func signChallenge(challenge []byte) (string, string, error) {
+	return "session-1", "signature-1:" + hex.EncodeToString(challenge), nil
+}
+
+func signChallengeInvalid(challenge []byte) (string, string, error) {
+	return "session-1", "signature-2:" + hex.EncodeToString(challenge), nil
+}
+
+func checkChallenge(sessionID string, challenge []byte, signature string) (bool, error) {
+	return sessionID == "session-1" && signature == "signature-1:"+hex.EncodeToString(challenge), nil
+}
+
+// startEchoServer starts target which sends back everything it receives
+func startEchoServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer connection.Close()
+				io.Copy(connection, connection)
+			}()
+		}
+	}()
+	return listener
+}
+
+// startServer starts server which lets consumers to local targets, which tests use
+func startServer(t *testing.T) *Server {
+	server := NewServer("127.0.0.1:0", checkChallenge)
+	server.isTargetAllowed = func(net.IP) bool { return true }
+	assert.NoError(t, server.Start())
+	return server
+}
+
+// connectRequest builds request to connect to given IPv4 target
+func connectRequest(t *testing.T, target net.Addr) []byte {
+	host, portValue, err := net.SplitHostPort(target.String())
+	assert.NoError(t, err)
+	port, err := strconv.Atoi(portValue)
+	assert.NoError(t, err)
+
+	request := []byte{socksVersion, commandConnect, 0x00, addressIPv4}
+	request = append(request, net.ParseIP(host).To4()...)
+	portBytes := make([]byte, 2)
+	binary.BigEndian.PutUint16(portBytes, uint16(port))
+	return append(request, portBytes...)
+}
+
+// connectThrough connects to target through given proxy, authenticating when signer is given
+func connectThrough(t *testing.T, proxy, target net.Addr, sign ChallengeSigner) (net.Conn, byte) {
+	connection, err := net.Dial("tcp", proxy.String())
+	assert.NoError(t, err)
+
+	if sign != nil {
+		assert.NoError(t, authenticate(connection, sign))
+	} else {
+		_, err = connection.Write([]byte{socksVersion, 1, methodNoAuth})
+		assert.NoError(t, err)
+		reply := make([]byte, 2)
+		_, err = io.ReadFull(connection, reply)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte{socksVersion, methodNoAuth}, reply)
+	}
+
+	_, err = connection.Write(connectRequest(t, target))
+	assert.NoError(t, err)
+	status, _, err := readReply(connection)
+	assert.NoError(t, err)
+	return connection, status
+}
+
+func assertEchoed(t *testing.T, connection net.Conn, message string) {
+	_, err := connection.Write([]byte(message))
+	assert.NoError(t, err)
+
+	echoed := make([]byte, len(message))
+	connection.SetReadDeadline(time.Now().Add(time.Second))
+	_, err = io.ReadFull(connection, echoed)
+	assert.NoError(t, err)
+	assert.Equal(t, message, string(echoed))
+}
+
+func waitForSessionStats(t *testing.T, server *Server, expected []SessionStats) {
+	for i := 0; i < 100; i++ {
+		if assert.ObjectsAreEqual(expected, server.SessionStats()) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, expected, server.SessionStats())
+}
+
+func TestServerProxiesAuthenticatedConsumer(t *testing.T) {
+	target := startEchoServer(t)
+	defer target.Close()
+	server := startServer(t)
+	defer server.Stop()
+
+	connection, status := connectThrough(t, server.Address(), target.Addr(), signChallenge)
+	defer connection.Close()
+
+	assert.Equal(t, byte(replySucceeded), status)
+	assertEchoed(t, connection, "ping")
+	waitForSessionStats(t, server, []SessionStats{{SessionID: "session-1", BytesSent: 4, BytesReceived: 4}})
+}
+
+func TestServerRejectsInvalidSignature(t *testing.T) {
+	server := startServer(t)
+	defer server.Stop()
+
+	connection, err := net.Dial("tcp", server.Address().String())
+	assert.NoError(t, err)
+	defer connection.Close()
+
+	assert.Equal(t, errAuthenticationFailed, authenticate(connection, signChallengeInvalid))
+}
+
+func TestServerRejectsReplayedSignature(t *testing.T) {
+	server := startServer(t)
+	defer server.Stop()
+
+	var signature string
+	recordSignature := func(challenge []byte) (string, string, error) {
+		sessionID, signed, err := signChallenge(challenge)
+		signature = signed
+		return sessionID, signed, err
+	}
+	replaySignature := func(challenge []byte) (string, string, error) {
+		return "session-1", signature, nil
+	}
+
+	connection, err := net.Dial("tcp", server.Address().String())
+	assert.NoError(t, err)
+	defer connection.Close()
+	assert.NoError(t, authenticate(connection, recordSignature))
+
+	replayed, err := net.Dial("tcp", server.Address().String())
+	assert.NoError(t, err)
+	defer replayed.Close()
+	assert.Equal(t, errAuthenticationFailed, authenticate(replayed, replaySignature))
+}
+
+func TestServerRejectsConsumerWithoutAuthentication(t *testing.T) {
+	server := startServer(t)
+	defer server.Stop()
+
+	connection, err := net.Dial("tcp", server.Address().String())
+	assert.NoError(t, err)
+	defer connection.Close()
+
+	_, err = connection.Write([]byte{socksVersion, 1, methodNoAuth})
+	assert.NoError(t, err)
+	reply := make([]byte, 2)
+	_, err = io.ReadFull(connection, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{socksVersion, methodNoAcceptable}, reply)
+}
+
+func TestServerRepliesWhenTargetIsUnreachable(t *testing.T) {
+	target := startEchoServer(t)
+	target.Close()
+	server := startServer(t)
+	defer server.Stop()
+
+	connection, status := connectThrough(t, server.Address(), target.Addr(), signChallenge)
+	defer connection.Close()
+
+	assert.Equal(t, byte(replyHostUnreachable), status)
+}
+
+func TestServerRefusesTargetOnLoopback(t *testing.T) {
+	target := startEchoServer(t)
+	defer target.Close()
+	server := NewServer("127.0.0.1:0", checkChallenge)
+	assert.NoError(t, server.Start())
+	defer server.Stop()
+
+	connection, status := connectThrough(t, server.Address(), target.Addr(), signChallenge)
+	defer connection.Close()
+
+	assert.Equal(t, byte(replyConnectionNotAllowed), status)
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"100.64.0.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.public, isPublicIP(net.ParseIP(test.ip)), test.ip)
+	}
+}
+
+func TestServerKillsConnectionsOfSession(t *testing.T) {
+	target := startEchoServer(t)
+	defer target.Close()
+	server := startServer(t)
+	defer server.Stop()
+
+	connection, _ := connectThrough(t, server.Address(), target.Addr(), signChallenge)
+	defer connection.Close()
+	assertEchoed(t, connection, "ping")
+	waitForSessionStats(t, server, []SessionStats{{SessionID: "session-1", BytesSent: 4, BytesReceived: 4}})
+
+	assert.NoError(t, server.KillSession(session.SessionID("session-1")))
+
+	connection.SetReadDeadline(time.Now().Add(time.Second))
+	_, err := connection.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	killedStats := []SessionStats{{SessionID: "session-1", BytesSent: 4, BytesReceived: 4}}
+	assert.Equal(t, killedStats, server.SessionStats(), "traffic of killed session is kept until it is reported")
+	server.AcknowledgeSessionStats(killedStats)
+	assert.Empty(t, server.SessionStats())
+}
+
+func TestServerStopUnblocksWait(t *testing.T) {
+	server := startServer(t)
+
+	stopped := make(chan error)
+	go func() {
+		stopped <- server.Wait()
+	}()
+	server.Stop()
+
+	select {
+	case err := <-stopped:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop")
+	}
+}
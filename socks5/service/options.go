@@ -0,0 +1,48 @@
+package service
+
+import (
+	"fmt"
+	"github.com/mysterium/node/money"
+	"strconv"
+	"strings"
+)
+
+// Options describes where socks5 service listens and how much consumers pay for it
+type Options struct {
+	Port int
+	// PricePerHour is price in MYST, zero means default price
+	PricePerHour money.Money
+}
+
+// DefaultOptions serves socks5 on its standard port for default price
+func DefaultOptions() Options {
+	return Options{
+		Port: 1080,
+	}
+}
+
+// ParseOptions parses options of format port[:price] e.g. "1080:0.02", empty value means default options
+func ParseOptions(value string) (options Options, err error) {
+	if value == "" {
+		return DefaultOptions(), nil
+	}
+
+	fields := strings.Split(value, ":")
+	if len(fields) > 2 {
+		return options, fmt.Errorf("invalid socks5 service %q, expected port[:price]", value)
+	}
+
+	options.Port, err = strconv.Atoi(fields[0])
+	if err != nil || options.Port <= 0 || options.Port > 65535 {
+		return options, fmt.Errorf("invalid port of socks5 service %q", value)
+	}
+
+	if len(fields) == 2 {
+		amount, err := money.ParseAmount(fields[1])
+		if err != nil {
+			return options, fmt.Errorf("invalid price of socks5 service %q", value)
+		}
+		options.PricePerHour = money.Money{Amount: amount, Currency: money.CURRENCY_MYST}
+	}
+	return options, nil
+}
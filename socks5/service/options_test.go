@@ -0,0 +1,28 @@
+package service
+
+import (
+	"github.com/mysterium/node/money"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	options, err := ParseOptions("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultOptions(), options)
+
+	options, err = ParseOptions("1081:0.02")
+	assert.NoError(t, err)
+	assert.Equal(t, Options{Port: 1081, PricePerHour: money.NewMoney(0.02, money.CURRENCY_MYST)}, options)
+}
+
+func TestParseOptionsWithInvalidValue(t *testing.T) {
+	_, err := ParseOptions("1080:0.02:1")
+	assert.EqualError(t, err, `invalid socks5 service "1080:0.02:1", expected port[:price]`)
+
+	_, err = ParseOptions("socks")
+	assert.EqualError(t, err, `invalid port of socks5 service "socks"`)
+
+	_, err = ParseOptions("1080:free")
+	assert.EqualError(t, err, `invalid price of socks5 service "1080:free"`)
+}
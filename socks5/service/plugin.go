@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/service"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/socks5"
+	"github.com/mysterium/node/socks5/discovery"
+	"net"
+	"strconv"
+	"time"
+)
+
+// DefaultLocalAddress is where consumer's applications connect to, when proxy is connected
+const DefaultLocalAddress = "127.0.0.1:1080"
+
+// statsReportInterval is how often client reports transferred bytes, short enough to compute current rates
+const statsReportInterval = 1 * time.Second
+
+// Bootstrap registers socks5 proposal unserializer and socks5 plugin
+func Bootstrap() {
+	socks5.Bootstrap()
+	service.RegisterPlugin(NewPlugin(DefaultLocalAddress))
+}
+
+type plugin struct {
+	localAddress string
+}
+
+// NewPlugin creates plugin which runs socks5 servers on provider and local socks5 proxy on consumer
+func NewPlugin(localAddress string) *plugin {
+	return &plugin{
+		localAddress: localAddress,
+	}
+}
+
+func (p *plugin) ServiceType() string {
+	return socks5.ServiceType
+}
+
+func (p *plugin) ParseOptions(value string) (service.Options, error) {
+	return ParseOptions(value)
+}
+
+// NewService creates service with its own socks5 server, consumers authenticate to it with signed sessions
+func (p *plugin) NewService(params service.ServiceParams, serviceOptions service.Options) (service.Service, error) {
+	options, ok := serviceOptions.(Options)
+	if !ok {
+		return nil, fmt.Errorf("invalid options of socks5 service: %v", serviceOptions)
+	}
+
+	pricePerHour := discovery.DefaultPricePerHour
+	if options.PricePerHour.Amount > 0 {
+		pricePerHour = options.PricePerHour
+	}
+	proposal := discovery.NewServiceProposalWithLocation(
+		params.ProposalID,
+		params.ProviderID,
+		params.ProviderContact,
+		params.Location,
+		pricePerHour,
+	)
+
+	clientConfig, err := socks5.NewClientConfig(params.ServerIP, options.Port)
+	if err != nil {
+		return nil, err
+	}
+	generateConfig := func() (string, error) {
+		return clientConfig, nil
+	}
+
+	// sessions get validated only once server runs, which is after session manager is created
+	var sessionManager session.Manager
+	challengeChecker := socks5.NewChallengeChecker(
+		func(sessionID session.SessionID) (session.Session, bool) {
+			return sessionManager.FindSession(sessionID)
+		},
+		identity.NewExtractor(),
+	)
+	server := socks5.NewServer(net.JoinHostPort("", strconv.Itoa(options.Port)), challengeChecker)
+
+	expiringManager := params.NewSessionManager(generateConfig, server.KillSession)
+	sessionManager = expiringManager
+
+	return NewService(proposal, expiringManager, server), nil
+}
+
+// NewConnection creates local proxy, which authenticates to provider with challenges signed by consumer
+func (p *plugin) NewConnection(params service.ConnectionParams) (service.Connection, error) {
+	clientConfig, err := socks5.ParseClientConfig(params.Session.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return socks5.NewClient(
+		p.localAddress,
+		clientConfig.Address(),
+		socks5.NewChallengeSigner(params.Session.ID, params.Signer),
+		params.StateCallback,
+		params.StatsHandler,
+		statsReportInterval,
+	), nil
+}
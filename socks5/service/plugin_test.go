@@ -0,0 +1,64 @@
+package service
+
+import (
+	"github.com/mysterium/node/firewall"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/money"
+	openvpn_session "github.com/mysterium/node/openvpn/session"
+	"github.com/mysterium/node/service"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/socks5"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPluginCreatesServiceOfProposal(t *testing.T) {
+	var generateConfig session.ConfigGenerator
+	params := service.ServiceParams{
+		ProposalID: 3,
+		ProviderID: identity.FromAddress("provider"),
+		ServerIP:   "1.2.3.4",
+		NewSessionManager: func(configGenerator session.ConfigGenerator, killClient session.ClientKiller) session.ExpiringManager {
+			generateConfig = configGenerator
+			return openvpn_session.NewManager(
+				configGenerator,
+				&session.UUIDGenerator{},
+				session.NewStorageMemory(),
+				openvpn_session.DefaultExpiryPolicy(),
+				openvpn_session.DefaultLimitPolicy(),
+				killClient,
+			)
+		},
+	}
+
+	socks5Service, err := NewPlugin(DefaultLocalAddress).NewService(params, Options{Port: 1081, PricePerHour: money.NewMoney(0.02, money.CURRENCY_MYST)})
+	assert.NoError(t, err)
+
+	proposal := socks5Service.Proposal()
+	assert.Equal(t, 3, proposal.ID)
+	assert.Equal(t, socks5.ServiceType, proposal.ServiceType)
+	assert.Equal(t, "provider", proposal.ProviderID)
+	assert.Equal(t, money.NewMoney(0.02, money.CURRENCY_MYST), proposal.PaymentMethod.GetPrice())
+
+	config, err := generateConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"host":"1.2.3.4","port":1081}`, config)
+}
+
+func TestConnectionAllowsServerInKillSwitch(t *testing.T) {
+	connection, err := NewPlugin(DefaultLocalAddress).NewConnection(service.ConnectionParams{
+		Session: session.SessionDto{ID: "session-1", Config: `{"host":"1.2.3.4","port":1081}`},
+		Signer:  &identity.SignerFake{},
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []firewall.RuleAllowed{{RemoteAddress: "1.2.3.4"}}, connection.KillSwitchRules())
+}
+
+func TestPluginRejectsInvalidSessionConfig(t *testing.T) {
+	_, err := NewPlugin(DefaultLocalAddress).NewConnection(service.ConnectionParams{
+		Session: session.SessionDto{ID: "session-1", Config: `{"port":1081}`},
+	})
+
+	assert.EqualError(t, err, "host is not defined in client configuration")
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	server_dto "github.com/mysterium/node/server/dto"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/socks5"
+)
+
+type socks5Service struct {
+	proposal       dto_discovery.ServiceProposal
+	sessionManager session.ExpiringManager
+	server         *socks5.Server
+}
+
+// NewService creates service which serves sessions of given manager with its own socks5 server
+func NewService(
+	proposal dto_discovery.ServiceProposal,
+	sessionManager session.ExpiringManager,
+	server *socks5.Server,
+) *socks5Service {
+	return &socks5Service{
+		proposal:       proposal,
+		sessionManager: sessionManager,
+		server:         server,
+	}
+}
+
+func (service *socks5Service) Proposal() dto_discovery.ServiceProposal {
+	return service.proposal
+}
+
+func (service *socks5Service) SessionManager() session.Manager {
+	return service.sessionManager
+}
+
+// SessionStats returns traffic of sessions counted by socks5 server
+func (service *socks5Service) SessionStats() []server_dto.SessionStats {
+	sessionStats := make([]server_dto.SessionStats, 0)
+	for _, stats := range service.server.SessionStats() {
+		sessionStats = append(sessionStats, server_dto.SessionStats{
+			SessionID:     stats.SessionID,
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+		})
+	}
+	return sessionStats
+}
+
+// AcknowledgeSessionStats lets socks5 server forget traffic of killed sessions, which is reported
+func (service *socks5Service) AcknowledgeSessionStats(reported []server_dto.SessionStats) {
+	serverStats := make([]socks5.SessionStats, 0, len(reported))
+	for _, stats := range reported {
+		serverStats = append(serverStats, socks5.SessionStats{
+			SessionID:     stats.SessionID,
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+		})
+	}
+	service.server.AcknowledgeSessionStats(serverStats)
+}
+
+func (service *socks5Service) Start() error {
+	service.sessionManager.Start()
+	if err := service.server.Start(); err != nil {
+		service.sessionManager.Stop()
+		return err
+	}
+	return nil
+}
+
+func (service *socks5Service) Wait() error {
+	return service.server.Wait()
+}
+
+func (service *socks5Service) Stop() {
+	service.server.Stop()
+	service.sessionManager.Stop()
+}
@@ -0,0 +1,76 @@
+package socks5
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+// errTargetNotAllowed is returned when consumer requests target which is private to provider
+var errTargetNotAllowed = errors.New("target is not allowed")
+
+// privateNetworks are not reachable through proxy, so that consumers do not get into provider's network
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
+
+// isPublicIP tells if IP is reachable on the internet and does not belong to provider itself
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.Equal(net.IPv4bcast) {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	interfaceAddresses, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, address := range interfaceAddresses {
+		if network, ok := address.(*net.IPNet); ok && network.IP.Equal(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveTarget resolves host of target and returns address of its first IP which consumer may connect to.
+// Target is dialed by resolved IP, so that host can not resolve to other IP once it is checked.
+func resolveTarget(ctx context.Context, address string, isAllowed func(net.IP) bool) (string, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+
+	addresses, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	for _, ipAddress := range addresses {
+		if isAllowed(ipAddress.IP) {
+			return net.JoinHostPort(ipAddress.IP.String(), port), nil
+		}
+	}
+	return "", errTargetNotAllowed
+}
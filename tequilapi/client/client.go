@@ -119,6 +119,19 @@ func (client *Client) ConnectionStatistics() (StatisticsDTO, error) {
 	return statistics, err
 }
 
+// ConnectionCost returns cost of current connection
+func (client *Client) ConnectionCost() (CostDTO, error) {
+	response, err := client.http.Get("connection/cost", url.Values{})
+	if err != nil {
+		return CostDTO{}, err
+	}
+	defer response.Body.Close()
+
+	var cost CostDTO
+	err = parseResponseJson(response, &cost)
+	return cost, err
+}
+
 // Status returns connection status
 func (client *Client) Status() (StatusDTO, error) {
 	response, err := client.http.Get("connection", url.Values{})
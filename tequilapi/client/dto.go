@@ -8,9 +8,17 @@ type StatusDTO struct {
 
 // StatisticsDTO holds statistics about connection
 type StatisticsDTO struct {
-	BytesSent     int `json:"bytesSent"`
-	BytesReceived int `json:"bytesReceived"`
-	Duration      int `json:"duration"`
+	BytesSent              int     `json:"bytesSent"`
+	BytesReceived          int     `json:"bytesReceived"`
+	Duration               int     `json:"duration"`
+	BytesSentPerSecond     float64 `json:"bytesSentPerSecond"`
+	BytesReceivedPerSecond float64 `json:"bytesReceivedPerSecond"`
+}
+
+// CostDTO holds cost of connection in the smallest units of currency
+type CostDTO struct {
+	Amount   uint64 `json:"amount"`
+	Currency string `json:"currency"`
 }
 
 // ProposalList describes list of proposals
@@ -6,15 +6,19 @@ import (
 	"github.com/mysterium/node/client_connection"
 	"github.com/mysterium/node/identity"
 	"github.com/mysterium/node/ip"
+	"github.com/mysterium/node/metering"
 	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	"github.com/mysterium/node/session"
 	"github.com/mysterium/node/tequilapi/utils"
 	"github.com/mysterium/node/tequilapi/validation"
 	"net/http"
 )
 
 type connectionRequest struct {
-	Identity string `json:"identity"`
-	NodeKey  string `json:"nodeKey"`
+	Identity    string `json:"identity"`
+	NodeKey     string `json:"nodeKey"`
+	ProposalID  int    `json:"proposalId,omitempty"`
+	ServiceType string `json:"serviceType,omitempty"`
 }
 
 type statusResponse struct {
@@ -54,10 +58,14 @@ func (ce *connectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = ce.manager.Connect(identity.FromAddress(cr.Identity), cr.NodeKey)
+	filter := client_connection.ProposalFilter{
+		ProposalID:  cr.ProposalID,
+		ServiceType: cr.ServiceType,
+	}
+	err = ce.manager.Connect(req.Context(), identity.FromAddress(cr.Identity), cr.NodeKey, filter)
 
 	if err != nil {
-		utils.SendError(resp, err, http.StatusInternalServerError)
+		utils.SendError(resp, err, connectionErrorStatusCode(err))
 		return
 	}
 	resp.WriteHeader(http.StatusCreated)
@@ -65,7 +73,11 @@ func (ce *connectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 }
 
 func (ce *connectionEndpoint) Kill(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
-	ce.manager.Disconnect()
+	err := ce.manager.Disconnect()
+	if err != nil {
+		utils.SendError(resp, err, connectionErrorStatusCode(err))
+		return
+	}
 	resp.WriteHeader(http.StatusAccepted)
 }
 
@@ -84,20 +96,54 @@ func (ce *connectionEndpoint) GetIP(writer http.ResponseWriter, request *http.Re
 	utils.WriteAsJSON(response, writer)
 }
 
-// GetStatistics returns statistics about current connection
+// GetStatistics returns statistics and current throughput of current connection
 func (ce *connectionEndpoint) GetStatistics(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	stats := ce.statsKeeper.Retrieve()
+	rates := ce.statsKeeper.RetrieveRates()
 
 	duration := ce.statsKeeper.GetSessionDuration()
 
 	response := struct {
-		BytesSent     int `json:"bytesSent"`
-		BytesReceived int `json:"bytesReceived"`
-		Duration      int `json:"duration"`
+		BytesSent              int     `json:"bytesSent"`
+		BytesReceived          int     `json:"bytesReceived"`
+		Duration               int     `json:"duration"`
+		BytesSentPerSecond     float64 `json:"bytesSentPerSecond"`
+		BytesReceivedPerSecond float64 `json:"bytesReceivedPerSecond"`
+	}{
+		BytesSent:              stats.BytesSent,
+		BytesReceived:          stats.BytesReceived,
+		Duration:               int(duration.Seconds()),
+		BytesSentPerSecond:     rates.BytesSentPerSecond,
+		BytesReceivedPerSecond: rates.BytesReceivedPerSecond,
+	}
+	utils.WriteAsJSON(response, writer)
+}
+
+// GetCost returns cost of current session, computed from its statistics by payment method of proposal
+func (ce *connectionEndpoint) GetCost(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	proposal, found := ce.manager.ActiveProposal()
+	if !found {
+		utils.SendError(writer, client_connection.ErrNoConnection, http.StatusConflict)
+		return
+	}
+
+	stats := ce.statsKeeper.Retrieve()
+	usage := metering.Usage{
+		Duration: ce.statsKeeper.GetSessionDuration(),
+		Bytes:    uint64(stats.BytesSent + stats.BytesReceived),
+	}
+	cost, err := metering.Cost(proposal.PaymentMethod, usage)
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := struct {
+		Amount   uint64 `json:"amount"`
+		Currency string `json:"currency"`
 	}{
-		BytesSent:     stats.BytesSent,
-		BytesReceived: stats.BytesReceived,
-		Duration:      int(duration.Seconds()),
+		Amount:   cost.Amount,
+		Currency: string(cost.Currency),
 	}
 	utils.WriteAsJSON(response, writer)
 }
@@ -111,6 +157,7 @@ func AddRoutesForConnection(router *httprouter.Router, manager client_connection
 	router.DELETE("/connection", connectionEndpoint.Kill)
 	router.GET("/connection/ip", connectionEndpoint.GetIP)
 	router.GET("/connection/statistics", connectionEndpoint.GetStatistics)
+	router.GET("/connection/cost", connectionEndpoint.GetCost)
 }
 
 func toConnectionRequest(req *http.Request) (*connectionRequest, error) {
@@ -130,9 +177,38 @@ func validateConnectionRequest(cr *connectionRequest) *validation.FieldErrorMap
 	if len(cr.NodeKey) == 0 {
 		errors.ForField("nodeKey").AddError("required", "Field is required")
 	}
+	if cr.ProposalID < 0 {
+		errors.ForField("proposalId").AddError("invalid", "Field has to be positive")
+	}
 	return errors
 }
 
+// connectionErrorStatusCode maps errors of connection manager to http status codes
+func connectionErrorStatusCode(err error) int {
+	switch err.(type) {
+	case *client_connection.TransitionError:
+		return http.StatusConflict
+	case *session.CreateError:
+		switch err.(*session.CreateError).Code {
+		case session.ErrorRateLimited:
+			return http.StatusTooManyRequests
+		case session.ErrorAccessDenied:
+			return http.StatusForbidden
+		}
+		return http.StatusServiceUnavailable
+	}
+
+	switch err {
+	case client_connection.ErrAlreadyConnecting,
+		client_connection.ErrAlreadyConnected,
+		client_connection.ErrDisconnecting,
+		client_connection.ErrNoConnection,
+		client_connection.ErrConnectionCancelled:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 func toStatusResponse(status client_connection.ConnectionStatus) statusResponse {
 	return statusResponse{
 		Status:    string(status.State),
@@ -0,0 +1,59 @@
+package endpoints
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/dns"
+	"github.com/mysterium/node/tequilapi/utils"
+	"net/http"
+)
+
+type dnsStatusResponse struct {
+	Active        bool     `json:"active"`
+	Protected     bool     `json:"protected"`
+	TunnelServers []string `json:"tunnelServers"`
+	SystemServers []string `json:"systemServers"`
+}
+
+type connectionDNSEndpoint struct {
+	protector dns.Protector
+}
+
+// NewConnectionDNSEndpoint creates endpoint which reports whether names are resolved through the tunnel
+func NewConnectionDNSEndpoint(protector dns.Protector) *connectionDNSEndpoint {
+	return &connectionDNSEndpoint{
+		protector: protector,
+	}
+}
+
+// GetStatus responds with name servers of the tunnel and the ones system actually uses
+func (cde *connectionDNSEndpoint) GetStatus(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	status, err := cde.protector.Status()
+	if err == dns.ErrUnsupported {
+		utils.SendError(writer, err, http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := dnsStatusResponse{
+		Active:        status.Active,
+		Protected:     status.Protected,
+		TunnelServers: status.TunnelServers,
+		SystemServers: status.SystemServers,
+	}
+	if response.TunnelServers == nil {
+		response.TunnelServers = []string{}
+	}
+	if response.SystemServers == nil {
+		response.SystemServers = []string{}
+	}
+	utils.WriteAsJSON(response, writer)
+}
+
+// AddRoutesForConnectionDNS adds connection dns route to given router
+func AddRoutesForConnectionDNS(router *httprouter.Router, protector dns.Protector) {
+	connectionDNSEndpoint := NewConnectionDNSEndpoint(protector)
+	router.GET("/connection/dns", connectionDNSEndpoint.GetStatus)
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"errors"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/dns"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeDNSProtector struct {
+	status dns.Status
+	err    error
+}
+
+func (fdp *fakeDNSProtector) Protect(device string, servers []string) error {
+	return nil
+}
+
+func (fdp *fakeDNSProtector) Release() error {
+	return nil
+}
+
+func (fdp *fakeDNSProtector) Status() (dns.Status, error) {
+	return fdp.status, fdp.err
+}
+
+func TestDNSStatusIsReturnedWhenNotConnected(t *testing.T) {
+	protector := &fakeDNSProtector{status: dns.Status{SystemServers: []string{"192.168.1.1"}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionDNSEndpoint(protector).GetStatus(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"active": false,
+			"protected": false,
+			"tunnelServers": [],
+			"systemServers": ["192.168.1.1"]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestDNSStatusReportsProtection(t *testing.T) {
+	protector := &fakeDNSProtector{status: dns.Status{
+		Active:        true,
+		TunnelServers: []string{"10.8.0.1"},
+		SystemServers: []string{"10.8.0.1"},
+		Protected:     true,
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionDNSEndpoint(protector).GetStatus(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"active": true,
+			"protected": true,
+			"tunnelServers": ["10.8.0.1"],
+			"systemServers": ["10.8.0.1"]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestDNSStatusFailsWhenVerificationFails(t *testing.T) {
+	protector := &fakeDNSProtector{err: errors.New("resolver is unavailable")}
+
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionDNSEndpoint(protector).GetStatus(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"message": "resolver is unavailable"}`, resp.Body.String())
+}
+
+func TestDNSStatusIsNotImplementedOnUnsupportedPlatform(t *testing.T) {
+	protector := &fakeDNSProtector{err: dns.ErrUnsupported}
+
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionDNSEndpoint(protector).GetStatus(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+	assert.JSONEq(t, `{"message": "name server configuration is not supported on this platform"}`, resp.Body.String())
+}
@@ -0,0 +1,110 @@
+package endpoints
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/client_connection"
+	"github.com/mysterium/node/tequilapi/utils"
+	"net/http"
+	"time"
+)
+
+// keepAliveInterval defines how often comment line is written to idle stream, so that dead clients are noticed
+const keepAliveInterval = 15 * time.Second
+
+type stateResponse struct {
+	State string `json:"state"`
+}
+
+type statisticsResponse struct {
+	BytesSent     int `json:"bytesSent"`
+	BytesReceived int `json:"bytesReceived"`
+}
+
+type connectionEventsEndpoint struct {
+	manager         client_connection.Manager
+	eventSubscriber client_connection.EventSubscriber
+}
+
+// NewConnectionEventsEndpoint creates endpoint which streams connection events to its clients
+func NewConnectionEventsEndpoint(manager client_connection.Manager, eventSubscriber client_connection.EventSubscriber) *connectionEventsEndpoint {
+	return &connectionEventsEndpoint{
+		manager:         manager,
+		eventSubscriber: eventSubscriber,
+	}
+}
+
+// Stream writes connection events as Server-Sent Events until client goes away
+func (cee *connectionEventsEndpoint) Stream(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		utils.SendError(writer, fmt.Errorf("streaming is not supported"), http.StatusInternalServerError)
+		return
+	}
+
+	events := cee.eventSubscriber.Subscribe()
+	defer cee.eventSubscriber.Unsubscribe(events)
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+
+	currentStatus := client_connection.Event{Type: client_connection.StatusEvent, Status: cee.manager.Status()}
+	if err := writeEvent(writer, currentStatus); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case event, more := <-events:
+			if !more {
+				return
+			}
+			if err := writeEvent(writer, event); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// AddRoutesForConnectionEvents adds connection events route to given router
+func AddRoutesForConnectionEvents(router *httprouter.Router, manager client_connection.Manager, eventSubscriber client_connection.EventSubscriber) {
+	connectionEventsEndpoint := NewConnectionEventsEndpoint(manager, eventSubscriber)
+	router.GET("/connection/events", connectionEventsEndpoint.Stream)
+}
+
+func writeEvent(writer http.ResponseWriter, event client_connection.Event) error {
+	data, err := json.Marshal(toEventResponse(event))
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event.Type, data)
+	return err
+}
+
+func toEventResponse(event client_connection.Event) interface{} {
+	switch event.Type {
+	case client_connection.StateEvent:
+		return stateResponse{string(event.State)}
+	case client_connection.StatisticsEvent:
+		return statisticsResponse{
+			BytesSent:     event.Statistics.BytesSent,
+			BytesReceived: event.Statistics.BytesReceived,
+		}
+	}
+	return toStatusResponse(event.Status)
+}
@@ -0,0 +1,97 @@
+package endpoints
+
+import (
+	"context"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/client_connection"
+	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	"github.com/mysterium/node/service"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeEventSubscriber struct {
+	events       chan client_connection.Event
+	unsubscribed bool
+}
+
+func (fes *fakeEventSubscriber) Subscribe() <-chan client_connection.Event {
+	return fes.events
+}
+
+func (fes *fakeEventSubscriber) Unsubscribe(events <-chan client_connection.Event) {
+	fes.unsubscribed = true
+}
+
+func TestConnectionEventsAreStreamed(t *testing.T) {
+	manager := fakeManager{}
+	manager.onStatusReturn = client_connection.ConnectionStatus{
+		State: client_connection.Connecting,
+	}
+	subscriber := &fakeEventSubscriber{events: make(chan client_connection.Event, 3)}
+	subscriber.events <- client_connection.Event{
+		Type: client_connection.StatusEvent,
+		Status: client_connection.ConnectionStatus{
+			State:     client_connection.Connected,
+			SessionID: "session-id",
+		},
+	}
+	subscriber.events <- client_connection.Event{
+		Type:  client_connection.StateEvent,
+		State: service.ConnectionConnecting,
+	}
+	subscriber.events <- client_connection.Event{
+		Type:       client_connection.StatisticsEvent,
+		Statistics: bytescount.SessionStats{BytesSent: 1, BytesReceived: 2},
+	}
+	close(subscriber.events)
+
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionEventsEndpoint(&manager, subscriber).Stream(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+	assert.True(t, resp.Flushed)
+	assert.Equal(
+		t,
+		"event: status\ndata: {\"status\":\"Connecting\"}\n\n"+
+			"event: status\ndata: {\"status\":\"Connected\",\"sessionId\":\"session-id\"}\n\n"+
+			"event: state\ndata: {\"state\":\"CONNECTING\"}\n\n"+
+			"event: statistics\ndata: {\"bytesSent\":1,\"bytesReceived\":2}\n\n",
+		resp.Body.String(),
+	)
+	assert.True(t, subscriber.unsubscribed)
+}
+
+func TestConnectionEventsStreamStopsWhenClientGoesAway(t *testing.T) {
+	subscriber := &fakeEventSubscriber{events: make(chan client_connection.Event)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil).WithContext(ctx)
+	resp := httptest.NewRecorder()
+
+	NewConnectionEventsEndpoint(&fakeManager{}, subscriber).Stream(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, subscriber.unsubscribed)
+}
+
+func TestConnectionEventsRouteIsAdded(t *testing.T) {
+	subscriber := &fakeEventSubscriber{events: make(chan client_connection.Event)}
+	close(subscriber.events)
+
+	router := httprouter.New()
+	AddRoutesForConnectionEvents(router, &fakeManager{}, subscriber)
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/events", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/event-stream", resp.Header().Get("Content-Type"))
+}
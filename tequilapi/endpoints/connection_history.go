@@ -0,0 +1,146 @@
+package endpoints
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/client_connection"
+	"github.com/mysterium/node/tequilapi/utils"
+	"github.com/mysterium/node/tequilapi/validation"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 1000
+)
+
+type historyRecordResponse struct {
+	ID               int    `json:"id"`
+	SessionID        string `json:"sessionId"`
+	ProviderID       string `json:"providerId"`
+	ProposalID       int    `json:"proposalId"`
+	StartedAt        string `json:"startedAt"`
+	EndedAt          string `json:"endedAt,omitempty"`
+	Duration         int    `json:"duration"`
+	BytesSent        int    `json:"bytesSent"`
+	BytesReceived    int    `json:"bytesReceived"`
+	DisconnectReason string `json:"disconnectReason,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+type historyResponse struct {
+	Sessions []historyRecordResponse `json:"sessions"`
+	Total    int                     `json:"total"`
+	Offset   int                     `json:"offset"`
+	Limit    int                     `json:"limit"`
+}
+
+type connectionHistoryEndpoint struct {
+	historyStore client_connection.HistoryStore
+}
+
+// NewConnectionHistoryEndpoint creates endpoint which lists past connections
+func NewConnectionHistoryEndpoint(historyStore client_connection.HistoryStore) *connectionHistoryEndpoint {
+	return &connectionHistoryEndpoint{
+		historyStore: historyStore,
+	}
+}
+
+// List responds with page of connection history, newest connections first
+func (che *connectionHistoryEndpoint) List(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	query, errorMap := toHistoryQuery(request.URL.Query())
+	if errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(writer, errorMap)
+		return
+	}
+
+	records, total, err := che.historyStore.List(query)
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := historyResponse{
+		Sessions: make([]historyRecordResponse, 0, len(records)),
+		Total:    total,
+		Offset:   query.Offset,
+		Limit:    query.Limit,
+	}
+	for _, record := range records {
+		response.Sessions = append(response.Sessions, toHistoryRecordResponse(record))
+	}
+	utils.WriteAsJSON(response, writer)
+}
+
+// AddRoutesForConnectionHistory adds connection history route to given router
+func AddRoutesForConnectionHistory(router *httprouter.Router, historyStore client_connection.HistoryStore) {
+	connectionHistoryEndpoint := NewConnectionHistoryEndpoint(historyStore)
+	router.GET("/connection/history", connectionHistoryEndpoint.List)
+}
+
+func toHistoryQuery(values url.Values) (client_connection.HistoryQuery, *validation.FieldErrorMap) {
+	errors := validation.NewErrorMap()
+	query := client_connection.HistoryQuery{
+		ProviderID: values.Get("providerId"),
+		From:       parseTimeParam(values, "from", errors),
+		To:         parseTimeParam(values, "to", errors),
+		Offset:     parseIntParam(values, "offset", 0, errors),
+		Limit:      parseIntParam(values, "limit", defaultHistoryLimit, errors),
+	}
+
+	if query.Offset < 0 {
+		errors.ForField("offset").AddError("invalid", "Field has to be positive")
+	}
+	if query.Limit <= 0 || query.Limit > maxHistoryLimit {
+		errors.ForField("limit").AddError("invalid", "Field has to be between 1 and "+strconv.Itoa(maxHistoryLimit))
+	}
+	return query, errors
+}
+
+func parseTimeParam(values url.Values, name string, errors *validation.FieldErrorMap) time.Time {
+	value := values.Get(name)
+	if value == "" {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		errors.ForField(name).AddError("invalid", "Field has to be RFC3339 time")
+	}
+	return parsed
+}
+
+func parseIntParam(values url.Values, name string, defaultValue int, errors *validation.FieldErrorMap) int {
+	value := values.Get(name)
+	if value == "" {
+		return defaultValue
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		errors.ForField(name).AddError("invalid", "Field has to be a number")
+		return defaultValue
+	}
+	return parsed
+}
+
+func toHistoryRecordResponse(record client_connection.HistoryRecord) historyRecordResponse {
+	response := historyRecordResponse{
+		ID:               record.ID,
+		SessionID:        string(record.SessionID),
+		ProviderID:       record.ProviderID,
+		ProposalID:       record.ProposalID,
+		StartedAt:        record.Started.UTC().Format(time.RFC3339),
+		BytesSent:        record.Stats.BytesSent,
+		BytesReceived:    record.Stats.BytesReceived,
+		DisconnectReason: string(record.DisconnectReason),
+		Error:            record.Error,
+	}
+	if !record.Ended.IsZero() {
+		response.EndedAt = record.Ended.UTC().Format(time.RFC3339)
+		response.Duration = int(record.Ended.Sub(record.Started).Seconds())
+	}
+	return response
+}
@@ -0,0 +1,136 @@
+package endpoints
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/client_connection"
+	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func createHistoryStore() client_connection.HistoryStore {
+	store := client_connection.NewHistoryStoreFake()
+	started := time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+	store.Add(client_connection.HistoryRecord{
+		SessionID:        "session-1",
+		ProviderID:       "provider-1",
+		ProposalID:       1,
+		Started:          started,
+		Ended:            started.Add(90 * time.Second),
+		Stats:            bytescount.SessionStats{BytesSent: 10, BytesReceived: 20},
+		DisconnectReason: client_connection.ReasonDisconnected,
+	})
+	store.Add(client_connection.HistoryRecord{
+		ProviderID:       "provider-2",
+		Started:          started.Add(time.Hour),
+		Ended:            started.Add(time.Hour),
+		DisconnectReason: client_connection.ReasonConnectFailed,
+		Error:            "node has no service proposals",
+	})
+	store.Add(client_connection.HistoryRecord{
+		SessionID:  "session-3",
+		ProviderID: "provider-1",
+		ProposalID: 1,
+		Started:    started.Add(2 * time.Hour),
+	})
+	return store
+}
+
+func TestConnectionHistoryIsListedNewestFirst(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/connection/history?limit=2", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionHistoryEndpoint(createHistoryStore()).List(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"sessions": [
+				{
+					"id": 3,
+					"sessionId": "session-3",
+					"providerId": "provider-1",
+					"proposalId": 1,
+					"startedAt": "2018-01-02T12:00:00Z",
+					"duration": 0,
+					"bytesSent": 0,
+					"bytesReceived": 0
+				},
+				{
+					"id": 2,
+					"sessionId": "",
+					"providerId": "provider-2",
+					"proposalId": 0,
+					"startedAt": "2018-01-02T11:00:00Z",
+					"endedAt": "2018-01-02T11:00:00Z",
+					"duration": 0,
+					"bytesSent": 0,
+					"bytesReceived": 0,
+					"disconnectReason": "connect_failed",
+					"error": "node has no service proposals"
+				}
+			],
+			"total": 3,
+			"offset": 0,
+			"limit": 2
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestConnectionHistoryIsFiltered(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/connection/history?providerId=provider-1&to=2018-01-02T11:30:00Z", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionHistoryEndpoint(createHistoryStore()).List(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"sessions": [
+				{
+					"id": 1,
+					"sessionId": "session-1",
+					"providerId": "provider-1",
+					"proposalId": 1,
+					"startedAt": "2018-01-02T10:00:00Z",
+					"endedAt": "2018-01-02T10:01:30Z",
+					"duration": 90,
+					"bytesSent": 10,
+					"bytesReceived": 20,
+					"disconnectReason": "disconnected"
+				}
+			],
+			"total": 1,
+			"offset": 0,
+			"limit": 50
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestConnectionHistoryQueryIsValidated(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/connection/history?from=yesterday&offset=-1&limit=abc", nil)
+	resp := httptest.NewRecorder()
+
+	NewConnectionHistoryEndpoint(createHistoryStore()).List(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "validation_error",
+			"errors": {
+				"from": [{"code": "invalid", "message": "Field has to be RFC3339 time"}],
+				"offset": [{"code": "invalid", "message": "Field has to be positive"}],
+				"limit": [{"code": "invalid", "message": "Field has to be a number"}]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
@@ -1,12 +1,17 @@
 package endpoints
 
 import (
+	"context"
 	"errors"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysterium/node/client_connection"
 	"github.com/mysterium/node/identity"
 	"github.com/mysterium/node/ip"
+	"github.com/mysterium/node/money"
+	dto_openvpn "github.com/mysterium/node/openvpn/discovery/dto"
 	"github.com/mysterium/node/openvpn/middlewares/client/bytescount"
+	dto_discovery "github.com/mysterium/node/service_discovery/dto"
+	"github.com/mysterium/node/session"
 	"github.com/mysterium/node/utils"
 	"github.com/stretchr/testify/assert"
 	"net/http"
@@ -20,14 +25,17 @@ type fakeManager struct {
 	onConnectReturn    error
 	onDisconnectReturn error
 	onStatusReturn     client_connection.ConnectionStatus
+	activeProposal     *dto_discovery.ServiceProposal
 	disconnectCount    int
 	requestedIdentity  identity.Identity
 	requestedNode      string
+	requestedFilter    client_connection.ProposalFilter
 }
 
-func (fm *fakeManager) Connect(identity identity.Identity, node string) error {
+func (fm *fakeManager) Connect(ctx context.Context, identity identity.Identity, node string, filter client_connection.ProposalFilter) error {
 	fm.requestedIdentity = identity
 	fm.requestedNode = node
+	fm.requestedFilter = filter
 	return fm.onConnectReturn
 }
 
@@ -45,6 +53,13 @@ func (fm *fakeManager) Wait() error {
 	return nil
 }
 
+func (fm *fakeManager) ActiveProposal() (dto_discovery.ServiceProposal, bool) {
+	if fm.activeProposal == nil {
+		return dto_discovery.ServiceProposal{}, false
+	}
+	return *fm.activeProposal, true
+}
+
 func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	router := httprouter.New()
 	fakeManager := fakeManager{}
@@ -54,7 +69,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 
 	sessionStart := time.Date(2000, time.January, 0, 10, 0, 0, 0, time.UTC)
 	settableClock.SetTime(sessionStart)
-	statsKeeper.MarkSessionStart()
+	statsKeeper.MarkSessionStart("session-id")
 	settableClock.SetTime(sessionStart.Add(time.Minute))
 
 	AddRoutesForConnection(router, &fakeManager, ipResolver, statsKeeper)
@@ -87,9 +102,15 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 			http.StatusOK, `{
 				"bytesSent": 0,
 				"bytesReceived": 0,
-				"duration": 60
+				"duration": 60,
+				"bytesSentPerSecond": 0,
+				"bytesReceivedPerSecond": 0
 			}`,
 		},
+		{
+			http.MethodGet, "/connection/cost", "",
+			http.StatusConflict, `{"message": "no connection exists"}`,
+		},
 	}
 
 	for _, test := range tests {
@@ -257,7 +278,123 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 
 	assert.Equal(t, identity.FromAddress("my-identity"), fakeManager.requestedIdentity)
 	assert.Equal(t, "required-node", fakeManager.requestedNode)
+	assert.Equal(t, client_connection.ProposalFilter{}, fakeManager.requestedFilter)
+}
+
+func TestPutWithProposalCriteriaCreatesConnectionToMatchingProposal(t *testing.T) {
+	fakeManager := fakeManager{}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"identity" : "my-identity",
+				"nodeKey" : "required-node",
+				"proposalId" : 2,
+				"serviceType" : "openvpn"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(
+		t,
+		client_connection.ProposalFilter{ProposalID: 2, ServiceType: "openvpn"},
+		fakeManager.requestedFilter,
+	)
+}
+
+func TestPutReturns429ErrorIfProviderRateLimitsSessions(t *testing.T) {
+	fakeManager := fakeManager{}
+	fakeManager.onConnectReturn = &session.CreateError{
+		Code:    session.ErrorRateLimited,
+		Message: "session creation rate limit exceeded",
+	}
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"identity" : "my-identity",
+				"nodeKey" : "required-node"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "session create rejected (rate_limited): session creation rate limit exceeded"
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPutReturns409ErrorIfConnectionIsAlreadyInProgress(t *testing.T) {
+	fakeManager := fakeManager{}
+	fakeManager.onConnectReturn = client_connection.ErrAlreadyConnecting
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"identity" : "my-identity",
+				"nodeKey" : "required-node"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
 
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message" : "connection is already in progress"
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPutReturns500ErrorIfConnectionFails(t *testing.T) {
+	fakeManager := fakeManager{}
+	fakeManager.onConnectReturn = errors.New("fatal connection error")
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"identity" : "my-identity",
+				"nodeKey" : "required-node"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
+
+func TestDeleteReturns409ErrorIfThereIsNoConnection(t *testing.T) {
+	fakeManager := fakeManager{}
+	fakeManager.onDisconnectReturn = client_connection.ErrNoConnection
+
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, nil)
+	req := httptest.NewRequest(http.MethodDelete, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Kill(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
 }
 
 func TestDeleteCallsDisconnect(t *testing.T) {
@@ -313,13 +450,12 @@ func TestGetIPEndpointReturnsErrorWhenIPDetectionFails(t *testing.T) {
 func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	settableClock := utils.SettableClock{}
 	statsKeeper := bytescount.NewSessionStatsKeeper(settableClock.GetTime)
-	stats := bytescount.SessionStats{BytesSent: 1, BytesReceived: 2}
-	statsKeeper.Save(stats)
 
 	sessionStart := time.Date(2000, time.January, 0, 10, 0, 0, 0, time.UTC)
 	settableClock.SetTime(sessionStart)
-	statsKeeper.MarkSessionStart()
+	statsKeeper.MarkSessionStart("session-id")
 	settableClock.SetTime(sessionStart.Add(time.Minute))
+	statsKeeper.Save("session-id", bytescount.SessionStats{BytesSent: 60, BytesReceived: 120})
 
 	manager := fakeManager{}
 	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper)
@@ -329,9 +465,11 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
-			"bytesSent": 1,
-			"bytesReceived": 2,
-			"duration": 60
+			"bytesSent": 60,
+			"bytesReceived": 120,
+			"duration": 60,
+			"bytesSentPerSecond": 1,
+			"bytesReceivedPerSecond": 2
 		}`,
 		resp.Body.String(),
 	)
@@ -340,8 +478,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 func TestGetStatisticsEndpointReturnsStatisticsWhenSessionIsNotStarted(t *testing.T) {
 	settableClock := utils.SettableClock{}
 	statsKeeper := bytescount.NewSessionStatsKeeper(settableClock.GetTime)
-	stats := bytescount.SessionStats{BytesSent: 1, BytesReceived: 2}
-	statsKeeper.Save(stats)
+	statsKeeper.Save("session-id", bytescount.SessionStats{BytesSent: 1, BytesReceived: 2})
 
 	manager := fakeManager{}
 	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper)
@@ -351,9 +488,59 @@ func TestGetStatisticsEndpointReturnsStatisticsWhenSessionIsNotStarted(t *testin
 	assert.JSONEq(
 		t,
 		`{
-			"bytesSent": 1,
-			"bytesReceived": 2,
-			"duration": 0
+			"bytesSent": 0,
+			"bytesReceived": 0,
+			"duration": 0,
+			"bytesSentPerSecond": 0,
+			"bytesReceivedPerSecond": 0
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetCostEndpointReturnsCostOfCurrentSession(t *testing.T) {
+	settableClock := utils.SettableClock{}
+	statsKeeper := bytescount.NewSessionStatsKeeper(settableClock.GetTime)
+
+	sessionStart := time.Date(2000, time.January, 0, 10, 0, 0, 0, time.UTC)
+	settableClock.SetTime(sessionStart)
+	statsKeeper.MarkSessionStart("session-id")
+	settableClock.SetTime(sessionStart.Add(90 * time.Minute))
+
+	manager := fakeManager{
+		activeProposal: &dto_discovery.ServiceProposal{
+			PaymentMethod: dto_openvpn.PaymentMethodPerTime{
+				Price:    money.NewMoney(0.125, money.CURRENCY_MYST),
+				Duration: time.Hour,
+			},
+		},
+	}
+	connEndpoint := NewConnectionEndpoint(&manager, nil, statsKeeper)
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetCost(resp, nil, nil)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"amount": 18750000,
+			"currency": "MYST"
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetCostEndpointReturnsConflictWhenNotConnected(t *testing.T) {
+	settableClock := utils.SettableClock{}
+	connEndpoint := NewConnectionEndpoint(&fakeManager{}, nil, bytescount.NewSessionStatsKeeper(settableClock.GetTime))
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetCost(resp, nil, nil)
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"message": "no connection exists"
 		}`,
 		resp.Body.String(),
 	)
@@ -0,0 +1,94 @@
+package endpoints
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+	"github.com/mysterium/node/tequilapi/utils"
+	"net/http"
+	"time"
+)
+
+type sessionDTO struct {
+	ID         string `json:"id"`
+	ConsumerID string `json:"consumerId"`
+	CreatedAt  string `json:"createdAt"`
+	LastSeenAt string `json:"lastSeenAt,omitempty"`
+}
+
+type sessionListResponse struct {
+	Sessions []sessionDTO `json:"sessions"`
+}
+
+type revokeResponse struct {
+	Sessions []string `json:"sessions"`
+}
+
+type sessionsEndpoint struct {
+	sessionManager session.Registry
+}
+
+// NewSessionsEndpoint creates provider's endpoint which lists sessions and terminates them
+func NewSessionsEndpoint(sessionManager session.Registry) *sessionsEndpoint {
+	return &sessionsEndpoint{
+		sessionManager: sessionManager,
+	}
+}
+
+// List responds with active sessions of provider
+func (se *sessionsEndpoint) List(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	response := sessionListResponse{Sessions: make([]sessionDTO, 0)}
+	for _, sessionInstance := range se.sessionManager.List() {
+		response.Sessions = append(response.Sessions, toSessionDTO(sessionInstance))
+	}
+	utils.WriteAsJSON(response, writer)
+}
+
+// Kill destroys given session and disconnects consumer which uses it
+func (se *sessionsEndpoint) Kill(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	err := se.sessionManager.Destroy(session.SessionID(params.ByName("id")))
+	if err == session.ErrSessionNotFound {
+		utils.SendError(writer, err, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+	writer.WriteHeader(http.StatusAccepted)
+}
+
+// RevokeConsumer destroys all sessions of given consumer and responds with their ids
+func (se *sessionsEndpoint) RevokeConsumer(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	destroyed, err := session.RevokeConsumer(se.sessionManager, identity.FromAddress(params.ByName("id")))
+	if err != nil {
+		utils.SendError(writer, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := revokeResponse{Sessions: make([]string, 0, len(destroyed))}
+	for _, id := range destroyed {
+		response.Sessions = append(response.Sessions, string(id))
+	}
+	utils.WriteAsJSON(response, writer)
+}
+
+func toSessionDTO(sessionInstance session.Session) sessionDTO {
+	dto := sessionDTO{
+		ID:         string(sessionInstance.ID),
+		ConsumerID: sessionInstance.ConsumerID.Address,
+		CreatedAt:  sessionInstance.Created.UTC().Format(time.RFC3339),
+	}
+	if !sessionInstance.LastSeen.IsZero() {
+		dto.LastSeenAt = sessionInstance.LastSeen.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
+
+// AddRoutesForSessions adds provider's session management routes to given router
+func AddRoutesForSessions(router *httprouter.Router, sessionManager session.Registry) {
+	sessionsEndpoint := NewSessionsEndpoint(sessionManager)
+	router.GET("/sessions", sessionsEndpoint.List)
+	router.DELETE("/sessions/:id", sessionsEndpoint.Kill)
+	router.DELETE("/consumers/:id/sessions", sessionsEndpoint.RevokeConsumer)
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysterium/node/identity"
+	"github.com/mysterium/node/session"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newSessionManagerFake() *session.ManagerFake {
+	return &session.ManagerFake{
+		Sessions: map[session.SessionID]session.Session{
+			"session-1": {
+				ID:         "session-1",
+				ConsumerID: identity.FromAddress("0x1"),
+				Created:    time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC),
+				LastSeen:   time.Date(2018, 1, 2, 10, 1, 0, 0, time.UTC),
+			},
+			"session-2": {
+				ID:         "session-2",
+				ConsumerID: identity.FromAddress("0x2"),
+				Created:    time.Date(2018, 1, 2, 11, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+}
+
+func TestSessionsAreListed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	resp := httptest.NewRecorder()
+
+	NewSessionsEndpoint(newSessionManagerFake()).List(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"sessions": [
+				{
+					"id": "session-1",
+					"consumerId": "0x1",
+					"createdAt": "2018-01-02T10:00:00Z",
+					"lastSeenAt": "2018-01-02T10:01:00Z"
+				},
+				{
+					"id": "session-2",
+					"consumerId": "0x2",
+					"createdAt": "2018-01-02T11:00:00Z"
+				}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestSessionIsKilled(t *testing.T) {
+	sessionManager := newSessionManagerFake()
+	req := httptest.NewRequest(http.MethodDelete, "/sessions/session-1", nil)
+	resp := httptest.NewRecorder()
+
+	NewSessionsEndpoint(sessionManager).Kill(resp, req, httprouter.Params{{"id", "session-1"}})
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, session.SessionID("session-1"), sessionManager.DestroyedSession)
+}
+
+func TestUnknownSessionIsNotKilled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/sessions/session-3", nil)
+	resp := httptest.NewRecorder()
+
+	NewSessionsEndpoint(&sessionManagerNotFound{}).Kill(resp, req, httprouter.Params{{"id", "session-3"}})
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.JSONEq(t, `{"message": "session not found"}`, resp.Body.String())
+}
+
+func TestConsumerIsRevoked(t *testing.T) {
+	sessionManager := newSessionManagerFake()
+	req := httptest.NewRequest(http.MethodDelete, "/consumers/0x2/sessions", nil)
+	resp := httptest.NewRecorder()
+
+	NewSessionsEndpoint(sessionManager).RevokeConsumer(resp, req, httprouter.Params{{"id", "0x2"}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"sessions": ["session-2"]}`, resp.Body.String())
+	assert.Len(t, sessionManager.Sessions, 1)
+}
+
+type sessionManagerNotFound struct {
+	session.ManagerFake
+}
+
+func (manager *sessionManagerNotFound) Destroy(id session.SessionID) error {
+	return session.ErrSessionNotFound
+}
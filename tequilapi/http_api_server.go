@@ -24,9 +24,14 @@ type apiServer struct {
 }
 
 func NewServer(address string, port int, handler http.Handler) APIServer {
+	return NewServerWithoutCors(address, port, ApplyCors(handler))
+}
+
+// NewServerWithoutCors creates server, which pages of other origins can not call from browser
+func NewServerWithoutCors(address string, port int, handler http.Handler) APIServer {
 	server := apiServer{
 		make(chan error, 1),
-		ApplyCors(handler),
+		handler,
 		fmt.Sprintf("%s:%d", address, port),
 		nil}
 	return &server
@@ -1,7 +1,9 @@
 package tequilapi
 
 import (
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
 )
 
@@ -22,3 +24,25 @@ func TestStopBeforeStartingListeningDoesNotCausePanic(t *testing.T) {
 	server := NewServer("", 12345, nil)
 	server.Stop()
 }
+
+func TestServerWithoutCorsDoesNotAllowOtherOrigins(t *testing.T) {
+	handler := http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {})
+	server := NewServerWithoutCors("localhost", 0, handler)
+	assert.NoError(t, server.StartServing())
+	defer server.Wait()
+	defer server.Stop()
+
+	port, err := server.Port()
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodOptions, fmt.Sprintf("http://localhost:%d/sessions/1", port), nil)
+	assert.NoError(t, err)
+	req.Header.Set("Origin", "http://example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+}
@@ -0,0 +1,42 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ReadJSON decodes content of JSON file into value, missing file is not an error and leaves value untouched
+func ReadJSON(path string, value interface{}) error {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("failed to parse file %s: %s", path, err)
+	}
+	return nil
+}
+
+// WriteJSON replaces file with JSON of value, through temporary file so that crash does not leave it truncated
+func WriteJSON(path string, value interface{}) error {
+	content, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	temporaryPath := path + ".tmp"
+	if err := ioutil.WriteFile(temporaryPath, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(temporaryPath, path)
+}
@@ -0,0 +1,41 @@
+package file
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteJSONAndReadItBack(t *testing.T) {
+	directory, err := ioutil.TempDir("", "json-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "nested", "values.json")
+
+	assert.NoError(t, WriteJSON(path, []string{"first", "second"}))
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	var values []string
+	assert.NoError(t, ReadJSON(path, &values))
+	assert.Equal(t, []string{"first", "second"}, values)
+}
+
+func TestReadJSONLeavesValueWhenFileIsMissing(t *testing.T) {
+	values := []string{"default"}
+	assert.NoError(t, ReadJSON("/nonexistent/values.json", &values))
+	assert.Equal(t, []string{"default"}, values)
+}
+
+func TestReadJSONFailsOnMalformedFile(t *testing.T) {
+	directory, err := ioutil.TempDir("", "json-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(directory)
+	path := filepath.Join(directory, "values.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0600))
+
+	var values []string
+	assert.EqualError(t, ReadJSON(path, &values), "failed to parse file "+path+": unexpected end of JSON input")
+}